@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/github"
+	"git-slack-bot/internal/preflight"
+	"git-slack-bot/internal/slack"
+	"log/slog"
+	"os"
+
+	config_loader "github.com/loveholidays/go-config-loader"
+	sl "github.com/slack-go/slack"
+)
+
+const checkConfigUsage = `Usage: git-slack-bot check-config [flags]
+
+Checks that the configuration is valid and that the github team, slack channel, emoji and users it refers to exist.
+Exits with 1 if any check fails.
+
+`
+
+// runCheckConfig runs the check-config subcommand and returns the exit code.
+func runCheckConfig(args []string) int {
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})))
+
+	flags := flag.NewFlagSet("check-config", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), checkConfigUsage)
+		flags.PrintDefaults()
+	}
+	configPath := flags.String("config", os.Getenv("CONFIG_PATH"), "path of the configuration file")
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	cfg, err := config_loader.LoadConfiguration[config.Configuration](*configPath)
+	if err != nil {
+		fmt.Printf("%-4s  %-13s %s\n", preflight.StatusFailed, "configuration", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	configSecrets, err := resolveSecrets(ctx, cfg)
+	if err != nil {
+		fmt.Printf("%-4s  %-13s %s\n", preflight.StatusFailed, "secrets", err)
+		return 1
+	}
+	gitHubClient, err := github.NewClient(ctx, cfg.GitHub, configSecrets.githubToken.Value)
+	if err != nil {
+		fmt.Printf("%-4s  %-13s %s\n", preflight.StatusFailed, "github", err)
+		return 1
+	}
+	report := preflight.NewChecker(*cfg, gitHubClient, sl.New(configSecrets.slackToken.Value()), slack.NewInspector(configSecrets.slackToken.Value)).Run(ctx)
+	err = report.Write(os.Stdout)
+	if err != nil || report.Failed() {
+		return 1
+	}
+	return 0
+}
+
+// logReport logs the checks that did not pass and reports whether any of them failed.
+func logReport(report preflight.Report) bool {
+	for _, result := range report.Results {
+		switch result.Status {
+		case preflight.StatusFailed:
+			slog.Error("Configuration check failed", slog.String("check", result.Check), slog.String("problem", result.Message))
+		case preflight.StatusWarning:
+			slog.Warn("Configuration check warning", slog.String("check", result.Check), slog.String("problem", result.Message))
+		}
+	}
+	return report.Failed()
+}
@@ -2,62 +2,292 @@ package main
 
 import (
 	"context"
+	"errors"
 	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/conflict"
+	"git-slack-bot/internal/deadletter"
+	"git-slack-bot/internal/digest"
 	"git-slack-bot/internal/github"
 	"git-slack-bot/internal/handler"
+	"git-slack-bot/internal/health"
+	"git-slack-bot/internal/logging"
+	"git-slack-bot/internal/messagebuilder"
+	"git-slack-bot/internal/metrics"
+	"git-slack-bot/internal/poller"
+	"git-slack-bot/internal/preflight"
+	"git-slack-bot/internal/reload"
+	"git-slack-bot/internal/reminder"
+	"git-slack-bot/internal/reviewqueue"
+	"git-slack-bot/internal/scheduler"
+	"git-slack-bot/internal/server"
 	"git-slack-bot/internal/slack"
+	"git-slack-bot/internal/tracing"
 	"git-slack-bot/internal/user"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	config_loader "github.com/loveholidays/go-config-loader"
 	sl "github.com/slack-go/slack"
 )
 
 func main() {
-	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		os.Exit(runReplay(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "check-config" {
+		os.Exit(runCheckConfig(os.Args[2:]))
+	}
+
+	logger, err := logging.NewLogger(os.Stdout, config.LoggingConfiguration{})
+	if err != nil {
+		slog.Error("Failed to set up logging", slog.Any("error", err))
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
 
 	cfg, err := config_loader.LoadConfiguration[config.Configuration](os.Getenv("CONFIG_PATH"))
 	if err != nil {
 		slog.Error("Failed to load configuration", slog.Any("error", err))
 		os.Exit(1)
 	}
+	logger, err = logging.NewLogger(os.Stdout, cfg.Logging)
+	if err != nil {
+		slog.Error("Failed to set up logging", slog.Any("error", err))
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+
+	// ctx is cancelled on SIGINT or SIGTERM, which stops the scheduled jobs, the poller and Socket Mode, and drains
+	// the server.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	externalSlackClient := sl.New(cfg.Slack.Token)
-	slackConnector := slack.NewSlackConnector(cfg.Slack, externalSlackClient)
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		slog.Error("Failed to set up tracing", slog.Any("error", err))
+		os.Exit(1)
+	}
+	defer func() {
+		err := shutdownTracing(context.Background())
+		if err != nil {
+			slog.Error("Failed to flush traces", slog.Any("error", err))
+		}
+	}()
 
-	ctx := context.Background()
-	gitHubClient := github.NewExternalClient(ctx, cfg.GitHub.Token)
+	configSecrets, err := resolveSecrets(ctx, cfg)
+	if err != nil {
+		slog.Error("Failed to resolve secrets", slog.Any("error", err))
+		os.Exit(1)
+	}
+	go configSecrets.store.Run(ctx)
+
+	externalSlackClient := slack.NewRetryClient(slack.NewTracingClient(slack.NewRotatingClient(configSecrets.slackToken.Value, func(token string) slack.Client {
+		return sl.New(token)
+	})), cfg.Slack.Retry.MaxAttempts, cfg.Slack.Timeout)
+	var slackClient slack.Client = externalSlackClient
+	if cfg.Slack.DryRun {
+		slackClient = slack.NewDryRunClient(externalSlackClient, nil)
+	}
+	if cfg.Slack.ShadowChannelID != "" {
+		slackClient = slack.NewShadowClient(slackClient, externalSlackClient, cfg.Slack.ShadowChannelID)
+	}
+	// Posts and reactions that still fail after the retries of the client are kept to be made again later.
+	var deadLetters slack.DeadLetters
+	var deadLetterStore *deadletter.Store
+	if cfg.Slack.DeadLetters.Enabled {
+		deadLetterFile := cfg.Slack.DeadLetters.File
+		if deadLetterFile == "" {
+			deadLetterFile = "dead-letters.json"
+		}
+		deadLetterStore, err = deadletter.NewStore(deadLetterFile)
+		if err != nil {
+			slog.Error("Failed to load dead letters", slog.Any("error", err))
+			os.Exit(1)
+		}
+		deadLetters = deadLetterStore
+	}
+	slackConnector := slack.NewSlackConnectorWithDeadLetters(cfg.Slack, slackClient, deadLetters)
+	var deadLetterRetrier *deadletter.Retrier
+	if deadLetterStore != nil {
+		deadLetterRetrier = deadletter.NewRetrier(deadLetterStore, slackConnector, cfg.Slack.DeadLetters.RetryInterval, cfg.Slack.DeadLetters.MaxAttempts)
+		go deadLetterRetrier.Run(ctx)
+	}
+
+	externalGitHubClient, err := github.NewClient(ctx, cfg.GitHub, configSecrets.githubToken.Value)
+	if err != nil {
+		slog.Error("Failed to create GitHub client", slog.Any("error", err))
+		os.Exit(1)
+	}
+	gitHubClient := github.NewTracingClient(externalGitHubClient)
+	if logReport(preflight.NewChecker(*cfg, gitHubClient, externalSlackClient, slack.NewInspector(configSecrets.slackToken.Value)).Run(ctx)) {
+		slog.Error("Not starting with a broken configuration, run check-config for a report")
+		os.Exit(1)
+	}
 	gitHubConnector, err := github.NewGitHubConnector(ctx, cfg.GitHub, gitHubClient)
 	if err != nil {
 		slog.Error("Failed to establish GitHub connection", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	userService := user.NewService(slackConnector, gitHubConnector.GetTeamMembers(ctx), cfg.Slack.GithubEmailToSlackEmail, cfg.GitHub.IgnoredCommentUsers, cfg.GitHub.IgnoredReviewUsers)
+	emojiConfiguration := cfg.Slack.EmojiConfiguration.WithDefaults()
+	var conflictChecker conflict.Checker
+	var conflictDetector *conflict.Detector
+	if cfg.GitHub.DetectConflicts {
+		conflictDetector = conflict.NewDetector(gitHubConnector, slackConnector, userService, emojiConfiguration)
+		conflictQueue := conflict.NewQueue(conflictDetector)
+		go conflictQueue.Run(ctx, conflict.DefaultWorkers)
+		conflictChecker = conflictQueue
+	}
+
+	location, err := time.LoadLocation(cfg.Schedule.Timezone)
+	if err != nil {
+		slog.Error("Failed to load schedule timezone", slog.String("timezone", cfg.Schedule.Timezone), slog.Any("error", err))
+		os.Exit(1)
+	}
+	jobScheduler := scheduler.NewScheduler(location)
+	if cfg.Schedule.Digest.Cron != "" {
+		openPRDigest := digest.NewDigest(gitHubConnector, slackConnector, userService, cfg.Schedule.Digest.StaleAfter)
+		err = jobScheduler.AddWeekdayJob("digest", cfg.Schedule.Digest.Cron, openPRDigest.Post)
+		if err != nil {
+			slog.Error("Failed to schedule digest", slog.String("cron", cfg.Schedule.Digest.Cron), slog.Any("error", err))
+			os.Exit(1)
+		}
+	}
+	var snoozer reminder.Snoozer
+	if len(cfg.Schedule.Reminders.Rules) > 0 {
+		reminderCron := cfg.Schedule.Reminders.Cron
+		if reminderCron == "" {
+			reminderCron = "*/30 * * * *"
+		}
+		workingHours := scheduler.NewWorkingHours(location, cfg.Schedule.WorkingHours.Start, cfg.Schedule.WorkingHours.End)
+		reviewReminder := reminder.NewReminder(gitHubConnector, slackConnector, userService, workingHours, cfg.Schedule.Reminders)
+		err = jobScheduler.AddWeekdayJob("reminders", reminderCron, reviewReminder.Run)
+		if err != nil {
+			slog.Error("Failed to schedule reminders", slog.String("cron", reminderCron), slog.Any("error", err))
+			os.Exit(1)
+		}
+		snoozer = reviewReminder
+	}
+	// The review queue is always scheduled, as a reload may add the first subscriber. It does nothing without any.
+	reviewQueue := reviewqueue.NewReviewQueue(gitHubConnector, slackConnector, userService, location, cfg.Schedule.ReviewQueue.Hour)
+	err = jobScheduler.AddJob("review queue", "*/15 * * * *", reviewQueue.Send)
+	if err != nil {
+		slog.Error("Failed to schedule review queue", slog.Any("error", err))
+		os.Exit(1)
+	}
+	jobScheduler.Start(ctx)
+
+	// Buttons need slack to be able to send the clicks back, either to the http endpoints, which are verified with the
+	// signing secret, or over Socket Mode.
+	slackIngress := cfg.Slack.SigningSecret != "" || cfg.Slack.AppToken != ""
+	var prActions messagebuilder.PRActions
+	if slackIngress {
+		prActions = messagebuilder.PRActions{ClaimReview: true, Snooze: snoozer != nil, OpenDiff: true}
+	}
+	gitHandler := handler.NewGitHandler(slackConnector, userService, conflictChecker, emojiConfiguration, prActions, cfg.GitHub.IgnoredRepos)
+
+	var allowList *handler.AllowList
+	if cfg.GitHub.Webhook.AllowedIPsFile != "" {
+		allowList, err = handler.LoadAllowList(cfg.GitHub.Webhook.AllowedIPsFile)
+		if err != nil {
+			slog.Error("Failed to load allowed webhook addresses", slog.Any("error", err))
+			os.Exit(1)
+		}
 	}
+	webhookEventHandler := handler.NewWebhookEventHandlerWithLimits(configSecrets.webhookSecrets, gitHandler, allowList, cfg.GitHub.Webhook.TrustForwardedFor)
 
-	userService := user.NewService(slackConnector, gitHubConnector.GetTeamMembers(), cfg.Slack.GithubEmailToSlackEmail, cfg.GitHub.IgnoredCommentUsers, cfg.GitHub.IgnoredReviewUsers)
-	emojiConfiguration := cfg.Slack.EmojiConfiguration
-	if emojiConfiguration.Approve == "" {
-		emojiConfiguration.Approve = "+1"
+	configWatcher := reload.NewWatcher(os.Getenv("CONFIG_PATH"), cfg.Reload.Interval, cfg, func(ctx context.Context, reloaded *config.Configuration) {
+		gitHubConnector.Reload(reloaded.GitHub.IgnoredPRUsers)
+		webhookSecrets, err := configSecrets.store.ResolveAll(ctx, reloaded.GitHub.WebhookSecrets()...)
+		if err != nil {
+			slog.Error("Failed to resolve reloaded webhook secrets, keeping the previous ones", slog.Any("error", err))
+		} else {
+			webhookEventHandler.Reload(webhookSecrets)
+		}
+		userService.Reload(gitHubConnector.GetTeamMembers(ctx), reloaded.Slack.GithubEmailToSlackEmail, reloaded.GitHub.IgnoredCommentUsers, reloaded.GitHub.IgnoredReviewUsers)
+		reloadedEmoji := reloaded.Slack.EmojiConfiguration.WithDefaults()
+		gitHandler.Reload(reloadedEmoji, reloaded.GitHub.IgnoredRepos)
+		if conflictDetector != nil {
+			conflictDetector.Reload(reloadedEmoji)
+		}
+	})
+	go configWatcher.Run(ctx)
+
+	if cfg.GitHub.Polling.Enabled {
+		cursorFile := cfg.GitHub.Polling.CursorFile
+		if cursorFile == "" {
+			cursorFile = "github-events-cursor.json"
+		}
+		eventPoller := poller.NewPoller(gitHubConnector, gitHandler, cursorFile, cfg.GitHub.Polling.Interval)
+		go eventPoller.Run(ctx)
 	}
-	if emojiConfiguration.Merge == "" {
-		emojiConfiguration.Merge = "merged"
+
+	if cfg.GitHub.WebhookEnabled() {
+		http.HandleFunc("/git-event", webhookEventHandler.HandleWebhook)
 	}
-	if emojiConfiguration.Close == "" {
-		emojiConfiguration.Close = "x"
+	healthChecker := health.NewChecker(0)
+	healthChecker.Add("config", func(context.Context) error { return nil })
+	healthChecker.Add("teamMembers", func(context.Context) error {
+		if len(userService.GetTeamMembers()) == 0 {
+			return errors.New("no members of the github team were resolved")
+		}
+		return nil
+	})
+	healthChecker.Add("slack", slackConnector.CheckAuth)
+	healthChecker.Add("github", gitHubConnector.CheckAuth)
+	http.HandleFunc("/healthz", healthChecker.HandleLiveness)
+	http.HandleFunc("/readyz", healthChecker.HandleReadiness)
+	// Kept for probes set up before /healthz existed.
+	http.HandleFunc("/", healthChecker.HandleLiveness)
+	http.Handle("/metrics", metrics.Handler())
+	if deadLetterRetrier != nil {
+		if configSecrets.adminToken.Value() == "" {
+			slog.Warn("Dead letters can only be retried or discarded by hand with server.adminToken set")
+		} else {
+			adminHandler := handler.NewAdminHandler(configSecrets.adminToken, deadLetterRetrier)
+			http.HandleFunc("GET /admin/dead-letters", adminHandler.HandleListDeadLetters)
+			http.HandleFunc("POST /admin/dead-letters/{id}/retry", adminHandler.HandleRetryDeadLetter)
+			http.HandleFunc("DELETE /admin/dead-letters/{id}", adminHandler.HandleDiscardDeadLetter)
+		}
 	}
-	gitHandler := handler.NewGitHandler(slackConnector, userService, emojiConfiguration, cfg.GitHub.IgnoredRepos)
-	webhookEventHandler := handler.NewWebhookEventHandler([]byte(cfg.GitHub.SecretKey), gitHandler)
-	http.HandleFunc("/git-event", webhookEventHandler.HandleWebhook)
-	http.HandleFunc("/", webhookEventHandler.HandleHeathCheck)
+	if slackIngress {
+		prCommandHandler := handler.NewPRCommandHandler(gitHubConnector, userService, cfg.Schedule.Digest.StaleAfter)
+		prActionHandler := handler.NewPRActionHandler(gitHubConnector, slackConnector, userService, snoozer)
+		var eventHandler handler.EventHandler
+		if cfg.Slack.SyncThreadReplies {
+			eventHandler = handler.NewThreadReplyHandler(gitHubConnector, slackConnector, userService, cfg.Slack.ChannelID)
+		}
+
+		if cfg.Slack.SigningSecret != "" {
+			slackHandler := handler.NewSlackHandler(configSecrets.slackSigningSecret, prCommandHandler, prActionHandler, eventHandler)
+			http.HandleFunc("/slack/commands", slackHandler.HandleSlashCommand)
+			http.HandleFunc("/slack/interactivity", slackHandler.HandleInteraction)
+			if eventHandler != nil {
+				http.HandleFunc("/slack/events", slackHandler.HandleEvent)
+			}
+		}
 
-	server := &http.Server{
-		Addr:              ":8080",
-		ReadHeaderTimeout: time.Second * 3,
+		if cfg.Slack.AppToken != "" {
+			socketMode := handler.NewSocketMode(configSecrets.slackToken, configSecrets.slackAppToken, prCommandHandler, prActionHandler, eventHandler)
+			go socketMode.Run(ctx)
+		}
 	}
 
-	err = server.ListenAndServe()
+	httpServer, err := server.NewServer(cfg.Server, http.DefaultServeMux)
+	if err != nil {
+		slog.Error("Failed to create server", slog.Any("error", err))
+		os.Exit(1)
+	}
+	err = httpServer.Run(ctx)
 	if err != nil {
 		slog.Error("Server error", slog.Any("error", err))
 	}
+	jobScheduler.Stop()
+	slog.Info("Shut down")
 }
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/github"
+	"git-slack-bot/internal/handler"
+	"git-slack-bot/internal/logging"
+	"git-slack-bot/internal/messagebuilder"
+	"git-slack-bot/internal/replay"
+	"git-slack-bot/internal/slack"
+	"git-slack-bot/internal/user"
+	"log/slog"
+	"os"
+
+	config_loader "github.com/loveholidays/go-config-loader"
+	sl "github.com/slack-go/slack"
+)
+
+const replayUsage = `Usage: git-slack-bot replay [flags] [file...]
+
+Feeds recorded github webhook payloads through the bot. Files hold json values, one per file or one per line, either
+{"event": "pull_request", "payload": {...}} envelopes or bare payloads of the -event type. Reads stdin without files.
+
+`
+
+// runReplay runs the replay subcommand and returns the exit code.
+func runReplay(args []string) int {
+	logger, err := logging.NewLogger(os.Stderr, config.LoggingConfiguration{})
+	if err != nil {
+		slog.Error("Failed to set up logging", slog.Any("error", err))
+		return 1
+	}
+	slog.SetDefault(logger)
+
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), replayUsage)
+		flags.PrintDefaults()
+	}
+	configPath := flags.String("config", os.Getenv("CONFIG_PATH"), "path of the configuration file")
+	event := flags.String("event", "", "event type of bare payloads, the value of the X-GitHub-Event header")
+	dryRun := flags.Bool("dry-run", true, "print the slack api calls instead of making them")
+	err = flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	var events []replay.Event
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	for _, file := range files {
+		fileEvents, err := readEvents(file, *event)
+		if err != nil {
+			slog.Error("Failed to read events", slog.String("file", file), slog.Any("error", err))
+			return 1
+		}
+		events = append(events, fileEvents...)
+	}
+
+	cfg, err := config_loader.LoadConfiguration[config.Configuration](*configPath)
+	if err != nil {
+		slog.Error("Failed to load configuration", slog.Any("error", err))
+		return 1
+	}
+	logger, err = logging.NewLogger(os.Stderr, cfg.Logging)
+	if err != nil {
+		slog.Error("Failed to set up logging", slog.Any("error", err))
+		return 1
+	}
+	slog.SetDefault(logger)
+
+	ctx := context.Background()
+	configSecrets, err := resolveSecrets(ctx, cfg)
+	if err != nil {
+		slog.Error("Failed to resolve secrets", slog.Any("error", err))
+		return 1
+	}
+
+	var slackClient slack.Client
+	if *dryRun {
+		var reads slack.Client
+		if configSecrets.slackToken.Value() != "" {
+			reads = slack.NewRetryClient(sl.New(configSecrets.slackToken.Value()), cfg.Slack.Retry.MaxAttempts, cfg.Slack.Timeout)
+		}
+		slackClient = slack.NewDryRunClient(reads, os.Stdout)
+	} else {
+		slackClient = slack.NewRetryClient(sl.New(configSecrets.slackToken.Value()), cfg.Slack.Retry.MaxAttempts, cfg.Slack.Timeout)
+	}
+	slackConnector := slack.NewSlackConnector(cfg.Slack, slackClient)
+
+	gitHubClient, err := github.NewClient(ctx, cfg.GitHub, configSecrets.githubToken.Value)
+	if err != nil {
+		slog.Error("Failed to create GitHub client", slog.Any("error", err))
+		return 1
+	}
+	gitHubConnector, err := github.NewGitHubConnector(ctx, cfg.GitHub, gitHubClient)
+	if err != nil {
+		slog.Error("Failed to establish GitHub connection", slog.Any("error", err))
+		return 1
+	}
+	userService := user.NewService(slackConnector, gitHubConnector.GetTeamMembers(ctx), cfg.Slack.GithubEmailToSlackEmail, cfg.GitHub.IgnoredCommentUsers, cfg.GitHub.IgnoredReviewUsers)
+
+	var prActions messagebuilder.PRActions
+	if cfg.Slack.SigningSecret != "" || cfg.Slack.AppToken != "" {
+		prActions = messagebuilder.PRActions{ClaimReview: true, Snooze: len(cfg.Schedule.Reminders.Rules) > 0, OpenDiff: true}
+	}
+	// Conflict detection runs in the background after a push and is left out, so the replay is done when Run returns.
+	gitHandler := handler.NewGitHandler(slackConnector, userService, nil, cfg.Slack.EmojiConfiguration.WithDefaults(), prActions, cfg.GitHub.IgnoredRepos)
+
+	replay.Run(ctx, events, gitHandler)
+	return 0
+}
+
+func readEvents(file, defaultEvent string) ([]replay.Event, error) {
+	if file == "-" {
+		return replay.Read(os.Stdin, defaultEvent)
+	}
+	data, err := os.ReadFile(file) //nolint:gosec // The files to replay are picked by whoever runs it.
+	if err != nil {
+		return nil, err
+	}
+	return replay.Read(bytes.NewReader(data), defaultEvent)
+}
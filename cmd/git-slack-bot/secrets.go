@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/secret"
+)
+
+// secrets are the secrets of the configuration, which may be references to secrets kept elsewhere.
+type secrets struct {
+	store       *secret.Store
+	githubToken *secret.Secret
+	slackToken  *secret.Secret
+	// slackAppToken and slackSigningSecret are empty unless Socket Mode or the http endpoints of slack are used.
+	slackAppToken      *secret.Secret
+	slackSigningSecret *secret.Secret
+	webhookSecrets     []*secret.Secret
+	adminToken         *secret.Secret
+}
+
+// resolveSecrets resolves the secrets of cfg.
+func resolveSecrets(ctx context.Context, cfg *config.Configuration) (*secrets, error) {
+	store, err := secret.NewStore(ctx, cfg.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	githubToken, err := store.Resolve(ctx, cfg.GitHub.Token)
+	if err != nil {
+		return nil, err
+	}
+	slackToken, err := store.Resolve(ctx, cfg.Slack.Token)
+	if err != nil {
+		return nil, err
+	}
+	slackAppToken, err := store.Resolve(ctx, cfg.Slack.AppToken)
+	if err != nil {
+		return nil, err
+	}
+	slackSigningSecret, err := store.Resolve(ctx, cfg.Slack.SigningSecret)
+	if err != nil {
+		return nil, err
+	}
+	webhookSecrets, err := store.ResolveAll(ctx, cfg.GitHub.WebhookSecrets()...)
+	if err != nil {
+		return nil, err
+	}
+	adminToken, err := store.Resolve(ctx, cfg.Server.AdminToken)
+	if err != nil {
+		return nil, err
+	}
+	return &secrets{
+		store:              store,
+		githubToken:        githubToken,
+		slackToken:         slackToken,
+		slackAppToken:      slackAppToken,
+		slackSigningSecret: slackSigningSecret,
+		webhookSecrets:     webhookSecrets,
+		adminToken:         adminToken,
+	}, nil
+}
@@ -20,36 +20,229 @@ Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
 //nolint:tagliatelle //Yaml camel case instead of snake case
 package config
 
+import "time"
+
 type Configuration struct {
-	GitHub GitHubConfiguration `yaml:"github"  required:"true"`
-	Slack  SlackConfiguration  `yaml:"slack"  required:"true"`
+	GitHub   GitHubConfiguration   `yaml:"github"  required:"true"`
+	Slack    SlackConfiguration    `yaml:"slack"  required:"true"`
+	Schedule ScheduleConfiguration `yaml:"schedule"`
+	Tracing  TracingConfiguration  `yaml:"tracing"`
+	Server   ServerConfiguration   `yaml:"server"`
+	Reload   ReloadConfiguration   `yaml:"reload"`
+	Secrets  SecretsConfiguration  `yaml:"secrets"`
+	Logging  LoggingConfiguration  `yaml:"logging"`
 }
 
 type GitHubConfiguration struct {
-	Token               string   `yaml:"token"  required:"true"`
-	Team                string   `yaml:"team"  required:"true"`
-	Org                 string   `yaml:"org"  required:"true"`
-	IgnoredPRUsers      []string `yaml:"ignoredPRUsers"`
-	IgnoredRepos        []string `yaml:"ignoredRepos"`
-	SecretKey           string   `yaml:"secretKey"  required:"true"`
-	IgnoredCommentUsers []string `yaml:"ignoredCommentUsers"`
-	IgnoredReviewUsers  []string `yaml:"ignoredReviewUsers"`
+	Token               string                 `yaml:"token"`
+	App                 GitHubAppConfiguration `yaml:"app"`
+	Team                string                 `yaml:"team"  required:"true"`
+	Org                 string                 `yaml:"org"  required:"true"`
+	IgnoredPRUsers      []string               `yaml:"ignoredPRUsers"`
+	IgnoredRepos        []string               `yaml:"ignoredRepos"`
+	SecretKey           string                 `yaml:"secretKey"`
+	SecretKeys          []string               `yaml:"secretKeys"`
+	Webhook             WebhookConfiguration   `yaml:"webhook"`
+	IgnoredCommentUsers []string               `yaml:"ignoredCommentUsers"`
+	IgnoredReviewUsers  []string               `yaml:"ignoredReviewUsers"`
+	DetectConflicts     bool                   `yaml:"detectConflicts"`
+	Polling             PollingConfiguration   `yaml:"polling"`
+	Timeout             time.Duration          `yaml:"timeout"`
+}
+
+// GitHubAppConfiguration authenticates as a GitHub App instead of with a token.
+type GitHubAppConfiguration struct {
+	ID             int64                   `yaml:"id"`
+	PrivateKey     string                  `yaml:"privateKey"`
+	PrivateKeyFile string                  `yaml:"privateKeyFile"`
+	InstallationID int64                   `yaml:"installationID"`
+	Installations  []GitHubAppInstallation `yaml:"installations"`
+}
+
+// GitHubAppInstallation is the installation of the app in another organization.
+type GitHubAppInstallation struct {
+	Org string `yaml:"org"`
+	ID  int64  `yaml:"id"`
+}
+
+// WebhookConfiguration limits the webhooks that are accepted.
+type WebhookConfiguration struct {
+	AllowedIPsFile    string `yaml:"allowedIPsFile"`
+	TrustForwardedFor bool   `yaml:"trustForwardedFor"`
+}
+
+// WebhookSecrets returns secretKey followed by the secretKeys, all of which webhooks may be signed with. Empty ones
+// are left out, so that they can't be signed with an empty secret.
+func (g GitHubConfiguration) WebhookSecrets() []string {
+	var secrets []string
+	for _, secret := range append([]string{g.SecretKey}, g.SecretKeys...) {
+		if secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
+// WebhookEnabled reports whether webhooks are received, which they are unless the events are only polled.
+func (g GitHubConfiguration) WebhookEnabled() bool {
+	return !g.Polling.Enabled || len(g.WebhookSecrets()) > 0
+}
+
+type PollingConfiguration struct {
+	Enabled    bool          `yaml:"enabled"`
+	Interval   time.Duration `yaml:"interval"`
+	CursorFile string        `yaml:"cursorFile"`
 }
 
 type SlackConfiguration struct {
 	Token                   string                    `yaml:"token"  required:"true"`
 	ChannelID               string                    `yaml:"channelID"  required:"true"`
+	SigningSecret           string                    `yaml:"signingSecret"`
+	AppToken                string                    `yaml:"appToken"`
+	SyncThreadReplies       bool                      `yaml:"syncThreadReplies"`
+	DryRun                  bool                      `yaml:"dryRun"`
+	ShadowChannelID         string                    `yaml:"shadowChannelID"`
 	GithubEmailToSlackEmail []GithubEmailToSlackEmail `yaml:"githubEmailToSlackEmail"`
 	EmojiConfiguration      EmojiConfiguration        `yaml:"emoji"`
+	Timeout                 time.Duration             `yaml:"timeout"`
+	Retry                   RetryConfiguration        `yaml:"retry"`
+	DeadLetters             DeadLetterConfiguration   `yaml:"deadLetters"`
+	AnnouncementWindow      time.Duration             `yaml:"announcementWindow"`
+}
+
+// RetryConfiguration configures the retries of rate limited and failed slack api calls.
+type RetryConfiguration struct {
+	MaxAttempts int `yaml:"maxAttempts"`
+}
+
+// DeadLetterConfiguration configures keeping the posts and reactions that failed in a file, to be retried later.
+type DeadLetterConfiguration struct {
+	Enabled       bool          `yaml:"enabled"`
+	File          string        `yaml:"file"`
+	RetryInterval time.Duration `yaml:"retryInterval"`
+	MaxAttempts   int           `yaml:"maxAttempts"`
 }
 
 type EmojiConfiguration struct {
-	Approve string `yaml:"approve"`
-	Merge   string `yaml:"merge"`
-	Close   string `yaml:"close"`
+	Approve  string `yaml:"approve"`
+	Merge    string `yaml:"merge"`
+	Close    string `yaml:"close"`
+	Conflict string `yaml:"conflict"`
+	Behind   string `yaml:"behind"`
+}
+
+// WithDefaults returns the emoji configuration with the default emoji filled in for those not set.
+func (e EmojiConfiguration) WithDefaults() EmojiConfiguration {
+	if e.Approve == "" {
+		e.Approve = "+1"
+	}
+	if e.Merge == "" {
+		e.Merge = "merged"
+	}
+	if e.Close == "" {
+		e.Close = "x"
+	}
+	if e.Conflict == "" {
+		e.Conflict = "warning"
+	}
+	if e.Behind == "" {
+		e.Behind = "arrows_counterclockwise"
+	}
+	return e
 }
 
 type GithubEmailToSlackEmail struct {
 	GithubEmail string `yaml:"githubEmail"`
 	SlackEmail  string `yaml:"slackEmail"`
+	ReviewQueue bool   `yaml:"reviewQueue"`
+}
+
+type ScheduleConfiguration struct {
+	Timezone     string                    `yaml:"timezone"`
+	WorkingHours WorkingHoursConfiguration `yaml:"workingHours"`
+	Digest       DigestConfiguration       `yaml:"digest"`
+	Reminders    ReminderConfiguration     `yaml:"reminders"`
+	ReviewQueue  ReviewQueueConfiguration  `yaml:"reviewQueue"`
+}
+
+type WorkingHoursConfiguration struct {
+	Start int `yaml:"start"`
+	End   int `yaml:"end"`
+}
+
+type DigestConfiguration struct {
+	Cron       string        `yaml:"cron"`
+	StaleAfter time.Duration `yaml:"staleAfter"`
+}
+
+type ReminderConfiguration struct {
+	Cron        string         `yaml:"cron"`
+	TeamMention string         `yaml:"teamMention"`
+	SnoozeFor   time.Duration  `yaml:"snoozeFor"`
+	Rules       []ReminderRule `yaml:"rules"`
+}
+
+type ReminderRule struct {
+	Name               string   `yaml:"name"`
+	Repos              []string `yaml:"repos"`
+	RemindAfterHours   float64  `yaml:"remindAfterHours"`
+	EscalateAfterHours float64  `yaml:"escalateAfterHours"`
+	Mention            string   `yaml:"mention"`
+}
+
+type ReviewQueueConfiguration struct {
+	Hour int `yaml:"hour"`
+}
+
+// TracingConfiguration configures exporting traces over OTLP/HTTP. The standard OTEL_EXPORTER_OTLP_* environment
+// variables apply for anything not set here.
+type TracingConfiguration struct {
+	Enabled     bool    `yaml:"enabled"`
+	Endpoint    string  `yaml:"endpoint"`
+	SampleRatio float64 `yaml:"sampleRatio"`
+}
+
+// ServerConfiguration configures the http server receiving webhooks and slack requests. Zero values fall back to
+// defaults suitable for running behind a proxy.
+type ServerConfiguration struct {
+	Address           string           `yaml:"address"`
+	ReadHeaderTimeout time.Duration    `yaml:"readHeaderTimeout"`
+	ReadTimeout       time.Duration    `yaml:"readTimeout"`
+	WriteTimeout      time.Duration    `yaml:"writeTimeout"`
+	IdleTimeout       time.Duration    `yaml:"idleTimeout"`
+	MaxBodyBytes      int64            `yaml:"maxBodyBytes"`
+	DrainTimeout      time.Duration    `yaml:"drainTimeout"`
+	TLS               TLSConfiguration `yaml:"tls"`
+	AdminToken        string           `yaml:"adminToken"`
+}
+
+// TLSConfiguration enables serving https. The files are read again when they change, so renewed certificates are
+// picked up without a restart.
+type TLSConfiguration struct {
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+}
+
+// ReloadConfiguration configures how often the configuration file is checked for changes.
+type ReloadConfiguration struct {
+	Interval time.Duration `yaml:"interval"`
+}
+
+// SecretsConfiguration configures where secret references are resolved and how often they are refreshed.
+type SecretsConfiguration struct {
+	RefreshInterval time.Duration      `yaml:"refreshInterval"`
+	Vault           VaultConfiguration `yaml:"vault"`
+}
+
+type VaultConfiguration struct {
+	Address   string `yaml:"address"`
+	Token     string `yaml:"token"`
+	Namespace string `yaml:"namespace"`
+}
+
+// LoggingConfiguration configures the log lines. Tokens are always masked in them.
+type LoggingConfiguration struct {
+	Level            string `yaml:"level"`
+	Format           string `yaml:"format"`
+	LogSensitiveData bool   `yaml:"logSensitiveData"`
 }
@@ -0,0 +1,270 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package conflict
+
+//go:generate mockgen -destination=./mocks/conflict.go . Checker
+
+import (
+	"context"
+	"fmt"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/github"
+	messageBuilder "git-slack-bot/internal/messagebuilder"
+	"git-slack-bot/internal/slack"
+	"git-slack-bot/internal/tracing"
+	"git-slack-bot/internal/user"
+	"log/slog"
+	"sync"
+	"time"
+
+	gh "github.com/google/go-github/v56/github"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type status string
+
+const (
+	statusClean      status = ""
+	statusUnknown    status = "unknown"
+	statusConflicted status = "dirty"
+	statusBehind     status = "behind"
+)
+
+const (
+	defaultAttempts   = 5
+	defaultRetryDelay = 3 * time.Second
+)
+
+type Checker interface {
+	CheckBranch(ctx context.Context, owner, repo, branch string)
+}
+
+type notice struct {
+	status         status
+	reaction       string
+	replyTimestamp string
+}
+
+// Detector keeps track of which of the team's open pull requests are conflicted or behind their base branch, and
+// reflects that on their slack message with a reaction and a thread reply. The notices are only held in memory,
+// so a notice posted before a restart will not be cleared by this instance.
+type Detector struct {
+	githubConnector github.Interactor
+	slackConnector  slack.Interactor
+	userService     user.Service
+	messageBuilder  messageBuilder.MessageBuilder
+	emoji           config.EmojiConfiguration
+	attempts        int
+	retryDelay      time.Duration
+	// mutex guards emoji, notices and updating, but is not held while calling slack.
+	mutex    sync.Mutex
+	notices  map[string]notice
+	updating map[string]*pullRequestLock
+}
+
+// pullRequestLock serializes the updates of the notice of one pull request.
+type pullRequestLock struct {
+	mutex   sync.Mutex
+	holders int
+}
+
+func NewDetector(githubConnector github.Interactor, slackConnector slack.Interactor, userService user.Service, emoji config.EmojiConfiguration) *Detector {
+	return NewDetectorWithRetry(githubConnector, slackConnector, userService, emoji, defaultAttempts, defaultRetryDelay)
+}
+
+// NewDetectorWithRetry creates a Detector which asks GitHub at most attempts times, retryDelay apart, for the
+// mergeability of a pull request while GitHub is still computing it.
+func NewDetectorWithRetry(githubConnector github.Interactor, slackConnector slack.Interactor, userService user.Service, emoji config.EmojiConfiguration, attempts int, retryDelay time.Duration) *Detector {
+	return &Detector{
+		githubConnector: githubConnector,
+		slackConnector:  slackConnector,
+		userService:     userService,
+		messageBuilder:  messageBuilder.MessageBuilder{},
+		emoji:           emoji,
+		attempts:        attempts,
+		retryDelay:      retryDelay,
+		notices:         make(map[string]notice),
+		updating:        make(map[string]*pullRequestLock),
+	}
+}
+
+// Reload replaces the emoji of new notices. Notices already posted are cleared with the emoji they were posted with.
+func (d *Detector) Reload(emoji config.EmojiConfiguration) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.emoji = emoji
+}
+
+// CheckBranch re-checks the team's open pull requests that either target branch or are built from it, as a push
+// to the former can introduce a conflict and a push to the latter can resolve one.
+func (d *Detector) CheckBranch(ctx context.Context, owner, repo, branch string) {
+	ctx, span := tracing.Start(ctx, "conflict.CheckBranch", tracing.Repository.String(owner+"/"+repo), attribute.String("git.branch", branch))
+	defer span.End()
+
+	targeting, err := d.githubConnector.ListOpenPullRequests(ctx, owner, repo, branch, "")
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to list pull requests", slog.String("repo", repo), slog.String("base", branch), slog.Any("error", err))
+		return
+	}
+	builtFrom, err := d.githubConnector.ListOpenPullRequests(ctx, owner, repo, "", branch)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to list pull requests", slog.String("repo", repo), slog.String("head", branch), slog.Any("error", err))
+		return
+	}
+
+	checked := make(map[int]bool)
+	for _, pullRequest := range append(targeting, builtFrom...) {
+		if checked[pullRequest.GetNumber()] {
+			continue
+		}
+		checked[pullRequest.GetNumber()] = true
+
+		if pullRequest.GetDraft() || !d.userService.IsTeamMember(pullRequest.GetUser().GetLogin()) {
+			continue
+		}
+		d.checkPullRequest(ctx, owner, repo, pullRequest.GetNumber())
+	}
+}
+
+func (d *Detector) checkPullRequest(ctx context.Context, owner, repo string, number int) {
+	for attempt := 1; attempt <= d.attempts; attempt++ {
+		pullRequest, err := d.githubConnector.GetPullRequest(ctx, owner, repo, number)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get pull request", slog.String("repo", repo), slog.Int("number", number), slog.Any("error", err))
+			return
+		}
+		current := mergeStatus(pullRequest)
+		if current != statusUnknown {
+			d.updateNotice(ctx, pullRequest, current)
+			return
+		}
+		if attempt < d.attempts {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(d.retryDelay):
+			}
+		}
+	}
+	slog.WarnContext(ctx, "GitHub did not compute mergeability in time", slog.String("repo", repo), slog.Int("number", number))
+}
+
+func (d *Detector) updateNotice(ctx context.Context, pullRequest *gh.PullRequest, current status) {
+	url := pullRequest.GetHTMLURL()
+	unlock := d.lock(url)
+	defer unlock()
+
+	d.mutex.Lock()
+	previous := d.notices[url]
+	emoji := d.emoji
+	d.mutex.Unlock()
+	if previous.status == current {
+		return
+	}
+
+	messageKey := fmt.Sprintf("<%s>", pullRequest.GetHTMLURL())
+	slackMessage, err := d.slackConnector.GetMessage(ctx, messageKey)
+	if err != nil {
+		slog.ErrorContext(ctx, "Could not find message", slog.Any("messageKey", messageKey), slog.Any("error", err))
+		return
+	}
+
+	if previous.status != statusClean {
+		d.slackConnector.RemoveReactionFromMessage(ctx, previous.reaction, slackMessage)
+		if previous.replyTimestamp != "" {
+			d.slackConnector.DeleteMessage(ctx, previous.replyTimestamp)
+		}
+	}
+
+	if current == statusClean {
+		d.mutex.Lock()
+		delete(d.notices, url)
+		d.mutex.Unlock()
+		return
+	}
+
+	reaction := reactionFor(emoji, current)
+	d.slackConnector.AddReactionToMessage(ctx, reaction, slackMessage)
+	userDescriptor := d.userService.GetUserDescriptor(ctx, pullRequest.GetUser().GetLogin())
+	var reply string
+	if current == statusConflicted {
+		reply = d.messageBuilder.BuildConflictMessage(userDescriptor, pullRequest)
+	} else {
+		reply = d.messageBuilder.BuildBehindMessage(userDescriptor, pullRequest)
+	}
+	replyTimestamp := d.slackConnector.SendReply(ctx, slackMessage, reply)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.notices[url] = notice{
+		status:         current,
+		reaction:       reaction,
+		replyTimestamp: replyTimestamp,
+	}
+}
+
+// lock waits until no other notice update of the pull request of url is running and returns the function that ends
+// this one.
+func (d *Detector) lock(url string) func() {
+	d.mutex.Lock()
+	pullRequest, ok := d.updating[url]
+	if !ok {
+		pullRequest = &pullRequestLock{}
+		d.updating[url] = pullRequest
+	}
+	pullRequest.holders++
+	d.mutex.Unlock()
+
+	pullRequest.mutex.Lock()
+	return func() {
+		pullRequest.mutex.Unlock()
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		pullRequest.holders--
+		if pullRequest.holders == 0 {
+			delete(d.updating, url)
+		}
+	}
+}
+
+func reactionFor(emoji config.EmojiConfiguration, s status) string {
+	if s == statusConflicted {
+		return emoji.Conflict
+	}
+	return emoji.Behind
+}
+
+func mergeStatus(pullRequest *gh.PullRequest) status {
+	if pullRequest.Mergeable == nil {
+		return statusUnknown
+	}
+	switch status(pullRequest.GetMergeableState()) {
+	case statusConflicted:
+		return statusConflicted
+	case statusBehind:
+		return statusBehind
+	case statusUnknown:
+		return statusUnknown
+	}
+	if !pullRequest.GetMergeable() {
+		return statusConflicted
+	}
+	return statusClean
+}
@@ -0,0 +1,234 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package conflict_test
+
+import (
+	"context"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/conflict"
+	mock_github "git-slack-bot/internal/github/mocks"
+	mock_slack "git-slack-bot/internal/slack/mocks"
+	mock_user "git-slack-bot/internal/user/mocks"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v56/github"
+	"github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestConflict(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Conflict detector tests")
+}
+
+var _ = Describe("CheckBranch", func() {
+	var (
+		mockCtrl     *gomock.Controller
+		githubMock   *mock_github.MockInteractor
+		slackMock    *mock_slack.MockInteractor
+		userMock     *mock_user.MockService
+		detector     *conflict.Detector
+		slackMessage *slack.Message
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		githubMock = mock_github.NewMockInteractor(mockCtrl)
+		slackMock = mock_slack.NewMockInteractor(mockCtrl)
+		userMock = mock_user.NewMockService(mockCtrl)
+		detector = conflict.NewDetectorWithRetry(githubMock, slackMock, userMock, config.EmojiConfiguration{
+			Conflict: "warning",
+			Behind:   "arrows_counterclockwise",
+		}, 2, 0)
+		slackMessage = &slack.Message{}
+
+		githubMock.EXPECT().ListOpenPullRequests(gomock.Any(), "org", "repo", "main", "").Return([]*gh.PullRequest{pullRequest("", nil)}, nil).AnyTimes()
+		githubMock.EXPECT().ListOpenPullRequests(gomock.Any(), "org", "repo", "", "main").Return(nil, nil).AnyTimes()
+		userMock.EXPECT().IsTeamMember("author").Return(true).AnyTimes()
+		userMock.EXPECT().GetUserDescriptor(gomock.Any(), "author").Return("<@123>").AnyTimes()
+	})
+
+	It("should post a notice when a pull request becomes conflicted", func() {
+		githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 42).Return(pullRequest("dirty", gh.Bool(false)), nil)
+		slackMock.EXPECT().GetMessage(gomock.Any(), "<https://github.com/org/repo/pull/42>").Return(slackMessage, nil)
+		slackMock.EXPECT().AddReactionToMessage(gomock.Any(), "warning", slackMessage)
+		slackMock.EXPECT().SendReply(gomock.Any(), slackMessage, "<@123> this PR has merge conflicts with `main` that need to be resolved before it can be merged").Return("1700000000.000100")
+
+		detector.CheckBranch(context.Background(), "org", "repo", "main")
+	})
+
+	It("should post a notice when a pull request falls behind its base", func() {
+		githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 42).Return(pullRequest("behind", gh.Bool(true)), nil)
+		slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(slackMessage, nil)
+		slackMock.EXPECT().AddReactionToMessage(gomock.Any(), "arrows_counterclockwise", slackMessage)
+		slackMock.EXPECT().SendReply(gomock.Any(), slackMessage, "<@123> this PR is behind `main` and needs to be updated before it can be merged").Return("1700000000.000100")
+
+		detector.CheckBranch(context.Background(), "org", "repo", "main")
+	})
+
+	It("should not post a notice twice", func() {
+		githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 42).Return(pullRequest("dirty", gh.Bool(false)), nil).Times(2)
+		slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(slackMessage, nil).Times(1)
+		slackMock.EXPECT().AddReactionToMessage(gomock.Any(), "warning", slackMessage).Times(1)
+		slackMock.EXPECT().SendReply(gomock.Any(), slackMessage, gomock.Any()).Return("1700000000.000100").Times(1)
+
+		detector.CheckBranch(context.Background(), "org", "repo", "main")
+		detector.CheckBranch(context.Background(), "org", "repo", "main")
+	})
+
+	It("should clear the notice once the conflict is resolved", func() {
+		gomock.InOrder(
+			githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 42).Return(pullRequest("dirty", gh.Bool(false)), nil),
+			githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 42).Return(pullRequest("clean", gh.Bool(true)), nil),
+		)
+		slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(slackMessage, nil).Times(2)
+		slackMock.EXPECT().AddReactionToMessage(gomock.Any(), "warning", slackMessage)
+		slackMock.EXPECT().SendReply(gomock.Any(), slackMessage, gomock.Any()).Return("1700000000.000100")
+		slackMock.EXPECT().RemoveReactionFromMessage(gomock.Any(), "warning", slackMessage)
+		slackMock.EXPECT().DeleteMessage(gomock.Any(), "1700000000.000100")
+
+		detector.CheckBranch(context.Background(), "org", "repo", "main")
+		detector.CheckBranch(context.Background(), "org", "repo", "main")
+	})
+
+	It("should clear a notice with the emoji it was posted with after a reload", func() {
+		gomock.InOrder(
+			githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 42).Return(pullRequest("dirty", gh.Bool(false)), nil),
+			githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 42).Return(pullRequest("clean", gh.Bool(true)), nil),
+		)
+		slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(slackMessage, nil).Times(2)
+		slackMock.EXPECT().AddReactionToMessage(gomock.Any(), "warning", slackMessage)
+		slackMock.EXPECT().SendReply(gomock.Any(), slackMessage, gomock.Any()).Return("1700000000.000100")
+		slackMock.EXPECT().RemoveReactionFromMessage(gomock.Any(), "warning", slackMessage)
+		slackMock.EXPECT().DeleteMessage(gomock.Any(), "1700000000.000100")
+
+		detector.CheckBranch(context.Background(), "org", "repo", "main")
+		detector.Reload(config.EmojiConfiguration{Conflict: "x", Behind: "hourglass"})
+		detector.CheckBranch(context.Background(), "org", "repo", "main")
+	})
+
+	It("should not hold up a reload while posting a notice", func() {
+		githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 42).Return(pullRequest("dirty", gh.Bool(false)), nil)
+		slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(slackMessage, nil)
+		slackMock.EXPECT().AddReactionToMessage(gomock.Any(), "warning", slackMessage)
+		slackMock.EXPECT().SendReply(gomock.Any(), slackMessage, gomock.Any()).DoAndReturn(func(context.Context, *slack.Message, string) string {
+			detector.Reload(config.EmojiConfiguration{Conflict: "x", Behind: "hourglass"})
+			return "1700000000.000100"
+		})
+
+		detector.CheckBranch(context.Background(), "org", "repo", "main")
+	})
+
+	It("should retry while GitHub is computing mergeability", func() {
+		gomock.InOrder(
+			githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 42).Return(pullRequest("unknown", nil), nil),
+			githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 42).Return(pullRequest("dirty", gh.Bool(false)), nil),
+		)
+		slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(slackMessage, nil)
+		slackMock.EXPECT().AddReactionToMessage(gomock.Any(), "warning", slackMessage)
+		slackMock.EXPECT().SendReply(gomock.Any(), slackMessage, gomock.Any()).Return("1700000000.000100")
+
+		detector.CheckBranch(context.Background(), "org", "repo", "main")
+	})
+
+	It("should give up if GitHub does not compute mergeability in time", func() {
+		githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 42).Return(pullRequest("unknown", nil), nil).Times(2)
+		slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Times(0)
+
+		detector.CheckBranch(context.Background(), "org", "repo", "main")
+	})
+
+	It("should stop retrying once the context is cancelled", func() {
+		detector = conflict.NewDetectorWithRetry(githubMock, slackMock, userMock, config.EmojiConfiguration{}, 2, time.Hour)
+		ctx, cancel := context.WithCancel(context.Background())
+		githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 42).DoAndReturn(func(_ context.Context, _, _ string, _ int) (*gh.PullRequest, error) {
+			cancel()
+			return pullRequest("unknown", nil), nil
+		})
+
+		detector.CheckBranch(ctx, "org", "repo", "main")
+	})
+
+	It("should ignore clean pull requests without a notice", func() {
+		githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 42).Return(pullRequest("clean", gh.Bool(true)), nil)
+		slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Times(0)
+
+		detector.CheckBranch(context.Background(), "org", "repo", "main")
+	})
+})
+
+var _ = Describe("CheckBranch filtering", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		githubMock *mock_github.MockInteractor
+		userMock   *mock_user.MockService
+		detector   *conflict.Detector
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		githubMock = mock_github.NewMockInteractor(mockCtrl)
+		userMock = mock_user.NewMockService(mockCtrl)
+		detector = conflict.NewDetectorWithRetry(githubMock, mock_slack.NewMockInteractor(mockCtrl), userMock, config.EmojiConfiguration{}, 1, 0)
+	})
+
+	It("should not check pull requests of non team members", func() {
+		githubMock.EXPECT().ListOpenPullRequests(gomock.Any(), "org", "repo", "main", "").Return([]*gh.PullRequest{pullRequest("", nil)}, nil)
+		githubMock.EXPECT().ListOpenPullRequests(gomock.Any(), "org", "repo", "", "main").Return(nil, nil)
+		userMock.EXPECT().IsTeamMember("author").Return(false)
+		githubMock.EXPECT().GetPullRequest(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		detector.CheckBranch(context.Background(), "org", "repo", "main")
+	})
+
+	It("should not check draft pull requests", func() {
+		draft := pullRequest("", nil)
+		draft.Draft = gh.Bool(true)
+		githubMock.EXPECT().ListOpenPullRequests(gomock.Any(), "org", "repo", "main", "").Return([]*gh.PullRequest{draft}, nil)
+		githubMock.EXPECT().ListOpenPullRequests(gomock.Any(), "org", "repo", "", "main").Return(nil, nil)
+		githubMock.EXPECT().GetPullRequest(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		detector.CheckBranch(context.Background(), "org", "repo", "main")
+	})
+
+	It("should check a pull request listed for both base and head only once", func() {
+		githubMock.EXPECT().ListOpenPullRequests(gomock.Any(), "org", "repo", "main", "").Return([]*gh.PullRequest{pullRequest("", nil)}, nil)
+		githubMock.EXPECT().ListOpenPullRequests(gomock.Any(), "org", "repo", "", "main").Return([]*gh.PullRequest{pullRequest("", nil)}, nil)
+		userMock.EXPECT().IsTeamMember("author").Return(true)
+		githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 42).Return(pullRequest("clean", gh.Bool(true)), nil).Times(1)
+
+		detector.CheckBranch(context.Background(), "org", "repo", "main")
+	})
+})
+
+func pullRequest(mergeableState string, mergeable *bool) *gh.PullRequest {
+	return &gh.PullRequest{
+		Number:         gh.Int(42),
+		HTMLURL:        gh.String("https://github.com/org/repo/pull/42"),
+		User:           &gh.User{Login: gh.String("author")},
+		Base:           &gh.PullRequestBranch{Ref: gh.String("main")},
+		Mergeable:      mergeable,
+		MergeableState: gh.String(mergeableState),
+	}
+}
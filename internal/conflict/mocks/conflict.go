@@ -0,0 +1,53 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: git-slack-bot/internal/conflict (interfaces: Checker)
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mocks/conflict.go . Checker
+//
+
+// Package mock_conflict is a generated GoMock package.
+package mock_conflict
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockChecker is a mock of Checker interface.
+type MockChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockCheckerMockRecorder
+	isgomock struct{}
+}
+
+// MockCheckerMockRecorder is the mock recorder for MockChecker.
+type MockCheckerMockRecorder struct {
+	mock *MockChecker
+}
+
+// NewMockChecker creates a new mock instance.
+func NewMockChecker(ctrl *gomock.Controller) *MockChecker {
+	mock := &MockChecker{ctrl: ctrl}
+	mock.recorder = &MockCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChecker) EXPECT() *MockCheckerMockRecorder {
+	return m.recorder
+}
+
+// CheckBranch mocks base method.
+func (m *MockChecker) CheckBranch(ctx context.Context, owner, repo, branch string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CheckBranch", ctx, owner, repo, branch)
+}
+
+// CheckBranch indicates an expected call of CheckBranch.
+func (mr *MockCheckerMockRecorder) CheckBranch(ctx, owner, repo, branch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckBranch", reflect.TypeOf((*MockChecker)(nil).CheckBranch), ctx, owner, repo, branch)
+}
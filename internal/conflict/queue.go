@@ -0,0 +1,101 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package conflict
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// DefaultWorkers is how many branches a Queue checks at the same time by default.
+const DefaultWorkers = 4
+
+const queueSize = 100
+
+type branchRef struct {
+	owner  string
+	repo   string
+	branch string
+}
+
+// Queue checks branches with a Checker in the background, a few at a time, so that pushes don't pile up requests to
+// GitHub. A branch pushed to again before its check started is only checked once. Branches are dropped while the
+// queue is full, and the checks stop once the context given to Run is cancelled.
+type Queue struct {
+	checker  Checker
+	branches chan branchRef
+	mutex    sync.Mutex
+	pending  map[branchRef]bool
+}
+
+func NewQueue(checker Checker) *Queue {
+	return &Queue{
+		checker:  checker,
+		branches: make(chan branchRef, queueSize),
+		pending:  make(map[branchRef]bool),
+	}
+}
+
+// CheckBranch queues a check of branch and returns at once.
+func (q *Queue) CheckBranch(ctx context.Context, owner, repo, branch string) {
+	ref := branchRef{owner: owner, repo: repo, branch: branch}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.pending[ref] {
+		return
+	}
+	select {
+	case q.branches <- ref:
+		q.pending[ref] = true
+	default:
+		slog.WarnContext(ctx, "Too many branches waiting to be checked for conflicts, skipping", slog.String("repo", repo), slog.String("branch", branch))
+	}
+}
+
+// Run checks the queued branches with workers goroutines until ctx is cancelled.
+func (q *Queue) Run(ctx context.Context, workers int) {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case ref := <-q.branches:
+					// Both cases may be ready at once, in which case select picks either.
+					if ctx.Err() != nil {
+						return
+					}
+					q.mutex.Lock()
+					delete(q.pending, ref)
+					q.mutex.Unlock()
+					q.checker.CheckBranch(ctx, ref.owner, ref.repo, ref.branch)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
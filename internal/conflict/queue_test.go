@@ -0,0 +1,113 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package conflict_test
+
+import (
+	"context"
+	"git-slack-bot/internal/conflict"
+	mock_conflict "git-slack-bot/internal/conflict/mocks"
+
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Queue", func() {
+	var (
+		mockCtrl    *gomock.Controller
+		checkerMock *mock_conflict.MockChecker
+		queue       *conflict.Queue
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		checkerMock = mock_conflict.NewMockChecker(mockCtrl)
+		queue = conflict.NewQueue(checkerMock)
+	})
+
+	run := func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			queue.Run(ctx, 2)
+		}()
+		DeferCleanup(func() {
+			cancel()
+			Eventually(stopped).Should(BeClosed())
+		})
+	}
+
+	It("should check queued branches in the background", func() {
+		checked := make(chan string, 2)
+		checkerMock.EXPECT().CheckBranch(gomock.Any(), "org", "repo", gomock.Any()).Do(func(_ context.Context, _, _, branch string) {
+			checked <- branch
+		}).Times(2)
+
+		queue.CheckBranch(context.Background(), "org", "repo", "main")
+		queue.CheckBranch(context.Background(), "org", "repo", "feature")
+		run()
+
+		Eventually(checked).Should(Receive())
+		Eventually(checked).Should(Receive())
+	})
+
+	It("should check a branch pushed to several times before its check started once", func() {
+		checked := make(chan struct{}, 3)
+		checkerMock.EXPECT().CheckBranch(gomock.Any(), "org", "repo", "main").Do(func(context.Context, string, string, string) {
+			checked <- struct{}{}
+		})
+
+		queue.CheckBranch(context.Background(), "org", "repo", "main")
+		queue.CheckBranch(context.Background(), "org", "repo", "main")
+		queue.CheckBranch(context.Background(), "org", "repo", "main")
+		run()
+
+		Eventually(checked).Should(Receive())
+		Consistently(checked).ShouldNot(Receive())
+	})
+
+	It("should check a branch again when pushed to after its check started", func() {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		checkerMock.EXPECT().CheckBranch(gomock.Any(), "org", "repo", "main").Do(func(context.Context, string, string, string) {
+			started <- struct{}{}
+			<-release
+		}).Times(2)
+		run()
+
+		queue.CheckBranch(context.Background(), "org", "repo", "main")
+		Eventually(started).Should(Receive())
+		queue.CheckBranch(context.Background(), "org", "repo", "main")
+		close(release)
+
+		Eventually(started).Should(Receive())
+	})
+
+	It("should stop checking once the context is cancelled", func() {
+		checkerMock.EXPECT().CheckBranch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		queue.CheckBranch(context.Background(), "org", "repo", "main")
+		queue.Run(ctx, 2)
+	})
+})
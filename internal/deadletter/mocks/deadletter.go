@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: git-slack-bot/internal/deadletter (interfaces: Deliverer)
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mocks/deadletter.go . Deliverer
+//
+
+// Package mock_deadletter is a generated GoMock package.
+package mock_deadletter
+
+import (
+	context "context"
+	deadletter "git-slack-bot/internal/deadletter"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDeliverer is a mock of Deliverer interface.
+type MockDeliverer struct {
+	ctrl     *gomock.Controller
+	recorder *MockDelivererMockRecorder
+	isgomock struct{}
+}
+
+// MockDelivererMockRecorder is the mock recorder for MockDeliverer.
+type MockDelivererMockRecorder struct {
+	mock *MockDeliverer
+}
+
+// NewMockDeliverer creates a new mock instance.
+func NewMockDeliverer(ctrl *gomock.Controller) *MockDeliverer {
+	mock := &MockDeliverer{ctrl: ctrl}
+	mock.recorder = &MockDelivererMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeliverer) EXPECT() *MockDelivererMockRecorder {
+	return m.recorder
+}
+
+// Deliver mocks base method.
+func (m *MockDeliverer) Deliver(ctx context.Context, entry deadletter.Entry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deliver", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Deliver indicates an expected call of Deliver.
+func (mr *MockDelivererMockRecorder) Deliver(ctx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deliver", reflect.TypeOf((*MockDeliverer)(nil).Deliver), ctx, entry)
+}
@@ -0,0 +1,156 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package deadletter
+
+//go:generate mockgen -destination=./mocks/deadletter.go . Deliverer
+
+import (
+	"context"
+	"git-slack-bot/internal/metrics"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	defaultInterval    = time.Minute
+	defaultMaxAttempts = 10
+	maxBackoff         = time.Hour
+)
+
+// Deliverer makes the operation of an entry again.
+type Deliverer interface {
+	Deliver(ctx context.Context, entry Entry) error
+}
+
+// Retrier delivers the entries of a store again, backing off exponentially from interval between the attempts at an
+// entry. Entries which failed maxAttempts times are kept, but only retried on request.
+type Retrier struct {
+	store       *Store
+	deliverer   Deliverer
+	interval    time.Duration
+	maxAttempts int
+	now         func() time.Time
+	// mutex keeps an entry from being delivered by the background retry and on request at the same time.
+	mutex sync.Mutex
+}
+
+// NewRetrier creates a Retrier. A zero interval defaults to a minute, and zero maxAttempts to 10.
+func NewRetrier(store *Store, deliverer Deliverer, interval time.Duration, maxAttempts int) *Retrier {
+	return NewRetrierWithClock(store, deliverer, interval, maxAttempts, time.Now)
+}
+
+// NewRetrierWithClock is NewRetrier with the clock used to time the attempts.
+func NewRetrierWithClock(store *Store, deliverer Deliverer, interval time.Duration, maxAttempts int, now func() time.Time) *Retrier {
+	if interval == 0 {
+		interval = defaultInterval
+	}
+	if maxAttempts == 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	return &Retrier{
+		store:       store,
+		deliverer:   deliverer,
+		interval:    interval,
+		maxAttempts: maxAttempts,
+		now:         now,
+	}
+}
+
+// Run retries the entries that are due every interval until ctx is cancelled.
+func (r *Retrier) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.RetryDue(ctx)
+		}
+	}
+}
+
+// RetryDue retries the entries whose backoff has passed and that have attempts left.
+func (r *Retrier) RetryDue(ctx context.Context) {
+	for _, entry := range r.store.List() {
+		if entry.Attempts >= r.maxAttempts || r.now().Before(entry.LastAttemptAt.Add(r.backoff(entry.Attempts))) {
+			continue
+		}
+		err := r.Retry(ctx, entry.ID)
+		if err != nil && ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// Retry delivers the entry with id again, removing it if that works. It returns ErrNotFound if there is no such
+// entry.
+func (r *Retrier) Retry(ctx context.Context, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	entry, ok := r.store.Get(id)
+	if !ok {
+		return ErrNotFound
+	}
+
+	err := r.deliverer.Deliver(ctx, entry)
+	if err == nil {
+		metrics.DeadLetterRetries.WithLabelValues(metrics.DeadLetterDelivered).Inc()
+		slog.Info("Delivered dead letter", slog.String("id", entry.ID), slog.String("operation", entry.Operation), slog.String("prURL", entry.PRURL), slog.Int("attempts", entry.Attempts+1))
+		r.store.Remove(id)
+		return nil
+	}
+
+	metrics.DeadLetterRetries.WithLabelValues(metrics.DeadLetterFailed).Inc()
+	entry.Attempts++
+	entry.Error = err.Error()
+	entry.LastAttemptAt = r.now()
+	r.store.Update(entry)
+	if entry.Attempts == r.maxAttempts {
+		slog.Error("Giving up on dead letter until it is retried by hand", slog.String("id", entry.ID), slog.String("operation", entry.Operation), slog.String("prURL", entry.PRURL), slog.Any("error", err))
+	}
+	return err
+}
+
+// Discard removes the entry with id without delivering it. It returns ErrNotFound if there is no such entry.
+func (r *Retrier) Discard(id string) error {
+	if !r.store.Remove(id) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// List returns the entries, oldest first.
+func (r *Retrier) List() []Entry {
+	return r.store.List()
+}
+
+// backoff is the time to wait after the attempts so far, doubling from interval up to an hour.
+func (r *Retrier) backoff(attempts int) time.Duration {
+	backoff := r.interval
+	for range attempts - 1 {
+		backoff *= 2
+		if backoff >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,125 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package deadletter_test
+
+import (
+	"context"
+	"errors"
+	"git-slack-bot/internal/deadletter"
+	mock_deadletter "git-slack-bot/internal/deadletter/mocks"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Retrier", func() {
+	var (
+		deliverer *mock_deadletter.MockDeliverer
+		now       time.Time
+		store     *deadletter.Store
+		retrier   *deadletter.Retrier
+	)
+
+	clock := func() time.Time { return now }
+
+	add := func(text string) deadletter.Entry {
+		store.Add(deadletter.Entry{Operation: deadletter.OperationMessage, Channel: "C123", Payload: deadletter.Payload{Text: text}})
+		entries := store.List()
+		return entries[len(entries)-1]
+	}
+
+	BeforeEach(func() {
+		deliverer = mock_deadletter.NewMockDeliverer(gomock.NewController(GinkgoT()))
+		now = time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+		var err error
+		store, err = deadletter.NewStoreWithClock(filepath.Join(GinkgoT().TempDir(), "dead-letters.json"), clock)
+		Expect(err).ToNot(HaveOccurred())
+		retrier = deadletter.NewRetrierWithClock(store, deliverer, time.Minute, 3, clock)
+	})
+
+	It("removes entries that are delivered", func() {
+		entry := add("opened")
+		deliverer.EXPECT().Deliver(gomock.Any(), entry).Return(nil)
+
+		Expect(retrier.Retry(context.Background(), entry.ID)).To(Succeed())
+
+		Expect(retrier.List()).To(BeEmpty())
+	})
+
+	It("counts attempts that fail again", func() {
+		entry := add("opened")
+		deliverer.EXPECT().Deliver(gomock.Any(), entry).Return(errors.New("service_unavailable"))
+		now = now.Add(time.Minute)
+
+		Expect(retrier.Retry(context.Background(), entry.ID)).To(MatchError("service_unavailable"))
+
+		entry.Attempts = 2
+		entry.Error = "service_unavailable"
+		entry.LastAttemptAt = now
+		Expect(retrier.List()).To(Equal([]deadletter.Entry{entry}))
+	})
+
+	It("only retries entries whose backoff has passed", func() {
+		entry := add("opened")
+		retrier.RetryDue(context.Background())
+
+		now = now.Add(time.Minute)
+		deliverer.EXPECT().Deliver(gomock.Any(), gomock.Any()).Return(errors.New("service_unavailable"))
+		retrier.RetryDue(context.Background())
+
+		now = now.Add(time.Minute)
+		retrier.RetryDue(context.Background())
+
+		now = now.Add(time.Minute)
+		deliverer.EXPECT().Deliver(gomock.Any(), gomock.Any()).Return(nil)
+		retrier.RetryDue(context.Background())
+
+		_, ok := store.Get(entry.ID)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("stops retrying in the background after the maximum attempts", func() {
+		entry := add("opened")
+		deliverer.EXPECT().Deliver(gomock.Any(), gomock.Any()).Return(errors.New("service_unavailable")).Times(2)
+		for range 10 {
+			now = now.Add(time.Hour)
+			retrier.RetryDue(context.Background())
+		}
+
+		Expect(retrier.List()).To(ConsistOf(HaveField("Attempts", 3)))
+
+		deliverer.EXPECT().Deliver(gomock.Any(), gomock.Any()).Return(nil)
+		Expect(retrier.Retry(context.Background(), entry.ID)).To(Succeed())
+	})
+
+	It("discards entries", func() {
+		entry := add("opened")
+
+		Expect(retrier.Discard(entry.ID)).To(Succeed())
+
+		Expect(retrier.List()).To(BeEmpty())
+		Expect(retrier.Discard(entry.ID)).To(MatchError(deadletter.ErrNotFound))
+		Expect(retrier.Retry(context.Background(), entry.ID)).To(MatchError(deadletter.ErrNotFound))
+	})
+})
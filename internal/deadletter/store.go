@@ -0,0 +1,193 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package deadletter
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"git-slack-bot/internal/metrics"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Operations of slack that are kept when they fail.
+const (
+	OperationMessage        string = "message"
+	OperationAnnouncement   string = "announcement"
+	OperationDirectMessage  string = "direct_message"
+	OperationReply          string = "reply"
+	OperationAddReaction    string = "add_reaction"
+	OperationRemoveReaction string = "remove_reaction"
+)
+
+// ErrNotFound is returned for ids of entries that are not in the store, such as ones delivered in the meantime.
+var ErrNotFound = errors.New("dead letter not found")
+
+var pullRequestURL = regexp.MustCompile(`https://github\.com/[^/\s|>]+/[^/\s|>]+/pull/\d+`)
+
+// Entry is a slack operation that failed, with everything needed to make it again.
+type Entry struct {
+	ID            string    `json:"id"`
+	Operation     string    `json:"operation"`
+	Channel       string    `json:"channel"`
+	Payload       Payload   `json:"payload"`
+	PRURL         string    `json:"prURL,omitempty"`
+	Error         string    `json:"error"`
+	Attempts      int       `json:"attempts"`
+	FailedAt      time.Time `json:"failedAt"`
+	LastAttemptAt time.Time `json:"lastAttemptAt"`
+}
+
+// Payload is what the operation sends. Timestamp is the parent of a reply or the message of a reaction.
+type Payload struct {
+	Text      string          `json:"text,omitempty"`
+	Blocks    json.RawMessage `json:"blocks,omitempty"`
+	Timestamp string          `json:"timestamp,omitempty"`
+	Reaction  string          `json:"reaction,omitempty"`
+}
+
+// PullRequestURL returns the url of the first pull request linked in text, or an empty string.
+func PullRequestURL(text string) string {
+	return pullRequestURL.FindString(text)
+}
+
+// Store keeps the entries in a json file, which is replaced on every change so that they survive restarts.
+type Store struct {
+	file    string
+	mutex   sync.Mutex
+	entries []Entry
+	now     func() time.Time
+}
+
+// NewStore loads the entries of file, which need not exist yet.
+func NewStore(file string) (*Store, error) {
+	return NewStoreWithClock(file, time.Now)
+}
+
+// NewStoreWithClock is NewStore with the clock used to time the failures.
+func NewStoreWithClock(file string, now func() time.Time) (*Store, error) {
+	store := &Store{file: file, now: now}
+	data, err := os.ReadFile(file) //nolint:gosec // The file is picked in the configuration.
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead letters: %w", err)
+	}
+	err = json.Unmarshal(data, &store.entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dead letters in %s: %w", file, err)
+	}
+	metrics.DeadLetters.Set(float64(len(store.entries)))
+	return store, nil
+}
+
+// Add keeps entry as having failed once, now.
+func (s *Store) Add(entry Entry) {
+	id := make([]byte, 8)
+	_, err := rand.Read(id)
+	if err != nil {
+		id = fmt.Appendf(nil, "%d", s.now().UnixNano())
+	}
+	entry.ID = hex.EncodeToString(id)
+	entry.Attempts = 1
+	entry.FailedAt = s.now()
+	entry.LastAttemptAt = entry.FailedAt
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries = append(s.entries, entry)
+	s.save()
+}
+
+// List returns the entries, oldest first.
+func (s *Store) List() []Entry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return slices.Clone(s.entries)
+}
+
+// Get returns the entry with id.
+func (s *Store) Get(id string) (Entry, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	index := s.index(id)
+	if index < 0 {
+		return Entry{}, false
+	}
+	return s.entries[index], true
+}
+
+// Update replaces the entry with the id of entry, unless it has been removed.
+func (s *Store) Update(entry Entry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	index := s.index(entry.ID)
+	if index < 0 {
+		return
+	}
+	s.entries[index] = entry
+	s.save()
+}
+
+// Remove removes the entry with id and reports whether there was one.
+func (s *Store) Remove(id string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	index := s.index(id)
+	if index < 0 {
+		return false
+	}
+	s.entries = slices.Delete(s.entries, index, index+1)
+	s.save()
+	return true
+}
+
+func (s *Store) index(id string) int {
+	return slices.IndexFunc(s.entries, func(entry Entry) bool {
+		return entry.ID == id
+	})
+}
+
+// save replaces the file in one go, so that a crash never leaves half of it behind. It is called with the mutex held.
+func (s *Store) save() {
+	metrics.DeadLetters.Set(float64(len(s.entries)))
+	data, err := json.Marshal(s.entries)
+	if err != nil {
+		slog.Error("Failed to serialise dead letters", slog.Any("error", err))
+		return
+	}
+	tempFile := filepath.Join(filepath.Dir(s.file), "."+filepath.Base(s.file)+".tmp")
+	err = os.WriteFile(tempFile, data, 0o600)
+	if err == nil {
+		err = os.Rename(tempFile, s.file)
+	}
+	if err != nil {
+		slog.Error("Failed to save dead letters", slog.String("file", s.file), slog.Any("error", err))
+	}
+}
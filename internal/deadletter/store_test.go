@@ -0,0 +1,115 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package deadletter_test
+
+import (
+	"git-slack-bot/internal/deadletter"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDeadLetter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Dead letter tests")
+}
+
+var _ = Describe("Store", func() {
+	var (
+		file  string
+		now   time.Time
+		store *deadletter.Store
+	)
+
+	BeforeEach(func() {
+		file = filepath.Join(GinkgoT().TempDir(), "dead-letters.json")
+		now = time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+		var err error
+		store, err = deadletter.NewStoreWithClock(file, func() time.Time { return now })
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("keeps added entries across restarts", func() {
+		store.Add(deadletter.Entry{
+			Operation: deadletter.OperationAddReaction,
+			Channel:   "C123",
+			Payload:   deadletter.Payload{Timestamp: "1700000000.000100", Reaction: "merged"},
+			PRURL:     "https://github.com/loveholidays/frontier/pull/1",
+			Error:     "internal_error",
+		})
+
+		reopened, err := deadletter.NewStore(file)
+		Expect(err).ToNot(HaveOccurred())
+
+		entries := reopened.List()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].ID).ToNot(BeEmpty())
+		Expect(entries[0]).To(Equal(deadletter.Entry{
+			ID:            entries[0].ID,
+			Operation:     deadletter.OperationAddReaction,
+			Channel:       "C123",
+			Payload:       deadletter.Payload{Timestamp: "1700000000.000100", Reaction: "merged"},
+			PRURL:         "https://github.com/loveholidays/frontier/pull/1",
+			Error:         "internal_error",
+			Attempts:      1,
+			FailedAt:      now,
+			LastAttemptAt: now,
+		}))
+	})
+
+	It("updates and removes entries by id", func() {
+		store.Add(deadletter.Entry{Operation: deadletter.OperationMessage, Payload: deadletter.Payload{Text: "first"}})
+		store.Add(deadletter.Entry{Operation: deadletter.OperationMessage, Payload: deadletter.Payload{Text: "second"}})
+		first, second := store.List()[0], store.List()[1]
+
+		first.Attempts = 2
+		store.Update(first)
+		Expect(store.Remove(second.ID)).To(BeTrue())
+		Expect(store.Remove(second.ID)).To(BeFalse())
+		store.Update(second)
+
+		Expect(store.List()).To(Equal([]deadletter.Entry{first}))
+	})
+
+	It("starts empty without a file", func() {
+		Expect(store.List()).To(BeEmpty())
+		Expect(file).ToNot(BeAnExistingFile())
+	})
+
+	It("refuses to start from a broken file", func() {
+		Expect(os.WriteFile(file, []byte("[{"), 0o600)).To(Succeed())
+
+		_, err := deadletter.NewStore(file)
+
+		Expect(err).To(MatchError(ContainSubstring("failed to parse dead letters")))
+	})
+})
+
+var _ = Describe("PullRequestURL", func() {
+	It("finds the pull request linked in a message", func() {
+		Expect(deadletter.PullRequestURL("octocat opened Add dead letters:\nhttps://github.com/loveholidays/frontier/pull/42")).To(Equal("https://github.com/loveholidays/frontier/pull/42"))
+		Expect(deadletter.PullRequestURL("<https://github.com/loveholidays/frontier/pull/42|#42> merged")).To(Equal("https://github.com/loveholidays/frontier/pull/42"))
+		Expect(deadletter.PullRequestURL("no link")).To(BeEmpty())
+	})
+})
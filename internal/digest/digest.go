@@ -0,0 +1,140 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package digest
+
+import (
+	"context"
+	"fmt"
+	"git-slack-bot/internal/github"
+	messageBuilder "git-slack-bot/internal/messagebuilder"
+	"git-slack-bot/internal/slack"
+	"git-slack-bot/internal/tracing"
+	"git-slack-bot/internal/user"
+	"log/slog"
+	"time"
+)
+
+const defaultStaleAfter = 48 * time.Hour
+
+type ageBucket struct {
+	title  string
+	maxAge time.Duration
+}
+
+// ageBuckets are ordered oldest first, so that the PRs which have waited longest lead each review state.
+var ageBuckets = []ageBucket{
+	{title: "opened over a week ago", maxAge: -1},
+	{title: "opened this week", maxAge: 7 * 24 * time.Hour},
+	{title: "opened today", maxAge: 24 * time.Hour},
+}
+
+var reviewStates = []struct {
+	state github.ReviewState
+	title string
+}{
+	{state: github.ReviewPending, title: "Awaiting review"},
+	{state: github.ReviewChangesRequested, title: "Changes requested"},
+	{state: github.ReviewApproved, title: "Approved"},
+}
+
+// Digest posts a summary of the team's open pull requests to the channel.
+type Digest struct {
+	githubConnector github.Interactor
+	slackConnector  slack.Interactor
+	userService     user.Service
+	messageBuilder  messageBuilder.MessageBuilder
+	staleAfter      time.Duration
+}
+
+// NewDigest creates a Digest which highlights pull requests that have not been updated for staleAfter. A zero
+// staleAfter defaults to two days.
+func NewDigest(githubConnector github.Interactor, slackConnector slack.Interactor, userService user.Service, staleAfter time.Duration) *Digest {
+	if staleAfter == 0 {
+		staleAfter = defaultStaleAfter
+	}
+	return &Digest{
+		githubConnector: githubConnector,
+		slackConnector:  slackConnector,
+		userService:     userService,
+		messageBuilder:  messageBuilder.MessageBuilder{},
+		staleAfter:      staleAfter,
+	}
+}
+
+func (d *Digest) Post(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "digest.Post")
+	defer span.End()
+	d.PostAt(ctx, time.Now())
+}
+
+// PostAt posts the digest as it looks at now. Nothing is posted if the team has no open pull requests.
+func (d *Digest) PostAt(ctx context.Context, now time.Time) {
+	teamMembers := d.userService.GetTeamMembers()
+	if len(teamMembers) == 0 {
+		slog.Warn("Not posting digest, no team members known")
+		return
+	}
+
+	pullRequests, err := d.githubConnector.SearchOpenPullRequests(ctx, github.SearchQuery{Authors: teamMembers})
+	if err != nil {
+		slog.Error("Failed to search open pull requests", slog.Any("error", err))
+		return
+	}
+	if len(pullRequests) == 0 {
+		return
+	}
+
+	userDescriptors := make(map[string]string)
+	var groups []messageBuilder.DigestGroup
+	for _, review := range reviewStates {
+		for i, bucket := range ageBuckets {
+			group := messageBuilder.DigestGroup{Title: fmt.Sprintf("%s – %s", review.title, bucket.title)}
+			for _, pullRequest := range pullRequests {
+				age := now.Sub(pullRequest.CreatedAt)
+				if pullRequest.Review != review.state || bucketIndex(age) != i {
+					continue
+				}
+				if _, ok := userDescriptors[pullRequest.Author]; !ok {
+					userDescriptors[pullRequest.Author] = d.userService.GetUserDescriptor(ctx, pullRequest.Author)
+				}
+				group.Entries = append(group.Entries, messageBuilder.DigestEntry{
+					UserDescriptor: userDescriptors[pullRequest.Author],
+					PullRequest:    pullRequest,
+					Age:            age,
+					Stale:          pullRequest.IsStale(now, d.staleAfter),
+				})
+			}
+			if len(group.Entries) > 0 {
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	d.slackConnector.SendMessage(ctx, d.messageBuilder.BuildDigestMessage(groups))
+}
+
+func bucketIndex(age time.Duration) int {
+	for i := len(ageBuckets) - 1; i > 0; i-- {
+		if age < ageBuckets[i].maxAge {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,118 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package digest_test
+
+import (
+	"context"
+	"errors"
+	"git-slack-bot/internal/digest"
+	"git-slack-bot/internal/github"
+	mock_github "git-slack-bot/internal/github/mocks"
+	mock_slack "git-slack-bot/internal/slack/mocks"
+	mock_user "git-slack-bot/internal/user/mocks"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDigest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Digest tests")
+}
+
+var _ = Describe("PostAt", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		githubMock *mock_github.MockInteractor
+		slackMock  *mock_slack.MockInteractor
+		userMock   *mock_user.MockService
+		now        time.Time
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		githubMock = mock_github.NewMockInteractor(mockCtrl)
+		slackMock = mock_slack.NewMockInteractor(mockCtrl)
+		userMock = mock_user.NewMockService(mockCtrl)
+		now = time.Date(2025, time.March, 5, 9, 0, 0, 0, time.UTC)
+	})
+
+	It("should post open pull requests grouped by review state and age", func() {
+		userMock.EXPECT().GetTeamMembers().Return([]string{"alice", "bob"})
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), github.SearchQuery{Authors: []string{"alice", "bob"}}).Return([]*github.OpenPullRequest{
+			{Title: "Old", URL: "https://github.com/org/repo/pull/1", Repo: "repo", Author: "alice", CreatedAt: now.Add(-10 * 24 * time.Hour), UpdatedAt: now.Add(-3 * 24 * time.Hour), Review: github.ReviewPending},
+			{Title: "Recent", URL: "https://github.com/org/repo/pull/2", Repo: "repo", Author: "bob", CreatedAt: now.Add(-2 * time.Hour), UpdatedAt: now.Add(-time.Hour), Review: github.ReviewPending},
+			{Title: "Approved", URL: "https://github.com/org/repo/pull/3", Repo: "repo", Author: "alice", CreatedAt: now.Add(-3 * 24 * time.Hour), UpdatedAt: now.Add(-time.Hour), Review: github.ReviewApproved},
+		}, nil)
+		userMock.EXPECT().GetUserDescriptor(gomock.Any(), "alice").Return("<@A>").Times(1)
+		userMock.EXPECT().GetUserDescriptor(gomock.Any(), "bob").Return("<@B>").Times(1)
+
+		expected := "*Open PRs: 3*\n\n" +
+			"*Awaiting review – opened over a week ago*\n" +
+			"• <https://github.com/org/repo/pull/1|Old> in `repo` by <@A>, opened 10 days ago :hourglass_flowing_sand: *stale*\n\n" +
+			"*Awaiting review – opened today*\n" +
+			"• <https://github.com/org/repo/pull/2|Recent> in `repo` by <@B>, opened today\n\n" +
+			"*Approved – opened this week*\n" +
+			"• <https://github.com/org/repo/pull/3|Approved> in `repo` by <@A>, opened 3 days ago"
+		slackMock.EXPECT().SendMessage(gomock.Any(), expected)
+
+		digest.NewDigest(githubMock, slackMock, userMock, 0).PostAt(context.Background(), now)
+	})
+
+	It("should use the configured stale threshold", func() {
+		userMock.EXPECT().GetTeamMembers().Return([]string{"alice"})
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return([]*github.OpenPullRequest{
+			{Title: "Quiet", URL: "https://github.com/org/repo/pull/1", Repo: "repo", Author: "alice", CreatedAt: now.Add(-5 * time.Hour), UpdatedAt: now.Add(-5 * time.Hour), Review: github.ReviewPending},
+		}, nil)
+		userMock.EXPECT().GetUserDescriptor(gomock.Any(), "alice").Return("<@A>")
+		slackMock.EXPECT().SendMessage(gomock.Any(), gomock.Any()).Do(func(_ context.Context, message string) {
+			Expect(message).To(ContainSubstring("*stale*"))
+		})
+
+		digest.NewDigest(githubMock, slackMock, userMock, 4*time.Hour).PostAt(context.Background(), now)
+	})
+
+	It("should not post if there are no open pull requests", func() {
+		userMock.EXPECT().GetTeamMembers().Return([]string{"alice"})
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return(nil, nil)
+		slackMock.EXPECT().SendMessage(gomock.Any(), gomock.Any()).Times(0)
+
+		digest.NewDigest(githubMock, slackMock, userMock, 0).PostAt(context.Background(), now)
+	})
+
+	It("should not post if searching fails", func() {
+		userMock.EXPECT().GetTeamMembers().Return([]string{"alice"})
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return(nil, errors.New("rate limited"))
+		slackMock.EXPECT().SendMessage(gomock.Any(), gomock.Any()).Times(0)
+
+		digest.NewDigest(githubMock, slackMock, userMock, 0).PostAt(context.Background(), now)
+	})
+
+	It("should not search without team members", func() {
+		userMock.EXPECT().GetTeamMembers().Return(nil)
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Times(0)
+
+		digest.NewDigest(githubMock, slackMock, userMock, 0).PostAt(context.Background(), now)
+	})
+})
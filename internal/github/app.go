@@ -0,0 +1,188 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// jwtLifetime stays under the 10 minutes GitHub accepts, leaving room for clock drift.
+	jwtLifetime = 9 * time.Minute
+	// tokenEarlyExpiry is how long before an installation token expires that a new one is minted.
+	tokenEarlyExpiry = 5 * time.Minute
+)
+
+// App mints the installation tokens of a GitHub App. Installation tokens expire after an hour, so rather than a
+// token it hands out token sources, which mint a new one when the previous is about to expire.
+type App struct {
+	client    *github.Client
+	transport http.RoundTripper
+}
+
+// NewApp creates an App authenticating with its id and PEM encoded private key.
+func NewApp(appID int64, privateKey []byte) (*App, error) {
+	return NewAppWithURL(http.DefaultClient, "", appID, privateKey)
+}
+
+// NewAppWithURL creates an App which calls the api at apiURL, or github.com's if it is empty, with httpClient.
+func NewAppWithURL(httpClient *http.Client, apiURL string, appID int64, privateKey []byte) (*App, error) {
+	key, err := parsePrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	client := github.NewClient(&http.Client{
+		Transport: &appTransport{appID: appID, key: key, base: transport},
+		Timeout:   httpClient.Timeout,
+	})
+	if apiURL != "" {
+		client.BaseURL, err = url.Parse(apiURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &App{client: client, transport: transport}, nil
+}
+
+// InstallationID returns the id of the app's installation in org.
+func (a *App) InstallationID(ctx context.Context, org string) (int64, error) {
+	installation, _, err := a.client.Apps.FindOrganizationInstallation(ctx, org)
+	if err != nil {
+		return 0, fmt.Errorf("could not find the installation of the app in %q: %w", org, err)
+	}
+	return installation.GetID(), nil
+}
+
+// TokenSource returns a source of tokens of the installation, which are reused until shortly before they expire.
+func (a *App) TokenSource(installationID int64) oauth2.TokenSource {
+	return oauth2.ReuseTokenSourceWithExpiry(nil, &installationTokenSource{app: a, installationID: installationID}, tokenEarlyExpiry)
+}
+
+// installationClient returns a client authenticated as the installation with installationID, or as the installation in
+// org if it is zero.
+func (a *App) installationClient(ctx context.Context, org string, installationID int64) (*github.Client, error) {
+	if installationID == 0 {
+		var err error
+		installationID, err = a.InstallationID(ctx, org)
+		if err != nil {
+			return nil, err
+		}
+	}
+	client := github.NewClient(&http.Client{Transport: &oauth2.Transport{Source: a.TokenSource(installationID), Base: a.transport}})
+	client.BaseURL = a.client.BaseURL
+	return client, nil
+}
+
+type installationTokenSource struct {
+	app            *App
+	installationID int64
+}
+
+func (s *installationTokenSource) Token() (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	token, _, err := s.app.client.Apps.CreateInstallationToken(ctx, s.installationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create a token of installation %d: %w", s.installationID, err)
+	}
+	return &oauth2.Token{AccessToken: token.GetToken(), TokenType: "token", Expiry: token.GetExpiresAt().Time}, nil
+}
+
+// appTransport authenticates requests as the app itself, with a JWT signed by its private key.
+type appTransport struct {
+	appID int64
+	key   *rsa.PrivateKey
+	base  http.RoundTripper
+}
+
+func (t *appTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	jwt, err := t.jwt(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	return t.base.RoundTrip(req)
+}
+
+func (t *appTransport) jwt(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		// Backdated as GitHub rejects tokens issued in its future.
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": strconv.FormatInt(t.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, t.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey parses the PKCS#1 key GitHub generates for apps, or the same key converted to PKCS#8.
+func parsePrivateKey(privateKey []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return nil, errors.New("the private key of the app is not PEM encoded")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the private key of the app: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("the private key of the app is not an RSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,186 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package github_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/github"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("App", func() {
+	var (
+		key        *rsa.PrivateKey
+		privateKey []byte
+		server     *httptest.Server
+		mints      atomic.Int32
+		tokenTTL   time.Duration
+		// commentedAs is the owner of the repository last commented on and the authorization it was done with.
+		commentedAs atomic.Value
+	)
+
+	// verifyJWT checks that the request is authenticated as app 123.
+	verifyJWT := func(r *http.Request) {
+		defer GinkgoRecover()
+		jwt, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		Expect(ok).To(BeTrue())
+		parts := strings.Split(jwt, ".")
+		Expect(parts).To(HaveLen(3))
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		Expect(err).ToNot(HaveOccurred())
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		Expect(rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature)).To(Succeed())
+		claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(claims)).To(ContainSubstring(`"iss":"123"`))
+	}
+
+	BeforeEach(func() {
+		var err error
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ToNot(HaveOccurred())
+		privateKey = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+		mints.Store(0)
+		tokenTTL = time.Hour
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /orgs/loveholidays/installation", func(w http.ResponseWriter, r *http.Request) {
+			verifyJWT(r)
+			Expect(w.Write([]byte(`{"id": 456}`))).Error().ToNot(HaveOccurred())
+		})
+		mux.HandleFunc("GET /orgs/unknown/installation", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+		})
+		mux.HandleFunc("POST /app/installations/{id}/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+			verifyJWT(r)
+			mint := mints.Add(1)
+			Expect(json.NewEncoder(w).Encode(map[string]any{
+				"token":      fmt.Sprintf("ghs_%s_%d", r.PathValue("id"), mint),
+				"expires_at": time.Now().Add(tokenTTL).UTC().Format(time.RFC3339),
+			})).To(Succeed())
+		})
+		mux.HandleFunc("GET /orgs/{org}", func(w http.ResponseWriter, r *http.Request) {
+			Expect(json.NewEncoder(w).Encode(map[string]any{"id": 1, "login": r.PathValue("org"), "description": r.Header.Get("Authorization")})).To(Succeed())
+		})
+		mux.HandleFunc("GET /orgs/{org}/teams", func(w http.ResponseWriter, r *http.Request) {
+			Expect(w.Write([]byte(`[{"id": 1, "name": "platform"}]`))).Error().ToNot(HaveOccurred())
+		})
+		mux.HandleFunc("POST /repos/{owner}/{repo}/issues/{number}/comments", func(w http.ResponseWriter, r *http.Request) {
+			commentedAs.Store(r.PathValue("owner") + " " + r.Header.Get("Authorization"))
+			Expect(w.Write([]byte(`{}`))).Error().ToNot(HaveOccurred())
+		})
+		server = httptest.NewServer(mux)
+		DeferCleanup(server.Close)
+	})
+
+	newClient := func(installations ...config.GitHubAppInstallation) *github.ExternalClient {
+		app, err := github.NewAppWithURL(server.Client(), server.URL+"/", 123, privateKey)
+		Expect(err).ToNot(HaveOccurred())
+		client, err := github.NewAppClient(context.Background(), app, "loveholidays", 0, installations)
+		Expect(err).ToNot(HaveOccurred())
+		return client
+	}
+
+	authorization := func(client *github.ExternalClient, org string) string {
+		organization, err := client.GetOrg(context.Background(), org)
+		Expect(err).ToNot(HaveOccurred())
+		return organization.GetDescription()
+	}
+
+	It("authenticates as the installation in the organization", func() {
+		client := newClient()
+
+		Expect(authorization(client, "loveholidays")).To(Equal("token ghs_456_1"))
+	})
+
+	It("reuses the installation token until it is about to expire", func() {
+		client := newClient()
+
+		Expect(authorization(client, "loveholidays")).To(Equal("token ghs_456_1"))
+		Expect(authorization(client, "loveholidays")).To(Equal("token ghs_456_1"))
+		Expect(mints.Load()).To(Equal(int32(1)))
+	})
+
+	It("mints a new token when the previous one is about to expire", func() {
+		tokenTTL = time.Minute
+		client := newClient()
+
+		Expect(authorization(client, "loveholidays")).To(Equal("token ghs_456_1"))
+		Expect(authorization(client, "loveholidays")).To(Equal("token ghs_456_2"))
+	})
+
+	It("authenticates as the installation in the owner of the resource", func() {
+		client := newClient(config.GitHubAppInstallation{Org: "Other-Org", ID: 789})
+
+		Expect(authorization(client, "other-org")).To(HavePrefix("token ghs_789_"))
+		Expect(authorization(client, "loveholidays")).To(HavePrefix("token ghs_456_"))
+		Expect(authorization(client, "elsewhere")).To(HavePrefix("token ghs_456_"))
+	})
+
+	It("routes calls about a pull request to the installation in the owner of its repository", func() {
+		client := newClient(config.GitHubAppInstallation{Org: "Other-Org", ID: 789})
+		connector, err := github.NewGitHubConnector(context.Background(), config.GitHubConfiguration{
+			Org:  "loveholidays",
+			Team: "platform",
+			App:  config.GitHubAppConfiguration{Installations: []config.GitHubAppInstallation{{Org: "Other-Org", ID: 789}}},
+		}, client)
+		Expect(err).ToNot(HaveOccurred())
+
+		owner, repo, number, err := github.ParsePullRequestURL("https://github.com/Other-Org/repo/pull/7")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connector.CreateIssueComment(context.Background(), owner, repo, number, "LGTM")).To(Succeed())
+		Expect(commentedAs.Load()).To(HavePrefix("Other-Org token ghs_789_"))
+
+		Expect(connector.CreateIssueComment(context.Background(), "loveholidays", "repo", 7, "LGTM")).To(Succeed())
+		Expect(commentedAs.Load()).To(HavePrefix("loveholidays token ghs_456_"))
+	})
+
+	It("fails when the app is not installed in the organization", func() {
+		app, err := github.NewAppWithURL(server.Client(), server.URL+"/", 123, privateKey)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = github.NewAppClient(context.Background(), app, "unknown", 0, nil)
+
+		Expect(err).To(MatchError(ContainSubstring(`could not find the installation of the app in "unknown"`)))
+	})
+
+	It("fails on a key that is not PEM encoded", func() {
+		_, err := github.NewApp(123, []byte("not a key"))
+
+		Expect(err).To(MatchError("the private key of the app is not PEM encoded"))
+	})
+})
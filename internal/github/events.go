@@ -0,0 +1,110 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+)
+
+const eventsPerPage = 100
+
+// EventsPage is a page of the organization's events that the authenticated user can see, newest first. If the page has not changed
+// since it was fetched with ETag, NotModified is set and there are no events.
+type EventsPage struct {
+	Events       []*github.Event
+	ETag         string
+	NotModified  bool
+	NextPage     int
+	PollInterval time.Duration
+	Rate         github.Rate
+}
+
+// ListOrganizationEvents lists the events of org as seen by the user of the token. Unlike orgs/{org}/events, which
+// only has the events of public repositories, this includes the private repositories the user can access. GitHub
+// Apps have no user, so they can't poll.
+func (c *ExternalClient) ListOrganizationEvents(ctx context.Context, org string, page int, etag string) (*EventsPage, error) {
+	login, err := c.userLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	request, err := c.client.NewRequest(http.MethodGet, fmt.Sprintf("users/%s/events/orgs/%s?per_page=%d&page=%d", login, org, eventsPerPage, page), nil)
+	if err != nil {
+		return nil, err
+	}
+	if etag != "" {
+		request.Header.Set("If-None-Match", etag)
+	}
+
+	var events []*github.Event
+	response, err := c.client.Do(ctx, request, &events)
+	if response == nil {
+		return nil, err
+	}
+	eventsPage := &EventsPage{
+		ETag:     response.Header.Get("ETag"),
+		NextPage: response.NextPage,
+		Rate:     response.Rate,
+	}
+	if seconds, parseErr := strconv.Atoi(response.Header.Get("X-Poll-Interval")); parseErr == nil {
+		eventsPage.PollInterval = time.Duration(seconds) * time.Second
+	}
+	if response.StatusCode == http.StatusNotModified {
+		eventsPage.NotModified = true
+		eventsPage.ETag = etag
+		return eventsPage, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	eventsPage.Events = events
+	return eventsPage, nil
+}
+
+// userLogin returns the login of the user the token belongs to.
+func (c *ExternalClient) userLogin(ctx context.Context) (string, error) {
+	if login := c.login.Load(); login != nil {
+		return *login, nil
+	}
+	if c.orgClients != nil {
+		return "", errors.New("polling for events needs a user token, a GitHub App can't list the events of private repositories")
+	}
+	user, _, err := c.client.Users.Get(ctx, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the user of the token: %w", err)
+	}
+	login := user.GetLogin()
+	c.login.Store(&login)
+	return login, nil
+}
+
+// ListOrganizationEvents fetches a page of the organization's events. Passing the ETag of an earlier fetch of the
+// page makes GitHub answer with NotModified, which does not count towards the rate limit, if nothing changed.
+func (ghc *Connector) ListOrganizationEvents(ctx context.Context, page int, etag string) (*EventsPage, error) {
+	ctx, cancel := ghc.withTimeout(ctx)
+	defer cancel()
+	return ghc.client.ListOrganizationEvents(ctx, ghc.repoOwner, page, etag)
+}
@@ -0,0 +1,144 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"git-slack-bot/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExternalClient.ListOrganizationEvents", func() {
+	var (
+		server *httptest.Server
+		client *ExternalClient
+	)
+
+	serve := func(handler http.HandlerFunc) {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/user" {
+				_, err := w.Write([]byte(`{"login":"octocat"}`))
+				Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			handler(w, r)
+		}))
+		baseURL, err := url.Parse(server.URL + "/")
+		Expect(err).ToNot(HaveOccurred())
+		githubClient := github.NewClient(nil)
+		githubClient.BaseURL = baseURL
+		client = &ExternalClient{client: githubClient}
+	}
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should return the events of private repositories the user can see with the etag and poll interval", func() {
+		serve(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/users/octocat/events/orgs/TestOrg"))
+			Expect(r.URL.Query().Get("page")).To(Equal("1"))
+			Expect(r.Header.Get("If-None-Match")).To(BeEmpty())
+			w.Header().Set("ETag", `"abc"`)
+			w.Header().Set("X-Poll-Interval", "60")
+			w.Header().Set("Link", `<`+server.URL+`/users/octocat/events/orgs/TestOrg?page=2>; rel="next"`)
+			_, err := w.Write([]byte(`[{"id":"2","type":"PushEvent"}]`))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		page, err := client.ListOrganizationEvents(context.Background(), "TestOrg", 1, "")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page.NotModified).To(BeFalse())
+		Expect(page.Events).To(HaveLen(1))
+		Expect(page.ETag).To(Equal(`"abc"`))
+		Expect(page.PollInterval).To(Equal(time.Minute))
+		Expect(page.NextPage).To(Equal(2))
+	})
+
+	It("should report pages that have not changed", func() {
+		serve(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("If-None-Match")).To(Equal(`"abc"`))
+			w.Header().Set("X-Poll-Interval", "60")
+			w.WriteHeader(http.StatusNotModified)
+		})
+
+		page, err := client.ListOrganizationEvents(context.Background(), "TestOrg", 1, `"abc"`)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page.NotModified).To(BeTrue())
+		Expect(page.Events).To(BeEmpty())
+		Expect(page.ETag).To(Equal(`"abc"`))
+	})
+
+	It("should refuse to poll as a GitHub App", func() {
+		serve(func(w http.ResponseWriter, r *http.Request) {
+			Fail("an app has no user to list events as")
+		})
+		client.orgClients = map[string]*github.Client{"testorg": client.client}
+
+		_, err := client.ListOrganizationEvents(context.Background(), "TestOrg", 1, "")
+
+		Expect(err).To(MatchError(ContainSubstring("needs a user token")))
+	})
+
+	It("should return error if listing fails", func() {
+		serve(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+
+		page, err := client.ListOrganizationEvents(context.Background(), "TestOrg", 1, "")
+
+		Expect(err).To(HaveOccurred())
+		Expect(page).To(BeNil())
+	})
+})
+
+var _ = Describe("NewClient", func() {
+	It("authenticates with the latest value of the token", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(json.NewEncoder(w).Encode(map[string]any{"login": "loveholidays", "description": r.Header.Get("Authorization")})).To(Succeed())
+		}))
+		DeferCleanup(server.Close)
+		token := "ghp_1"
+		client, err := NewClient(context.Background(), config.GitHubConfiguration{Org: "loveholidays"}, func() string { return token })
+		Expect(err).ToNot(HaveOccurred())
+		client.client.BaseURL, err = url.Parse(server.URL + "/")
+		Expect(err).ToNot(HaveOccurred())
+
+		organization, err := client.GetOrg(context.Background(), "loveholidays")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(organization.GetDescription()).To(Equal("Bearer ghp_1"))
+
+		token = "ghp_2"
+		organization, err = client.GetOrg(context.Background(), "loveholidays")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(organization.GetDescription()).To(Equal("Bearer ghp_2"))
+	})
+})
@@ -0,0 +1,220 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// feedbackQuery looks up the reviews and review threads of the pull requests a search finds.
+const feedbackQuery = `query($query: String!, $cursor: String) {
+  search(query: $query, type: ISSUE, first: 50, after: $cursor) {
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      ... on PullRequest {
+        number
+        title
+        url
+        createdAt
+        updatedAt
+        reviewDecision
+        author { login }
+        repository { name owner { login } }
+        reviews(last: 100, states: [COMMENTED, CHANGES_REQUESTED]) { nodes { submittedAt author { login } } }
+        reviewThreads(first: 100) { nodes { isResolved comments(last: 1) { nodes { createdAt author { login } } } } }
+      }
+    }
+  }
+}`
+
+// ReviewFeedback is an open pull request together with the feedback its author got from others: reviews with
+// comments or requested changes, and unresolved review threads waiting on the author's reply.
+type ReviewFeedback struct {
+	PullRequest       *OpenPullRequest
+	UnresolvedThreads int
+	// LastFeedbackAt is when the latest of that feedback was given.
+	LastFeedbackAt time.Time
+}
+
+type feedbackSearch struct {
+	Search struct {
+		PageInfo struct {
+			HasNextPage bool   `json:"hasNextPage"`
+			EndCursor   string `json:"endCursor"`
+		} `json:"pageInfo"`
+		Nodes []feedbackPullRequest `json:"nodes"`
+	} `json:"search"`
+}
+
+type feedbackPullRequest struct {
+	Number         int       `json:"number"`
+	Title          string    `json:"title"`
+	URL            string    `json:"url"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+	ReviewDecision string    `json:"reviewDecision"`
+	Author         actor     `json:"author"`
+	Repository     struct {
+		Name  string `json:"name"`
+		Owner actor  `json:"owner"`
+	} `json:"repository"`
+	Reviews struct {
+		Nodes []struct {
+			SubmittedAt time.Time `json:"submittedAt"`
+			Author      actor     `json:"author"`
+		} `json:"nodes"`
+	} `json:"reviews"`
+	ReviewThreads struct {
+		Nodes []struct {
+			IsResolved bool `json:"isResolved"`
+			Comments   struct {
+				Nodes []struct {
+					CreatedAt time.Time `json:"createdAt"`
+					Author    actor     `json:"author"`
+				} `json:"nodes"`
+			} `json:"comments"`
+		} `json:"nodes"`
+	} `json:"reviewThreads"`
+}
+
+type actor struct {
+	Login string `json:"login"`
+}
+
+// GraphQL runs query with variables as the installation in owner and decodes the data of the response into result.
+func (c *ExternalClient) GraphQL(ctx context.Context, owner, query string, variables map[string]any, result any) error {
+	client := c.forOwner(owner)
+	request, err := client.NewRequest(http.MethodPost, "graphql", map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	var response struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	_, err = client.Do(ctx, request, &response)
+	if err != nil {
+		return err
+	}
+	if len(response.Errors) > 0 {
+		messages := make([]string, 0, len(response.Errors))
+		for _, graphQLError := range response.Errors {
+			messages = append(messages, graphQLError.Message)
+		}
+		return errors.New(strings.Join(messages, "; "))
+	}
+	return json.Unmarshal(response.Data, result)
+}
+
+// SearchReviewFeedback returns the open, non-draft pull requests of authors that got feedback, in the organisation
+// and those the app is installed in. Unlike searching for each author, all of them are looked up in one query.
+func (ghc *Connector) SearchReviewFeedback(ctx context.Context, authors []string) ([]*ReviewFeedback, error) {
+	var feedback []*ReviewFeedback
+	for _, owner := range ghc.owners {
+		for _, chunkOfAuthors := range chunk(authors, authorsPerSearch) {
+			variables := map[string]any{"query": buildQuery(SearchQuery{}, owner, "", chunkOfAuthors)}
+			for {
+				var result feedbackSearch
+				callCtx, cancel := ghc.withTimeout(ctx)
+				err := ghc.client.GraphQL(callCtx, owner, feedbackQuery, variables, &result)
+				cancel()
+				if err != nil {
+					return nil, err
+				}
+				for _, pullRequest := range result.Search.Nodes {
+					if pullRequestFeedback := pullRequest.feedback(); pullRequestFeedback != nil {
+						feedback = append(feedback, pullRequestFeedback)
+					}
+				}
+				if !result.Search.PageInfo.HasNextPage {
+					break
+				}
+				variables["cursor"] = result.Search.PageInfo.EndCursor
+			}
+		}
+	}
+	return feedback, nil
+}
+
+// feedback returns the feedback others gave on the pull request, or nil if there is none.
+func (p feedbackPullRequest) feedback() *ReviewFeedback {
+	author := p.Author.Login
+	var given bool
+	var unresolvedThreads int
+	var last time.Time
+	for _, review := range p.Reviews.Nodes {
+		if review.Author.Login == author {
+			continue
+		}
+		given = true
+		if review.SubmittedAt.After(last) {
+			last = review.SubmittedAt
+		}
+	}
+	// Only threads where someone else had the last word are waiting on the author.
+	for _, thread := range p.ReviewThreads.Nodes {
+		if thread.IsResolved {
+			continue
+		}
+		for _, comment := range thread.Comments.Nodes {
+			if comment.Author.Login == author {
+				continue
+			}
+			unresolvedThreads++
+			given = true
+			if comment.CreatedAt.After(last) {
+				last = comment.CreatedAt
+			}
+		}
+	}
+	if !given {
+		return nil
+	}
+
+	review := ReviewPending
+	switch p.ReviewDecision {
+	case "APPROVED":
+		review = ReviewApproved
+	case "CHANGES_REQUESTED":
+		review = ReviewChangesRequested
+	}
+	return &ReviewFeedback{
+		PullRequest: &OpenPullRequest{
+			Number:    p.Number,
+			Title:     p.Title,
+			URL:       p.URL,
+			Owner:     p.Repository.Owner.Login,
+			Repo:      p.Repository.Name,
+			Author:    author,
+			CreatedAt: p.CreatedAt,
+			UpdatedAt: p.UpdatedAt,
+			Review:    review,
+		},
+		UnresolvedThreads: unresolvedThreads,
+		LastFeedbackAt:    last,
+	}
+}
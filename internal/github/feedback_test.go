@@ -0,0 +1,142 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package github_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/github"
+	mock_github "git-slack-bot/internal/github/mocks"
+	"time"
+
+	gh "github.com/google/go-github/v56/github"
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SearchReviewFeedback", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *mock_github.MockClient
+		connector  *github.Connector
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock_github.NewMockClient(mockCtrl)
+		cfg := config.GitHubConfiguration{
+			Token: "anyToken",
+			Team:  "TestTeam",
+			Org:   "TestOrg",
+		}
+
+		orgID := int64(123)
+		mockClient.EXPECT().GetOrg(gomock.Any(), gomock.Any()).Return(&gh.Organization{ID: &orgID}, nil)
+		teamID := int64(234)
+		teamName := "TestTeam"
+		mockClient.EXPECT().ListTeams(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*gh.Team{{ID: &teamID, Name: &teamName}}, nil)
+		conn, err := github.NewGitHubConnector(context.Background(), cfg, mockClient)
+		Expect(err).To(BeNil())
+		connector = conn
+	})
+
+	respond := func(data string) func(context.Context, string, string, map[string]any, any) error {
+		return func(_ context.Context, _, _ string, _ map[string]any, result any) error {
+			return json.Unmarshal([]byte(data), result)
+		}
+	}
+
+	It("should look up the feedback on the pull requests of all authors at once", func() {
+		mockClient.EXPECT().GraphQL(gomock.Any(), "TestOrg", gomock.Any(), map[string]any{
+			"query": "is:pr is:open draft:false archived:false org:TestOrg author:alice author:bob",
+		}, gomock.Any()).DoAndReturn(respond(`{"search": {"pageInfo": {"hasNextPage": false}, "nodes": [
+			{"number": 1, "title": "Commented", "url": "https://github.com/TestOrg/repo/pull/1",
+			 "createdAt": "2025-03-03T09:00:00Z", "reviewDecision": "REVIEW_REQUIRED",
+			 "author": {"login": "alice"}, "repository": {"name": "repo", "owner": {"login": "TestOrg"}},
+			 "reviews": {"nodes": [
+				{"submittedAt": "2025-03-03T10:00:00Z", "author": {"login": "bob"}},
+				{"submittedAt": "2025-03-03T12:00:00Z", "author": {"login": "alice"}}
+			 ]},
+			 "reviewThreads": {"nodes": []}},
+			{"number": 2, "title": "Open threads", "url": "https://github.com/TestOrg/repo/pull/2",
+			 "createdAt": "2025-03-03T09:00:00Z", "reviewDecision": "CHANGES_REQUESTED",
+			 "author": {"login": "bob"}, "repository": {"name": "repo", "owner": {"login": "TestOrg"}},
+			 "reviews": {"nodes": []},
+			 "reviewThreads": {"nodes": [
+				{"isResolved": false, "comments": {"nodes": [{"createdAt": "2025-03-03T11:00:00Z", "author": {"login": "alice"}}]}},
+				{"isResolved": false, "comments": {"nodes": [{"createdAt": "2025-03-03T13:00:00Z", "author": {"login": "bob"}}]}},
+				{"isResolved": true, "comments": {"nodes": [{"createdAt": "2025-03-03T14:00:00Z", "author": {"login": "alice"}}]}}
+			 ]}},
+			{"number": 3, "title": "Quiet", "url": "https://github.com/TestOrg/repo/pull/3",
+			 "createdAt": "2025-03-03T09:00:00Z", "author": {"login": "bob"},
+			 "repository": {"name": "repo", "owner": {"login": "TestOrg"}},
+			 "reviews": {"nodes": []}, "reviewThreads": {"nodes": []}}
+		]}}`))
+
+		actual, err := connector.SearchReviewFeedback(context.Background(), []string{"alice", "bob"})
+
+		Expect(err).To(BeNil())
+		Expect(actual).To(HaveLen(2))
+		Expect(actual[0].PullRequest).To(Equal(&github.OpenPullRequest{
+			Number:    1,
+			Title:     "Commented",
+			URL:       "https://github.com/TestOrg/repo/pull/1",
+			Owner:     "TestOrg",
+			Repo:      "repo",
+			Author:    "alice",
+			CreatedAt: time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC),
+			Review:    github.ReviewPending,
+		}))
+		Expect(actual[0].UnresolvedThreads).To(Equal(0))
+		Expect(actual[0].LastFeedbackAt).To(Equal(time.Date(2025, time.March, 3, 10, 0, 0, 0, time.UTC)))
+		Expect(actual[1].PullRequest.Review).To(Equal(github.ReviewChangesRequested))
+		Expect(actual[1].UnresolvedThreads).To(Equal(1))
+		Expect(actual[1].LastFeedbackAt).To(Equal(time.Date(2025, time.March, 3, 11, 0, 0, 0, time.UTC)))
+	})
+
+	It("should follow the cursor to the next page", func() {
+		gomock.InOrder(
+			mockClient.EXPECT().GraphQL(gomock.Any(), "TestOrg", gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(respond(`{"search": {"pageInfo": {"hasNextPage": true, "endCursor": "abc"}, "nodes": []}}`)),
+			mockClient.EXPECT().GraphQL(gomock.Any(), "TestOrg", gomock.Any(), map[string]any{
+				"query":  "is:pr is:open draft:false archived:false org:TestOrg author:alice",
+				"cursor": "abc",
+			}, gomock.Any()).DoAndReturn(respond(`{"search": {"pageInfo": {"hasNextPage": false}, "nodes": []}}`)),
+		)
+
+		actual, err := connector.SearchReviewFeedback(context.Background(), []string{"alice"})
+
+		Expect(err).To(BeNil())
+		Expect(actual).To(BeEmpty())
+	})
+
+	It("should return error if the query fails", func() {
+		mockClient.EXPECT().GraphQL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("rate limited"))
+
+		actual, err := connector.SearchReviewFeedback(context.Background(), []string{"alice"})
+
+		Expect(err).To(MatchError("rate limited"))
+		Expect(actual).To(BeNil())
+	})
+})
@@ -24,8 +24,15 @@ package github
 import (
 	"context"
 	"errors"
+	"fmt"
 	"git-slack-bot/internal/config"
 	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/go-github/v56/github"
 	"golang.org/x/oauth2"
@@ -33,12 +40,26 @@ import (
 
 type Client interface {
 	ListTeams(ctx context.Context, org string, options *github.ListOptions) ([]*github.Team, error)
-	ListTeamMembers(ctx context.Context, team, orgID int64, opt *github.TeamListTeamMembersOptions) ([]*github.User, error)
+	ListTeamMembers(ctx context.Context, org string, team, orgID int64, opt *github.TeamListTeamMembersOptions) ([]*github.User, error)
 	GetOrg(ctx context.Context, orgName string) (*github.Organization, error)
+	ListPullRequests(ctx context.Context, owner, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, error)
+	GetPullRequest(ctx context.Context, owner, repo string, number int) (*github.PullRequest, error)
+	SearchIssues(ctx context.Context, owner, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, error)
+	RequestReviewers(ctx context.Context, owner, repo string, number int, reviewers github.ReviewersRequest) (*github.PullRequest, error)
+	CreateIssueComment(ctx context.Context, owner, repo string, number int, comment *github.IssueComment) (*github.IssueComment, error)
+	ListIssueTimeline(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) ([]*github.Timeline, error)
+	ListOrganizationEvents(ctx context.Context, org string, page int, etag string) (*EventsPage, error)
+	GraphQL(ctx context.Context, owner, query string, variables map[string]any, result any) error
+	RateLimits(ctx context.Context) (*github.RateLimits, error)
 }
 
+// ExternalClient calls the GitHub API. Authenticated as an app, calls concerning a repository or organization are made
+// as the app's installation in its owner, so that one app can serve several organizations.
 type ExternalClient struct {
-	client *github.Client
+	client     *github.Client
+	orgClients map[string]*github.Client
+	// login is the user the token belongs to, looked up when first needed.
+	login atomic.Pointer[string]
 }
 
 func NewExternalClient(ctx context.Context, gitHubToken string) *ExternalClient {
@@ -52,35 +73,157 @@ func NewExternalClient(ctx context.Context, gitHubToken string) *ExternalClient
 	}
 }
 
+// NewAppClient creates an ExternalClient authenticated as the installations of app, the one in org by default and
+// those in the organizations of installations for their repositories. Installations without an id are looked up.
+func NewAppClient(ctx context.Context, app *App, org string, installationID int64, installations []config.GitHubAppInstallation) (*ExternalClient, error) {
+	client, err := app.installationClient(ctx, org, installationID)
+	if err != nil {
+		return nil, err
+	}
+	orgClients := map[string]*github.Client{strings.ToLower(org): client}
+	for _, installation := range installations {
+		orgClient, err := app.installationClient(ctx, installation.Org, installation.ID)
+		if err != nil {
+			return nil, err
+		}
+		orgClients[strings.ToLower(installation.Org)] = orgClient
+	}
+	return &ExternalClient{
+		client:     client,
+		orgClients: orgClients,
+	}, nil
+}
+
+// NewClient creates an ExternalClient authenticated as the app of cfg if it has one, and with the latest value of
+// token otherwise, so that a rotated token is used without a restart.
+func NewClient(ctx context.Context, cfg config.GitHubConfiguration, token func() string) (*ExternalClient, error) {
+	if cfg.App.ID == 0 {
+		return &ExternalClient{
+			client: github.NewClient(&http.Client{Transport: &oauth2.Transport{Source: tokenFunc(token)}}),
+		}, nil
+	}
+	privateKey := []byte(cfg.App.PrivateKey)
+	if cfg.App.PrivateKeyFile != "" {
+		var err error
+		privateKey, err = os.ReadFile(cfg.App.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	app, err := NewApp(cfg.App.ID, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return NewAppClient(lookupCtx, app, cfg.Org, cfg.App.InstallationID, cfg.App.Installations)
+}
+
+// tokenFunc is the token source of a token which may change. Unlike a static token source its tokens aren't cached.
+type tokenFunc func() string
+
+func (f tokenFunc) Token() (*oauth2.Token, error) {
+	return &oauth2.Token{AccessToken: f()}, nil
+}
+
+// forOwner returns the client of the installation in owner, falling back to the default one.
+func (c *ExternalClient) forOwner(owner string) *github.Client {
+	client, ok := c.orgClients[strings.ToLower(owner)]
+	if !ok {
+		return c.client
+	}
+	return client
+}
+
 func (c *ExternalClient) ListTeams(ctx context.Context, org string, options *github.ListOptions) ([]*github.Team, error) {
-	teams, _, err := c.client.Teams.ListTeams(ctx, org, options)
+	teams, _, err := c.forOwner(org).Teams.ListTeams(ctx, org, options)
 	return teams, err
 }
 
-func (c *ExternalClient) ListTeamMembers(ctx context.Context, team, orgID int64, opt *github.TeamListTeamMembersOptions) ([]*github.User, error) {
-	members, _, err := c.client.Teams.ListTeamMembersByID(ctx, orgID, team, opt)
+func (c *ExternalClient) ListTeamMembers(ctx context.Context, org string, team, orgID int64, opt *github.TeamListTeamMembersOptions) ([]*github.User, error) {
+	members, _, err := c.forOwner(org).Teams.ListTeamMembersByID(ctx, orgID, team, opt)
 	return members, err
 }
 
 func (c *ExternalClient) GetOrg(ctx context.Context, orgName string) (*github.Organization, error) {
-	org, _, err := c.client.Organizations.Get(ctx, orgName)
+	org, _, err := c.forOwner(orgName).Organizations.Get(ctx, orgName)
 	return org, err
 }
 
+func (c *ExternalClient) ListPullRequests(ctx context.Context, owner, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, error) {
+	pullRequests, _, err := c.forOwner(owner).PullRequests.List(ctx, owner, repo, opts)
+	return pullRequests, err
+}
+
+func (c *ExternalClient) GetPullRequest(ctx context.Context, owner, repo string, number int) (*github.PullRequest, error) {
+	pullRequest, _, err := c.forOwner(owner).PullRequests.Get(ctx, owner, repo, number)
+	return pullRequest, err
+}
+
+func (c *ExternalClient) RequestReviewers(ctx context.Context, owner, repo string, number int, reviewers github.ReviewersRequest) (*github.PullRequest, error) {
+	pullRequest, _, err := c.forOwner(owner).PullRequests.RequestReviewers(ctx, owner, repo, number, reviewers)
+	return pullRequest, err
+}
+
+func (c *ExternalClient) CreateIssueComment(ctx context.Context, owner, repo string, number int, comment *github.IssueComment) (*github.IssueComment, error) {
+	issueComment, _, err := c.forOwner(owner).Issues.CreateComment(ctx, owner, repo, number, comment)
+	return issueComment, err
+}
+
+func (c *ExternalClient) ListIssueTimeline(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) ([]*github.Timeline, error) {
+	timeline, _, err := c.forOwner(owner).Issues.ListIssueTimeline(ctx, owner, repo, number, opts)
+	return timeline, err
+}
+
+// SearchIssues searches as the installation in owner, which only finds the issues of the repositories it can access.
+func (c *ExternalClient) SearchIssues(ctx context.Context, owner, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, error) {
+	result, _, err := c.forOwner(owner).Search.Issues(ctx, query, opts)
+	return result, err
+}
+
+// defaultTimeout bounds each GitHub API call when the configuration sets no timeout.
+const defaultTimeout = 15 * time.Second
+
+func (c *ExternalClient) RateLimits(ctx context.Context) (*github.RateLimits, error) {
+	rateLimits, _, err := c.client.RateLimits(ctx)
+	return rateLimits, err
+}
+
 type Interactor interface {
-	GetTeamMembers() []string
+	GetTeamMembers(ctx context.Context) []string
+	ListOpenPullRequests(ctx context.Context, owner, repo, base, head string) ([]*github.PullRequest, error)
+	GetPullRequest(ctx context.Context, owner, repo string, number int) (*github.PullRequest, error)
+	SearchOpenPullRequests(ctx context.Context, query SearchQuery) ([]*OpenPullRequest, error)
+	SearchReviewFeedback(ctx context.Context, authors []string) ([]*ReviewFeedback, error)
+	RequestReviewer(ctx context.Context, owner, repo string, number int, githubLogin string) error
+	CreateIssueComment(ctx context.Context, owner, repo string, number int, body string) error
+	LastReviewActivity(ctx context.Context, owner, repo string, number int) (time.Time, error)
+	ListOrganizationEvents(ctx context.Context, page int, etag string) (*EventsPage, error)
 }
 
 type Connector struct {
-	ctx           context.Context
-	client        Client
-	repoOwner     string
+	client    Client
+	timeout   time.Duration
+	repoOwner string
+	// owners are the organizations searched for pull requests, repoOwner and those the app is installed in.
+	owners        []string
 	orgID         int64
 	teamID        int64
-	userBlackList []string
+	userBlackList atomic.Pointer[[]string]
 }
 
 func NewGitHubConnector(ctx context.Context, cfg config.GitHubConfiguration, client Client) (*Connector, error) {
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	org, err := client.GetOrg(ctx, cfg.Org)
 	if err != nil {
 		return nil, err
@@ -91,21 +234,39 @@ func NewGitHubConnector(ctx context.Context, cfg config.GitHubConfiguration, cli
 	}
 	for _, team := range teams {
 		if *team.Name == cfg.Team {
-			return &Connector{
-				ctx:           ctx,
-				client:        client,
-				repoOwner:     cfg.Org,
-				orgID:         *org.ID,
-				teamID:        *team.ID,
-				userBlackList: cfg.IgnoredPRUsers,
-			}, nil
+			owners := []string{cfg.Org}
+			for _, installation := range cfg.App.Installations {
+				owners = append(owners, installation.Org)
+			}
+			connector := &Connector{
+				client:    client,
+				timeout:   timeout,
+				repoOwner: cfg.Org,
+				owners:    owners,
+				orgID:     *org.ID,
+				teamID:    *team.ID,
+			}
+			connector.Reload(cfg.IgnoredPRUsers)
+			return connector, nil
 		}
 	}
 	return nil, errors.New("did not find team in organisation")
 }
 
-func (ghc *Connector) GetTeamMembers() []string {
-	usersFromAPI, err := ghc.client.ListTeamMembers(ghc.ctx, ghc.teamID, ghc.orgID, &github.TeamListTeamMembersOptions{
+// Reload replaces the team members left out of GetTeamMembers.
+func (ghc *Connector) Reload(ignoredPRUsers []string) {
+	ghc.userBlackList.Store(&ignoredPRUsers)
+}
+
+// withTimeout bounds a single API call, on top of any deadline ctx already has.
+func (ghc *Connector) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, ghc.timeout)
+}
+
+func (ghc *Connector) GetTeamMembers(ctx context.Context) []string {
+	ctx, cancel := ghc.withTimeout(ctx)
+	defer cancel()
+	usersFromAPI, err := ghc.client.ListTeamMembers(ctx, ghc.repoOwner, ghc.teamID, ghc.orgID, &github.TeamListTeamMembersOptions{
 		ListOptions: github.ListOptions{
 			PerPage: 999,
 		},
@@ -118,7 +279,7 @@ func (ghc *Connector) GetTeamMembers() []string {
 	var users []string
 	for _, user := range usersFromAPI {
 		blacklisted := false
-		for _, blacklist := range ghc.userBlackList {
+		for _, blacklist := range *ghc.userBlackList.Load() {
 			if *user.Login == blacklist {
 				blacklisted = true
 				break
@@ -131,3 +292,111 @@ func (ghc *Connector) GetTeamMembers() []string {
 
 	return users
 }
+
+// ListOpenPullRequests returns the open pull requests of the repository owner/repo. base and head are optional branch
+// filters, head being a branch of the same repository.
+func (ghc *Connector) ListOpenPullRequests(ctx context.Context, owner, repo, base, head string) ([]*github.PullRequest, error) {
+	opts := &github.PullRequestListOptions{
+		State: "open",
+		Base:  base,
+		ListOptions: github.ListOptions{
+			Page:    1,
+			PerPage: 100,
+		},
+	}
+	if head != "" {
+		opts.Head = fmt.Sprintf("%s:%s", owner, head)
+	}
+
+	var pullRequests []*github.PullRequest
+	for {
+		callCtx, cancel := ghc.withTimeout(ctx)
+		page, err := ghc.client.ListPullRequests(callCtx, owner, repo, opts)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		pullRequests = append(pullRequests, page...)
+		if len(page) < opts.PerPage {
+			return pullRequests, nil
+		}
+		opts.Page++
+	}
+}
+
+// GetPullRequest fetches a single pull request. Unlike the list endpoint this includes the mergeability fields,
+// which GitHub computes in the background and reports as unknown until it is done.
+func (ghc *Connector) GetPullRequest(ctx context.Context, owner, repo string, number int) (*github.PullRequest, error) {
+	ctx, cancel := ghc.withTimeout(ctx)
+	defer cancel()
+	return ghc.client.GetPullRequest(ctx, owner, repo, number)
+}
+
+// RequestReviewer adds githubLogin to the requested reviewers of a pull request.
+func (ghc *Connector) RequestReviewer(ctx context.Context, owner, repo string, number int, githubLogin string) error {
+	ctx, cancel := ghc.withTimeout(ctx)
+	defer cancel()
+	_, err := ghc.client.RequestReviewers(ctx, owner, repo, number, github.ReviewersRequest{Reviewers: []string{githubLogin}})
+	return err
+}
+
+// CreateIssueComment adds a comment to the conversation of a pull request.
+func (ghc *Connector) CreateIssueComment(ctx context.Context, owner, repo string, number int, body string) error {
+	ctx, cancel := ghc.withTimeout(ctx)
+	defer cancel()
+	_, err := ghc.client.CreateIssueComment(ctx, owner, repo, number, &github.IssueComment{Body: github.String(body)})
+	return err
+}
+
+// LastReviewActivity returns when a pull request was last marked ready for review, had a review requested or was
+// reviewed, and the zero time if none of that happened yet.
+func (ghc *Connector) LastReviewActivity(ctx context.Context, owner, repo string, number int) (time.Time, error) {
+	opts := &github.ListOptions{Page: 1, PerPage: 100}
+	var last time.Time
+	for {
+		callCtx, cancel := ghc.withTimeout(ctx)
+		page, err := ghc.client.ListIssueTimeline(callCtx, owner, repo, number, opts)
+		cancel()
+		if err != nil {
+			return time.Time{}, err
+		}
+		for _, event := range page {
+			var at time.Time
+			switch event.GetEvent() {
+			case "ready_for_review", "review_requested":
+				at = event.GetCreatedAt().Time
+			case "reviewed":
+				at = event.GetSubmittedAt().Time
+			}
+			if at.After(last) {
+				last = at
+			}
+		}
+		if len(page) < opts.PerPage {
+			return last, nil
+		}
+		opts.Page++
+	}
+}
+
+// CheckAuth verifies that the token is valid. The rate limit endpoint works for any kind of token and does not count
+// towards the rate limit.
+func (ghc *Connector) CheckAuth(ctx context.Context) error {
+	ctx, cancel := ghc.withTimeout(ctx)
+	defer cancel()
+	_, err := ghc.client.RateLimits(ctx)
+	return err
+}
+
+// ParsePullRequestURL returns the repository owner, repository name and number of a pull request from its html url.
+func ParsePullRequestURL(url string) (string, string, int, error) {
+	parts := strings.Split(strings.TrimSuffix(url, "/"), "/")
+	if len(parts) < 5 || parts[len(parts)-2] != "pull" {
+		return "", "", 0, fmt.Errorf("not a pull request url: %s", url)
+	}
+	number, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return "", "", 0, fmt.Errorf("not a pull request url: %s", url)
+	}
+	return parts[len(parts)-4], parts[len(parts)-3], number, nil
+}
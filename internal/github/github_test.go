@@ -26,6 +26,7 @@ import (
 	"git-slack-bot/internal/github"
 	mock_github "git-slack-bot/internal/github/mocks"
 	"testing"
+	"time"
 
 	gh "github.com/google/go-github/v56/github"
 	"go.uber.org/mock/gomock"
@@ -175,9 +176,9 @@ var _ = Describe("GetTeamMembers", func() {
 	})
 
 	It("should not return nil if failed to get team members", func() {
-		mockClient.EXPECT().ListTeamMembers(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("failed to get team members"))
+		mockClient.EXPECT().ListTeamMembers(gomock.Any(), "TestOrg", gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("failed to get team members"))
 
-		teamMembers := connector.GetTeamMembers()
+		teamMembers := connector.GetTeamMembers(context.Background())
 
 		Expect(teamMembers).To(BeNil())
 	})
@@ -193,12 +194,267 @@ var _ = Describe("GetTeamMembers", func() {
 				Login: &blackListedTeamMember,
 			},
 		}
-		mockClient.EXPECT().ListTeamMembers(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(teamMembersFromAPI, nil)
+		mockClient.EXPECT().ListTeamMembers(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(teamMembersFromAPI, nil)
 
-		teamMembers := connector.GetTeamMembers()
+		teamMembers := connector.GetTeamMembers(context.Background())
 
 		expected := []string{"NonBlackListed"}
 
 		Expect(teamMembers).To(Equal(expected))
 	})
+
+	It("should bound the call with a timeout", func() {
+		mockClient.EXPECT().ListTeamMembers(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ string, _, _ int64, _ *gh.TeamListTeamMembersOptions) ([]*gh.User, error) {
+			deadline, ok := ctx.Deadline()
+			Expect(ok).To(BeTrue())
+			Expect(time.Until(deadline)).To(BeNumerically("~", 15*time.Second, time.Second))
+			return nil, nil
+		})
+
+		connector.GetTeamMembers(context.Background())
+	})
+})
+
+var _ = Describe("CheckAuth", func() {
+	It("should fail with an invalid token", func() {
+		mockClient := mock_github.NewMockClient(gomock.NewController(GinkgoT()))
+		orgID := int64(123)
+		mockClient.EXPECT().GetOrg(gomock.Any(), gomock.Any()).Return(&gh.Organization{ID: &orgID}, nil)
+		mockClient.EXPECT().ListTeams(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*gh.Team{{ID: gh.Int64(234), Name: gh.String("TestTeam")}}, nil)
+		connector, err := github.NewGitHubConnector(context.Background(), config.GitHubConfiguration{Team: "TestTeam", Org: "TestOrg"}, mockClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		mockClient.EXPECT().RateLimits(gomock.Any()).Return(nil, errors.New("401 Bad credentials"))
+
+		Expect(connector.CheckAuth(context.Background())).To(MatchError("401 Bad credentials"))
+	})
+})
+
+var _ = Describe("ListOpenPullRequests", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *mock_github.MockClient
+		connector  *github.Connector
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock_github.NewMockClient(mockCtrl)
+		cfg := config.GitHubConfiguration{
+			Token: "anyToken",
+			Team:  "TestTeam",
+			Org:   "TestOrg",
+		}
+
+		orgID := int64(123)
+		mockClient.EXPECT().GetOrg(gomock.Any(), gomock.Any()).Return(&gh.Organization{ID: &orgID}, nil)
+		teamID := int64(234)
+		teamName := "TestTeam"
+		mockClient.EXPECT().ListTeams(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*gh.Team{{ID: &teamID, Name: &teamName}}, nil)
+		conn, err := github.NewGitHubConnector(context.Background(), cfg, mockClient)
+		Expect(err).To(BeNil())
+		connector = conn
+	})
+
+	It("should filter by the head branch within the organisation", func() {
+		mockClient.EXPECT().ListPullRequests(gomock.Any(), "TestOrg", "repo", gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, _ string, opts *gh.PullRequestListOptions) ([]*gh.PullRequest, error) {
+				Expect(opts.State).To(Equal("open"))
+				Expect(opts.Base).To(Equal(""))
+				Expect(opts.Head).To(Equal("TestOrg:feature"))
+				return []*gh.PullRequest{{Number: gh.Int(1)}}, nil
+			})
+
+		pullRequests, err := connector.ListOpenPullRequests(context.Background(), "TestOrg", "repo", "", "feature")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pullRequests).To(HaveLen(1))
+	})
+
+	It("should list the pull requests of a repository in another organisation", func() {
+		mockClient.EXPECT().ListPullRequests(gomock.Any(), "OtherOrg", "repo", gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, _ string, opts *gh.PullRequestListOptions) ([]*gh.PullRequest, error) {
+				Expect(opts.Head).To(Equal("OtherOrg:feature"))
+				return nil, nil
+			})
+
+		_, err := connector.ListOpenPullRequests(context.Background(), "OtherOrg", "repo", "", "feature")
+
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should fetch all pages", func() {
+		firstPage := make([]*gh.PullRequest, 100)
+		for i := range firstPage {
+			firstPage[i] = &gh.PullRequest{Number: gh.Int(i)}
+		}
+		gomock.InOrder(
+			mockClient.EXPECT().ListPullRequests(gomock.Any(), "TestOrg", "repo", gomock.Any()).DoAndReturn(
+				func(_ context.Context, _, _ string, opts *gh.PullRequestListOptions) ([]*gh.PullRequest, error) {
+					Expect(opts.Page).To(Equal(1))
+					Expect(opts.Base).To(Equal("main"))
+					return firstPage, nil
+				}),
+			mockClient.EXPECT().ListPullRequests(gomock.Any(), "TestOrg", "repo", gomock.Any()).DoAndReturn(
+				func(_ context.Context, _, _ string, opts *gh.PullRequestListOptions) ([]*gh.PullRequest, error) {
+					Expect(opts.Page).To(Equal(2))
+					return []*gh.PullRequest{{Number: gh.Int(100)}}, nil
+				}),
+		)
+
+		pullRequests, err := connector.ListOpenPullRequests(context.Background(), "TestOrg", "repo", "main", "")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pullRequests).To(HaveLen(101))
+	})
+
+	It("should return error if listing fails", func() {
+		mockClient.EXPECT().ListPullRequests(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("failed to list"))
+
+		pullRequests, err := connector.ListOpenPullRequests(context.Background(), "TestOrg", "repo", "main", "")
+
+		Expect(err).To(HaveOccurred())
+		Expect(pullRequests).To(BeNil())
+	})
+})
+
+var _ = Describe("RequestReviewer", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *mock_github.MockClient
+		connector  *github.Connector
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock_github.NewMockClient(mockCtrl)
+		cfg := config.GitHubConfiguration{
+			Token: "anyToken",
+			Team:  "TestTeam",
+			Org:   "TestOrg",
+		}
+
+		orgID := int64(123)
+		mockClient.EXPECT().GetOrg(gomock.Any(), gomock.Any()).Return(&gh.Organization{ID: &orgID}, nil)
+		teamID := int64(234)
+		teamName := "TestTeam"
+		mockClient.EXPECT().ListTeams(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*gh.Team{{ID: &teamID, Name: &teamName}}, nil)
+		conn, err := github.NewGitHubConnector(context.Background(), cfg, mockClient)
+		Expect(err).To(BeNil())
+		connector = conn
+	})
+
+	It("should request a review from the user", func() {
+		mockClient.EXPECT().RequestReviewers(gomock.Any(), "TestOrg", "repo", 7, gh.ReviewersRequest{Reviewers: []string{"bob"}}).Return(&gh.PullRequest{}, nil)
+
+		Expect(connector.RequestReviewer(context.Background(), "TestOrg", "repo", 7, "bob")).To(Succeed())
+	})
+
+	It("should return error if requesting the review fails", func() {
+		mockClient.EXPECT().RequestReviewers(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("author can't review"))
+
+		Expect(connector.RequestReviewer(context.Background(), "TestOrg", "repo", 7, "alice")).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("CreateIssueComment", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *mock_github.MockClient
+		connector  *github.Connector
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock_github.NewMockClient(mockCtrl)
+		cfg := config.GitHubConfiguration{
+			Token: "anyToken",
+			Team:  "TestTeam",
+			Org:   "TestOrg",
+		}
+
+		orgID := int64(123)
+		mockClient.EXPECT().GetOrg(gomock.Any(), gomock.Any()).Return(&gh.Organization{ID: &orgID}, nil)
+		teamID := int64(234)
+		teamName := "TestTeam"
+		mockClient.EXPECT().ListTeams(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*gh.Team{{ID: &teamID, Name: &teamName}}, nil)
+		conn, err := github.NewGitHubConnector(context.Background(), cfg, mockClient)
+		Expect(err).To(BeNil())
+		connector = conn
+	})
+
+	It("should comment on the pull request", func() {
+		mockClient.EXPECT().CreateIssueComment(gomock.Any(), "TestOrg", "repo", 7, &gh.IssueComment{Body: gh.String("LGTM")}).Return(&gh.IssueComment{}, nil)
+
+		Expect(connector.CreateIssueComment(context.Background(), "TestOrg", "repo", 7, "LGTM")).To(Succeed())
+	})
+
+	It("should comment as the owner of the repository", func() {
+		mockClient.EXPECT().CreateIssueComment(gomock.Any(), "OtherOrg", "repo", 7, gomock.Any()).Return(&gh.IssueComment{}, nil)
+
+		Expect(connector.CreateIssueComment(context.Background(), "OtherOrg", "repo", 7, "LGTM")).To(Succeed())
+	})
+})
+
+var _ = Describe("LastReviewActivity", func() {
+	var (
+		mockClient *mock_github.MockClient
+		connector  *github.Connector
+	)
+
+	BeforeEach(func() {
+		mockClient = mock_github.NewMockClient(gomock.NewController(GinkgoT()))
+		mockClient.EXPECT().GetOrg(gomock.Any(), gomock.Any()).Return(&gh.Organization{ID: gh.Int64(123)}, nil)
+		mockClient.EXPECT().ListTeams(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*gh.Team{{ID: gh.Int64(234), Name: gh.String("TestTeam")}}, nil)
+		var err error
+		connector, err = github.NewGitHubConnector(context.Background(), config.GitHubConfiguration{Team: "TestTeam", Org: "TestOrg"}, mockClient)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should return the latest time the pull request was made ready, had a review requested or was reviewed", func() {
+		at := func(hour int) *gh.Timestamp {
+			return &gh.Timestamp{Time: time.Date(2025, time.March, 3, hour, 0, 0, 0, time.UTC)}
+		}
+		mockClient.EXPECT().ListIssueTimeline(gomock.Any(), "TestOrg", "repo", 7, gomock.Any()).Return([]*gh.Timeline{
+			{Event: gh.String("ready_for_review"), CreatedAt: at(9)},
+			{Event: gh.String("reviewed"), SubmittedAt: at(11)},
+			{Event: gh.String("review_requested"), CreatedAt: at(10)},
+			{Event: gh.String("commented"), CreatedAt: at(12)},
+		}, nil)
+
+		lastActivity, err := connector.LastReviewActivity(context.Background(), "TestOrg", "repo", 7)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lastActivity).To(Equal(at(11).Time))
+	})
+
+	It("should return the zero time without review activity", func() {
+		mockClient.EXPECT().ListIssueTimeline(gomock.Any(), "TestOrg", "repo", 7, gomock.Any()).Return(nil, nil)
+
+		lastActivity, err := connector.LastReviewActivity(context.Background(), "TestOrg", "repo", 7)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lastActivity).To(BeZero())
+	})
+})
+
+var _ = Describe("ParsePullRequestURL", func() {
+	It("should return the owner, repo and number", func() {
+		owner, repo, number, err := github.ParsePullRequestURL("https://github.com/TestOrg/repo/pull/7")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(owner).To(Equal("TestOrg"))
+		Expect(repo).To(Equal("repo"))
+		Expect(number).To(Equal(7))
+	})
+
+	DescribeTable("should return error for other urls", func(url string) {
+		_, _, _, err := github.ParsePullRequestURL(url)
+
+		Expect(err).To(HaveOccurred())
+	},
+		Entry("issue", "https://github.com/TestOrg/repo/issues/7"),
+		Entry("no number", "https://github.com/TestOrg/repo/pull/abc"),
+		Entry("empty", ""),
+	)
 })
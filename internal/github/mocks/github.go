@@ -11,9 +11,11 @@ package mock_github
 
 import (
 	context "context"
+	github "git-slack-bot/internal/github"
 	reflect "reflect"
+	time "time"
 
-	github "github.com/google/go-github/v56/github"
+	github0 "github.com/google/go-github/v56/github"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -21,6 +23,7 @@ import (
 type MockClient struct {
 	ctrl     *gomock.Controller
 	recorder *MockClientMockRecorder
+	isgomock struct{}
 }
 
 // MockClientMockRecorder is the mock recorder for MockClient.
@@ -40,55 +43,190 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// CreateIssueComment mocks base method.
+func (m *MockClient) CreateIssueComment(ctx context.Context, owner, repo string, number int, comment *github0.IssueComment) (*github0.IssueComment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIssueComment", ctx, owner, repo, number, comment)
+	ret0, _ := ret[0].(*github0.IssueComment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIssueComment indicates an expected call of CreateIssueComment.
+func (mr *MockClientMockRecorder) CreateIssueComment(ctx, owner, repo, number, comment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIssueComment", reflect.TypeOf((*MockClient)(nil).CreateIssueComment), ctx, owner, repo, number, comment)
+}
+
 // GetOrg mocks base method.
-func (m *MockClient) GetOrg(arg0 context.Context, arg1 string) (*github.Organization, error) {
+func (m *MockClient) GetOrg(ctx context.Context, orgName string) (*github0.Organization, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrg", arg0, arg1)
-	ret0, _ := ret[0].(*github.Organization)
+	ret := m.ctrl.Call(m, "GetOrg", ctx, orgName)
+	ret0, _ := ret[0].(*github0.Organization)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOrg indicates an expected call of GetOrg.
-func (mr *MockClientMockRecorder) GetOrg(arg0, arg1 any) *gomock.Call {
+func (mr *MockClientMockRecorder) GetOrg(ctx, orgName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrg", reflect.TypeOf((*MockClient)(nil).GetOrg), ctx, orgName)
+}
+
+// GetPullRequest mocks base method.
+func (m *MockClient) GetPullRequest(ctx context.Context, owner, repo string, number int) (*github0.PullRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPullRequest", ctx, owner, repo, number)
+	ret0, _ := ret[0].(*github0.PullRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPullRequest indicates an expected call of GetPullRequest.
+func (mr *MockClientMockRecorder) GetPullRequest(ctx, owner, repo, number any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPullRequest", reflect.TypeOf((*MockClient)(nil).GetPullRequest), ctx, owner, repo, number)
+}
+
+// GraphQL mocks base method.
+func (m *MockClient) GraphQL(ctx context.Context, owner, query string, variables map[string]any, result any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GraphQL", ctx, owner, query, variables, result)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GraphQL indicates an expected call of GraphQL.
+func (mr *MockClientMockRecorder) GraphQL(ctx, owner, query, variables, result any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrg", reflect.TypeOf((*MockClient)(nil).GetOrg), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GraphQL", reflect.TypeOf((*MockClient)(nil).GraphQL), ctx, owner, query, variables, result)
+}
+
+// ListIssueTimeline mocks base method.
+func (m *MockClient) ListIssueTimeline(ctx context.Context, owner, repo string, number int, opts *github0.ListOptions) ([]*github0.Timeline, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListIssueTimeline", ctx, owner, repo, number, opts)
+	ret0, _ := ret[0].([]*github0.Timeline)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListIssueTimeline indicates an expected call of ListIssueTimeline.
+func (mr *MockClientMockRecorder) ListIssueTimeline(ctx, owner, repo, number, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIssueTimeline", reflect.TypeOf((*MockClient)(nil).ListIssueTimeline), ctx, owner, repo, number, opts)
+}
+
+// ListOrganizationEvents mocks base method.
+func (m *MockClient) ListOrganizationEvents(ctx context.Context, org string, page int, etag string) (*github.EventsPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrganizationEvents", ctx, org, page, etag)
+	ret0, _ := ret[0].(*github.EventsPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrganizationEvents indicates an expected call of ListOrganizationEvents.
+func (mr *MockClientMockRecorder) ListOrganizationEvents(ctx, org, page, etag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrganizationEvents", reflect.TypeOf((*MockClient)(nil).ListOrganizationEvents), ctx, org, page, etag)
+}
+
+// ListPullRequests mocks base method.
+func (m *MockClient) ListPullRequests(ctx context.Context, owner, repo string, opts *github0.PullRequestListOptions) ([]*github0.PullRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPullRequests", ctx, owner, repo, opts)
+	ret0, _ := ret[0].([]*github0.PullRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPullRequests indicates an expected call of ListPullRequests.
+func (mr *MockClientMockRecorder) ListPullRequests(ctx, owner, repo, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPullRequests", reflect.TypeOf((*MockClient)(nil).ListPullRequests), ctx, owner, repo, opts)
 }
 
 // ListTeamMembers mocks base method.
-func (m *MockClient) ListTeamMembers(arg0 context.Context, arg1, arg2 int64, arg3 *github.TeamListTeamMembersOptions) ([]*github.User, error) {
+func (m *MockClient) ListTeamMembers(ctx context.Context, org string, team, orgID int64, opt *github0.TeamListTeamMembersOptions) ([]*github0.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListTeamMembers", arg0, arg1, arg2, arg3)
-	ret0, _ := ret[0].([]*github.User)
+	ret := m.ctrl.Call(m, "ListTeamMembers", ctx, org, team, orgID, opt)
+	ret0, _ := ret[0].([]*github0.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListTeamMembers indicates an expected call of ListTeamMembers.
-func (mr *MockClientMockRecorder) ListTeamMembers(arg0, arg1, arg2, arg3 any) *gomock.Call {
+func (mr *MockClientMockRecorder) ListTeamMembers(ctx, org, team, orgID, opt any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeamMembers", reflect.TypeOf((*MockClient)(nil).ListTeamMembers), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeamMembers", reflect.TypeOf((*MockClient)(nil).ListTeamMembers), ctx, org, team, orgID, opt)
 }
 
 // ListTeams mocks base method.
-func (m *MockClient) ListTeams(arg0 context.Context, arg1 string, arg2 *github.ListOptions) ([]*github.Team, error) {
+func (m *MockClient) ListTeams(ctx context.Context, org string, options *github0.ListOptions) ([]*github0.Team, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListTeams", arg0, arg1, arg2)
-	ret0, _ := ret[0].([]*github.Team)
+	ret := m.ctrl.Call(m, "ListTeams", ctx, org, options)
+	ret0, _ := ret[0].([]*github0.Team)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListTeams indicates an expected call of ListTeams.
-func (mr *MockClientMockRecorder) ListTeams(arg0, arg1, arg2 any) *gomock.Call {
+func (mr *MockClientMockRecorder) ListTeams(ctx, org, options any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeams", reflect.TypeOf((*MockClient)(nil).ListTeams), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeams", reflect.TypeOf((*MockClient)(nil).ListTeams), ctx, org, options)
+}
+
+// RateLimits mocks base method.
+func (m *MockClient) RateLimits(ctx context.Context) (*github0.RateLimits, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RateLimits", ctx)
+	ret0, _ := ret[0].(*github0.RateLimits)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RateLimits indicates an expected call of RateLimits.
+func (mr *MockClientMockRecorder) RateLimits(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RateLimits", reflect.TypeOf((*MockClient)(nil).RateLimits), ctx)
+}
+
+// RequestReviewers mocks base method.
+func (m *MockClient) RequestReviewers(ctx context.Context, owner, repo string, number int, reviewers github0.ReviewersRequest) (*github0.PullRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestReviewers", ctx, owner, repo, number, reviewers)
+	ret0, _ := ret[0].(*github0.PullRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestReviewers indicates an expected call of RequestReviewers.
+func (mr *MockClientMockRecorder) RequestReviewers(ctx, owner, repo, number, reviewers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestReviewers", reflect.TypeOf((*MockClient)(nil).RequestReviewers), ctx, owner, repo, number, reviewers)
+}
+
+// SearchIssues mocks base method.
+func (m *MockClient) SearchIssues(ctx context.Context, owner, query string, opts *github0.SearchOptions) (*github0.IssuesSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchIssues", ctx, owner, query, opts)
+	ret0, _ := ret[0].(*github0.IssuesSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchIssues indicates an expected call of SearchIssues.
+func (mr *MockClientMockRecorder) SearchIssues(ctx, owner, query, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchIssues", reflect.TypeOf((*MockClient)(nil).SearchIssues), ctx, owner, query, opts)
 }
 
 // MockInteractor is a mock of Interactor interface.
 type MockInteractor struct {
 	ctrl     *gomock.Controller
 	recorder *MockInteractorMockRecorder
+	isgomock struct{}
 }
 
 // MockInteractorMockRecorder is the mock recorder for MockInteractor.
@@ -108,16 +246,134 @@ func (m *MockInteractor) EXPECT() *MockInteractorMockRecorder {
 	return m.recorder
 }
 
+// CreateIssueComment mocks base method.
+func (m *MockInteractor) CreateIssueComment(ctx context.Context, owner, repo string, number int, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIssueComment", ctx, owner, repo, number, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateIssueComment indicates an expected call of CreateIssueComment.
+func (mr *MockInteractorMockRecorder) CreateIssueComment(ctx, owner, repo, number, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIssueComment", reflect.TypeOf((*MockInteractor)(nil).CreateIssueComment), ctx, owner, repo, number, body)
+}
+
+// GetPullRequest mocks base method.
+func (m *MockInteractor) GetPullRequest(ctx context.Context, owner, repo string, number int) (*github0.PullRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPullRequest", ctx, owner, repo, number)
+	ret0, _ := ret[0].(*github0.PullRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPullRequest indicates an expected call of GetPullRequest.
+func (mr *MockInteractorMockRecorder) GetPullRequest(ctx, owner, repo, number any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPullRequest", reflect.TypeOf((*MockInteractor)(nil).GetPullRequest), ctx, owner, repo, number)
+}
+
 // GetTeamMembers mocks base method.
-func (m *MockInteractor) GetTeamMembers() []string {
+func (m *MockInteractor) GetTeamMembers(ctx context.Context) []string {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTeamMembers")
+	ret := m.ctrl.Call(m, "GetTeamMembers", ctx)
 	ret0, _ := ret[0].([]string)
 	return ret0
 }
 
 // GetTeamMembers indicates an expected call of GetTeamMembers.
-func (mr *MockInteractorMockRecorder) GetTeamMembers() *gomock.Call {
+func (mr *MockInteractorMockRecorder) GetTeamMembers(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamMembers", reflect.TypeOf((*MockInteractor)(nil).GetTeamMembers), ctx)
+}
+
+// LastReviewActivity mocks base method.
+func (m *MockInteractor) LastReviewActivity(ctx context.Context, owner, repo string, number int) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastReviewActivity", ctx, owner, repo, number)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastReviewActivity indicates an expected call of LastReviewActivity.
+func (mr *MockInteractorMockRecorder) LastReviewActivity(ctx, owner, repo, number any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastReviewActivity", reflect.TypeOf((*MockInteractor)(nil).LastReviewActivity), ctx, owner, repo, number)
+}
+
+// ListOpenPullRequests mocks base method.
+func (m *MockInteractor) ListOpenPullRequests(ctx context.Context, owner, repo, base, head string) ([]*github0.PullRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOpenPullRequests", ctx, owner, repo, base, head)
+	ret0, _ := ret[0].([]*github0.PullRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOpenPullRequests indicates an expected call of ListOpenPullRequests.
+func (mr *MockInteractorMockRecorder) ListOpenPullRequests(ctx, owner, repo, base, head any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOpenPullRequests", reflect.TypeOf((*MockInteractor)(nil).ListOpenPullRequests), ctx, owner, repo, base, head)
+}
+
+// ListOrganizationEvents mocks base method.
+func (m *MockInteractor) ListOrganizationEvents(ctx context.Context, page int, etag string) (*github.EventsPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrganizationEvents", ctx, page, etag)
+	ret0, _ := ret[0].(*github.EventsPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrganizationEvents indicates an expected call of ListOrganizationEvents.
+func (mr *MockInteractorMockRecorder) ListOrganizationEvents(ctx, page, etag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrganizationEvents", reflect.TypeOf((*MockInteractor)(nil).ListOrganizationEvents), ctx, page, etag)
+}
+
+// RequestReviewer mocks base method.
+func (m *MockInteractor) RequestReviewer(ctx context.Context, owner, repo string, number int, githubLogin string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestReviewer", ctx, owner, repo, number, githubLogin)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestReviewer indicates an expected call of RequestReviewer.
+func (mr *MockInteractorMockRecorder) RequestReviewer(ctx, owner, repo, number, githubLogin any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestReviewer", reflect.TypeOf((*MockInteractor)(nil).RequestReviewer), ctx, owner, repo, number, githubLogin)
+}
+
+// SearchOpenPullRequests mocks base method.
+func (m *MockInteractor) SearchOpenPullRequests(ctx context.Context, query github.SearchQuery) ([]*github.OpenPullRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchOpenPullRequests", ctx, query)
+	ret0, _ := ret[0].([]*github.OpenPullRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchOpenPullRequests indicates an expected call of SearchOpenPullRequests.
+func (mr *MockInteractorMockRecorder) SearchOpenPullRequests(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchOpenPullRequests", reflect.TypeOf((*MockInteractor)(nil).SearchOpenPullRequests), ctx, query)
+}
+
+// SearchReviewFeedback mocks base method.
+func (m *MockInteractor) SearchReviewFeedback(ctx context.Context, authors []string) ([]*github.ReviewFeedback, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchReviewFeedback", ctx, authors)
+	ret0, _ := ret[0].([]*github.ReviewFeedback)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchReviewFeedback indicates an expected call of SearchReviewFeedback.
+func (mr *MockInteractorMockRecorder) SearchReviewFeedback(ctx, authors any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamMembers", reflect.TypeOf((*MockInteractor)(nil).GetTeamMembers))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchReviewFeedback", reflect.TypeOf((*MockInteractor)(nil).SearchReviewFeedback), ctx, authors)
 }
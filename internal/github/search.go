@@ -0,0 +1,194 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+)
+
+type ReviewState string
+
+const (
+	ReviewPending          ReviewState = "pending"
+	ReviewApproved         ReviewState = "approved"
+	ReviewChangesRequested ReviewState = "changes_requested"
+)
+
+// authorsPerSearch keeps the author qualifiers of a single search query well within GitHub's query limits.
+const authorsPerSearch = 20
+
+// OpenPullRequest is an open, non-draft pull request as returned by the search API.
+type OpenPullRequest struct {
+	Number    int
+	Title     string
+	URL       string
+	Owner     string
+	Repo      string
+	Author    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Review    ReviewState
+}
+
+// IsStale reports whether the pull request has not been updated for staleAfter or longer at now. The digest and
+// `/prs stale` both use it, so that they agree on pull requests right at the threshold.
+func (p *OpenPullRequest) IsStale(now time.Time, staleAfter time.Duration) bool {
+	return now.Sub(p.UpdatedAt) >= staleAfter
+}
+
+// SearchQuery narrows down SearchOpenPullRequests. Empty fields are not filtered on. Repo is the name of a repository
+// in the organisation, or the full name of one in another organisation. IgnoreReview saves looking up the review
+// decisions, leaving every pull request pending.
+type SearchQuery struct {
+	Authors         []string
+	ReviewRequested string
+	Repo            string
+	Unreviewed      bool
+	IgnoreReview    bool
+}
+
+// SearchOpenPullRequests returns the open, non-draft pull requests matching query in the organisation and those the
+// app is installed in, with their review state set from the review decision GitHub reports for them.
+func (ghc *Connector) SearchOpenPullRequests(ctx context.Context, query SearchQuery) ([]*OpenPullRequest, error) {
+	owners, repo := ghc.owners, ""
+	if query.Repo != "" {
+		owner, name, found := strings.Cut(query.Repo, "/")
+		if !found {
+			owner, name = ghc.repoOwner, query.Repo
+		}
+		owners, repo = []string{owner}, owner+"/"+name
+	}
+
+	var pullRequests []*OpenPullRequest
+	for _, owner := range owners {
+		for _, authors := range chunk(query.Authors, authorsPerSearch) {
+			baseQuery := buildQuery(query, owner, repo, authors)
+			found, err := ghc.searchPullRequests(ctx, owner, baseQuery)
+			if err != nil {
+				return nil, err
+			}
+			// Pull requests without reviews can't have a review decision, so there is nothing to look up.
+			for _, review := range reviewDecisions(query) {
+				reviewed, err := ghc.searchPullRequests(ctx, owner, fmt.Sprintf("%s review:%s", baseQuery, review))
+				if err != nil {
+					return nil, err
+				}
+				for _, pullRequest := range found {
+					if _, ok := reviewed[pullRequest.URL]; ok {
+						pullRequest.Review = review
+					}
+				}
+			}
+			pullRequests = slices.AppendSeq(pullRequests, maps.Values(found))
+		}
+	}
+	slices.SortFunc(pullRequests, func(a, b *OpenPullRequest) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return pullRequests, nil
+}
+
+func reviewDecisions(query SearchQuery) []ReviewState {
+	if query.Unreviewed || query.IgnoreReview {
+		return nil
+	}
+	return []ReviewState{ReviewApproved, ReviewChangesRequested}
+}
+
+func buildQuery(query SearchQuery, owner, repo string, authors []string) string {
+	qualifiers := []string{"is:pr", "is:open", "draft:false", "archived:false", "org:" + owner}
+	if repo != "" {
+		qualifiers = append(qualifiers, "repo:"+repo)
+	}
+	if query.ReviewRequested != "" {
+		qualifiers = append(qualifiers, "review-requested:"+query.ReviewRequested)
+	}
+	if query.Unreviewed {
+		qualifiers = append(qualifiers, "review:none")
+	}
+	for _, author := range authors {
+		qualifiers = append(qualifiers, "author:"+author)
+	}
+	return strings.Join(qualifiers, " ")
+}
+
+func (ghc *Connector) searchPullRequests(ctx context.Context, owner, query string) (map[string]*OpenPullRequest, error) {
+	opts := &github.SearchOptions{
+		ListOptions: github.ListOptions{
+			Page:    1,
+			PerPage: 100,
+		},
+	}
+
+	pullRequests := make(map[string]*OpenPullRequest)
+	for {
+		callCtx, cancel := ghc.withTimeout(ctx)
+		result, err := ghc.client.SearchIssues(callCtx, owner, query, opts)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range result.Issues {
+			owner, repo := repoName(issue.GetRepositoryURL())
+			pullRequests[issue.GetHTMLURL()] = &OpenPullRequest{
+				Number:    issue.GetNumber(),
+				Title:     issue.GetTitle(),
+				URL:       issue.GetHTMLURL(),
+				Owner:     owner,
+				Repo:      repo,
+				Author:    issue.GetUser().GetLogin(),
+				CreatedAt: issue.GetCreatedAt().Time,
+				UpdatedAt: issue.GetUpdatedAt().Time,
+				Review:    ReviewPending,
+			}
+		}
+		if len(result.Issues) < opts.PerPage {
+			return pullRequests, nil
+		}
+		opts.Page++
+	}
+}
+
+// repoName returns the owner and name of a repository from its api url, which ends in /repos/{owner}/{repo}.
+func repoName(repositoryURL string) (string, string) {
+	parts := strings.Split(repositoryURL, "/")
+	if len(parts) < 2 {
+		return "", repositoryURL
+	}
+	return parts[len(parts)-2], parts[len(parts)-1]
+}
+
+// chunk splits values into slices of at most size elements. An empty slice results in a single empty chunk, so that
+// queries without authors still run once.
+func chunk(values []string, size int) [][]string {
+	var chunks [][]string
+	for size < len(values) {
+		chunks = append(chunks, values[:size])
+		values = values[size:]
+	}
+	return append(chunks, values)
+}
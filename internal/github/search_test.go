@@ -0,0 +1,197 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package github_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/github"
+	mock_github "git-slack-bot/internal/github/mocks"
+	"time"
+
+	gh "github.com/google/go-github/v56/github"
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IsStale", func() {
+	now := time.Date(2025, time.March, 5, 9, 0, 0, 0, time.UTC)
+
+	DescribeTable("should be stale from the threshold on", func(updatedAgo time.Duration, stale bool) {
+		pullRequest := &github.OpenPullRequest{UpdatedAt: now.Add(-updatedAgo)}
+
+		Expect(pullRequest.IsStale(now, 48*time.Hour)).To(Equal(stale))
+	},
+		Entry("before the threshold", 48*time.Hour-time.Second, false),
+		Entry("at the threshold", 48*time.Hour, true),
+		Entry("after the threshold", 72*time.Hour, true),
+	)
+})
+
+var _ = Describe("SearchOpenPullRequests", func() {
+	const baseQuery = "is:pr is:open draft:false archived:false org:TestOrg"
+
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *mock_github.MockClient
+		connector  *github.Connector
+		created    time.Time
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock_github.NewMockClient(mockCtrl)
+		cfg := config.GitHubConfiguration{
+			Token: "anyToken",
+			Team:  "TestTeam",
+			Org:   "TestOrg",
+		}
+
+		orgID := int64(123)
+		mockClient.EXPECT().GetOrg(gomock.Any(), gomock.Any()).Return(&gh.Organization{ID: &orgID}, nil)
+		teamID := int64(234)
+		teamName := "TestTeam"
+		mockClient.EXPECT().ListTeams(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*gh.Team{{ID: &teamID, Name: &teamName}}, nil)
+		conn, err := github.NewGitHubConnector(context.Background(), cfg, mockClient)
+		Expect(err).To(BeNil())
+		connector = conn
+		created = time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC)
+	})
+
+	issue := func(number int, createdAt time.Time) *gh.Issue {
+		return &gh.Issue{
+			Number:        gh.Int(number),
+			Title:         gh.String(fmt.Sprintf("PR %d", number)),
+			HTMLURL:       gh.String(fmt.Sprintf("https://github.com/TestOrg/repo/pull/%d", number)),
+			RepositoryURL: gh.String("https://api.github.com/repos/TestOrg/repo"),
+			User:          &gh.User{Login: gh.String("alice")},
+			CreatedAt:     &gh.Timestamp{Time: createdAt},
+			UpdatedAt:     &gh.Timestamp{Time: createdAt},
+		}
+	}
+
+	It("should set the review state of each pull request", func() {
+		query := baseQuery + " author:alice author:bob"
+		mockClient.EXPECT().SearchIssues(gomock.Any(), "TestOrg", query, gomock.Any()).Return(&gh.IssuesSearchResult{
+			Issues: []*gh.Issue{issue(1, created.Add(time.Hour)), issue(2, created), issue(3, created.Add(2*time.Hour))},
+		}, nil)
+		mockClient.EXPECT().SearchIssues(gomock.Any(), "TestOrg", query+" review:approved", gomock.Any()).Return(&gh.IssuesSearchResult{
+			Issues: []*gh.Issue{issue(1, created.Add(time.Hour))},
+		}, nil)
+		mockClient.EXPECT().SearchIssues(gomock.Any(), "TestOrg", query+" review:changes_requested", gomock.Any()).Return(&gh.IssuesSearchResult{
+			Issues: []*gh.Issue{issue(3, created.Add(2*time.Hour))},
+		}, nil)
+
+		pullRequests, err := connector.SearchOpenPullRequests(context.Background(), github.SearchQuery{Authors: []string{"alice", "bob"}})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pullRequests).To(Equal([]*github.OpenPullRequest{
+			{Number: 2, Title: "PR 2", URL: "https://github.com/TestOrg/repo/pull/2", Owner: "TestOrg", Repo: "repo", Author: "alice", CreatedAt: created, UpdatedAt: created, Review: github.ReviewPending},
+			{Number: 1, Title: "PR 1", URL: "https://github.com/TestOrg/repo/pull/1", Owner: "TestOrg", Repo: "repo", Author: "alice", CreatedAt: created.Add(time.Hour), UpdatedAt: created.Add(time.Hour), Review: github.ReviewApproved},
+			{Number: 3, Title: "PR 3", URL: "https://github.com/TestOrg/repo/pull/3", Owner: "TestOrg", Repo: "repo", Author: "alice", CreatedAt: created.Add(2 * time.Hour), UpdatedAt: created.Add(2 * time.Hour), Review: github.ReviewChangesRequested},
+		}))
+	})
+
+	It("should filter on repo and requested reviewer", func() {
+		query := baseQuery + " repo:TestOrg/repo review-requested:bob"
+		mockClient.EXPECT().SearchIssues(gomock.Any(), "TestOrg", query, gomock.Any()).Return(&gh.IssuesSearchResult{}, nil)
+		mockClient.EXPECT().SearchIssues(gomock.Any(), "TestOrg", query+" review:approved", gomock.Any()).Return(&gh.IssuesSearchResult{}, nil)
+		mockClient.EXPECT().SearchIssues(gomock.Any(), "TestOrg", query+" review:changes_requested", gomock.Any()).Return(&gh.IssuesSearchResult{}, nil)
+
+		pullRequests, err := connector.SearchOpenPullRequests(context.Background(), github.SearchQuery{Repo: "repo", ReviewRequested: "bob"})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pullRequests).To(BeEmpty())
+	})
+
+	It("should filter on a repository in another organisation", func() {
+		query := "is:pr is:open draft:false archived:false org:OtherOrg repo:OtherOrg/repo"
+		mockClient.EXPECT().SearchIssues(gomock.Any(), "OtherOrg", query, gomock.Any()).Return(&gh.IssuesSearchResult{}, nil)
+		mockClient.EXPECT().SearchIssues(gomock.Any(), "OtherOrg", query+" review:approved", gomock.Any()).Return(&gh.IssuesSearchResult{}, nil)
+		mockClient.EXPECT().SearchIssues(gomock.Any(), "OtherOrg", query+" review:changes_requested", gomock.Any()).Return(&gh.IssuesSearchResult{}, nil)
+
+		_, err := connector.SearchOpenPullRequests(context.Background(), github.SearchQuery{Repo: "OtherOrg/repo"})
+
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should search the organisations the app is installed in", func() {
+		mockClient.EXPECT().GetOrg(gomock.Any(), gomock.Any()).Return(&gh.Organization{ID: gh.Int64(123)}, nil)
+		mockClient.EXPECT().ListTeams(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*gh.Team{{ID: gh.Int64(234), Name: gh.String("TestTeam")}}, nil)
+		cfg := config.GitHubConfiguration{Team: "TestTeam", Org: "TestOrg", App: config.GitHubAppConfiguration{
+			Installations: []config.GitHubAppInstallation{{Org: "OtherOrg"}},
+		}}
+		connector, err := github.NewGitHubConnector(context.Background(), cfg, mockClient)
+		Expect(err).ToNot(HaveOccurred())
+		mockClient.EXPECT().SearchIssues(gomock.Any(), "TestOrg", baseQuery+" review:none author:alice", gomock.Any()).Return(&gh.IssuesSearchResult{
+			Issues: []*gh.Issue{issue(1, created)},
+		}, nil)
+		otherIssue := issue(2, created.Add(time.Hour))
+		otherIssue.HTMLURL = gh.String("https://github.com/OtherOrg/other/pull/2")
+		otherIssue.RepositoryURL = gh.String("https://api.github.com/repos/OtherOrg/other")
+		mockClient.EXPECT().SearchIssues(gomock.Any(), "OtherOrg", "is:pr is:open draft:false archived:false org:OtherOrg review:none author:alice", gomock.Any()).Return(&gh.IssuesSearchResult{
+			Issues: []*gh.Issue{otherIssue},
+		}, nil)
+
+		pullRequests, err := connector.SearchOpenPullRequests(context.Background(), github.SearchQuery{Authors: []string{"alice"}, Unreviewed: true})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pullRequests).To(HaveLen(2))
+		Expect(pullRequests[1].Owner).To(Equal("OtherOrg"))
+		Expect(pullRequests[1].Repo).To(Equal("other"))
+	})
+
+	It("should filter on pull requests without reviews", func() {
+		mockClient.EXPECT().SearchIssues(gomock.Any(), "TestOrg", baseQuery+" review:none author:alice", gomock.Any()).Return(&gh.IssuesSearchResult{
+			Issues: []*gh.Issue{issue(1, created)},
+		}, nil).Times(1)
+
+		pullRequests, err := connector.SearchOpenPullRequests(context.Background(), github.SearchQuery{Authors: []string{"alice"}, Unreviewed: true})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pullRequests).To(HaveLen(1))
+		Expect(pullRequests[0].Review).To(Equal(github.ReviewPending))
+	})
+
+	It("should split large teams over several searches", func() {
+		authors := make([]string, 25)
+		for i := range authors {
+			authors[i] = fmt.Sprintf("user%d", i)
+		}
+		mockClient.EXPECT().SearchIssues(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&gh.IssuesSearchResult{}, nil).Times(6)
+
+		_, err := connector.SearchOpenPullRequests(context.Background(), github.SearchQuery{Authors: authors})
+
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should return error if searching fails", func() {
+		mockClient.EXPECT().SearchIssues(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("rate limited"))
+
+		pullRequests, err := connector.SearchOpenPullRequests(context.Background(), github.SearchQuery{Authors: []string{"alice"}})
+
+		Expect(err).To(HaveOccurred())
+		Expect(pullRequests).To(BeNil())
+	})
+})
@@ -0,0 +1,121 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package github
+
+import (
+	"context"
+	"git-slack-bot/internal/tracing"
+
+	"github.com/google/go-github/v56/github"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TracingClient is a Client that records a span for every github api call.
+type TracingClient struct {
+	client Client
+}
+
+func NewTracingClient(client Client) *TracingClient {
+	return &TracingClient{client: client}
+}
+
+func (c *TracingClient) ListTeams(ctx context.Context, org string, options *github.ListOptions) ([]*github.Team, error) {
+	ctx, span := tracing.Start(ctx, "github ListTeams", tracing.GithubOp.String("ListTeams"))
+	teams, err := c.client.ListTeams(ctx, org, options)
+	tracing.End(span, err)
+	return teams, err
+}
+
+func (c *TracingClient) ListTeamMembers(ctx context.Context, org string, team, orgID int64, opt *github.TeamListTeamMembersOptions) ([]*github.User, error) {
+	ctx, span := tracing.Start(ctx, "github ListTeamMembers", tracing.GithubOp.String("ListTeamMembers"))
+	members, err := c.client.ListTeamMembers(ctx, org, team, orgID, opt)
+	tracing.End(span, err)
+	return members, err
+}
+
+func (c *TracingClient) GetOrg(ctx context.Context, orgName string) (*github.Organization, error) {
+	ctx, span := tracing.Start(ctx, "github GetOrg", tracing.GithubOp.String("GetOrg"))
+	org, err := c.client.GetOrg(ctx, orgName)
+	tracing.End(span, err)
+	return org, err
+}
+
+func (c *TracingClient) ListPullRequests(ctx context.Context, owner, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, error) {
+	ctx, span := tracing.Start(ctx, "github ListPullRequests", tracing.GithubOp.String("ListPullRequests"), tracing.Repository.String(repo))
+	pullRequests, err := c.client.ListPullRequests(ctx, owner, repo, opts)
+	tracing.End(span, err)
+	return pullRequests, err
+}
+
+func (c *TracingClient) GetPullRequest(ctx context.Context, owner, repo string, number int) (*github.PullRequest, error) {
+	ctx, span := tracing.Start(ctx, "github GetPullRequest", tracing.GithubOp.String("GetPullRequest"), tracing.Repository.String(repo), tracing.PullRequestNum.Int(number))
+	pullRequest, err := c.client.GetPullRequest(ctx, owner, repo, number)
+	tracing.End(span, err)
+	return pullRequest, err
+}
+
+func (c *TracingClient) SearchIssues(ctx context.Context, owner, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, error) {
+	ctx, span := tracing.Start(ctx, "github SearchIssues", tracing.GithubOp.String("SearchIssues"), attribute.String("github.query", query))
+	result, err := c.client.SearchIssues(ctx, owner, query, opts)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (c *TracingClient) RequestReviewers(ctx context.Context, owner, repo string, number int, reviewers github.ReviewersRequest) (*github.PullRequest, error) {
+	ctx, span := tracing.Start(ctx, "github RequestReviewers", tracing.GithubOp.String("RequestReviewers"), tracing.Repository.String(repo), tracing.PullRequestNum.Int(number))
+	pullRequest, err := c.client.RequestReviewers(ctx, owner, repo, number, reviewers)
+	tracing.End(span, err)
+	return pullRequest, err
+}
+
+func (c *TracingClient) CreateIssueComment(ctx context.Context, owner, repo string, number int, comment *github.IssueComment) (*github.IssueComment, error) {
+	ctx, span := tracing.Start(ctx, "github CreateIssueComment", tracing.GithubOp.String("CreateIssueComment"), tracing.Repository.String(repo), tracing.PullRequestNum.Int(number))
+	issueComment, err := c.client.CreateIssueComment(ctx, owner, repo, number, comment)
+	tracing.End(span, err)
+	return issueComment, err
+}
+
+func (c *TracingClient) ListIssueTimeline(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) ([]*github.Timeline, error) {
+	ctx, span := tracing.Start(ctx, "github ListIssueTimeline", tracing.GithubOp.String("ListIssueTimeline"), tracing.Repository.String(repo), tracing.PullRequestNum.Int(number))
+	timeline, err := c.client.ListIssueTimeline(ctx, owner, repo, number, opts)
+	tracing.End(span, err)
+	return timeline, err
+}
+
+func (c *TracingClient) ListOrganizationEvents(ctx context.Context, org string, page int, etag string) (*EventsPage, error) {
+	ctx, span := tracing.Start(ctx, "github ListOrganizationEvents", tracing.GithubOp.String("ListOrganizationEvents"), attribute.Int("github.page", page))
+	events, err := c.client.ListOrganizationEvents(ctx, org, page, etag)
+	tracing.End(span, err)
+	return events, err
+}
+
+func (c *TracingClient) GraphQL(ctx context.Context, owner, query string, variables map[string]any, result any) error {
+	ctx, span := tracing.Start(ctx, "github GraphQL", tracing.GithubOp.String("GraphQL"))
+	err := c.client.GraphQL(ctx, owner, query, variables, result)
+	tracing.End(span, err)
+	return err
+}
+
+func (c *TracingClient) RateLimits(ctx context.Context) (*github.RateLimits, error) {
+	ctx, span := tracing.Start(ctx, "github RateLimits", tracing.GithubOp.String("RateLimits"))
+	rateLimits, err := c.client.RateLimits(ctx)
+	tracing.End(span, err)
+	return rateLimits, err
+}
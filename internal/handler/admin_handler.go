@@ -0,0 +1,118 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"git-slack-bot/internal/deadletter"
+	"git-slack-bot/internal/secret"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// DeadLetterAdmin lists, retries and discards the slack operations that failed.
+type DeadLetterAdmin interface {
+	List() []deadletter.Entry
+	Retry(ctx context.Context, id string) error
+	Discard(id string) error
+}
+
+// AdminHandler serves the admin endpoints, which need the admin token as a bearer token.
+type AdminHandler struct {
+	token       *secret.Secret
+	deadLetters DeadLetterAdmin
+}
+
+func NewAdminHandler(token *secret.Secret, deadLetters DeadLetterAdmin) *AdminHandler {
+	return &AdminHandler{
+		token:       token,
+		deadLetters: deadLetters,
+	}
+}
+
+// HandleListDeadLetters answers with the dead letters as a json array, oldest first.
+func (h *AdminHandler) HandleListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+	entries := h.deadLetters.List()
+	if entries == nil {
+		entries = []deadletter.Entry{}
+	}
+	writeAdminJSON(w, http.StatusOK, entries)
+}
+
+// HandleRetryDeadLetter delivers the dead letter of the id path value again. It answers 502 if that fails again.
+func (h *AdminHandler) HandleRetryDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+	id := r.PathValue("id")
+	err := h.deadLetters.Retry(r.Context(), id)
+	switch {
+	case errors.Is(err, deadletter.ErrNotFound):
+		http.Error(w, "Not found", http.StatusNotFound)
+	case err != nil:
+		slog.Error("Failed to retry dead letter", slog.String("id", id), slog.Any("error", err))
+		writeAdminJSON(w, http.StatusBadGateway, map[string]string{"error": err.Error()})
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// HandleDiscardDeadLetter removes the dead letter of the id path value without delivering it.
+func (h *AdminHandler) HandleDiscardDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+	id := r.PathValue("id")
+	err := h.deadLetters.Discard(id)
+	if errors.Is(err, deadletter.ErrNotFound) {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	slog.Info("Discarded dead letter", slog.String("id", id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorized checks the bearer token of r, answering 401 if it is not the admin token.
+func (h *AdminHandler) authorized(w http.ResponseWriter, r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	expected := h.token.Value()
+	if !ok || expected == "" || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		slog.Error("Unauthorized admin request", slog.String("path", r.URL.Path), slog.String("remoteAddr", r.RemoteAddr))
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		slog.Error("Failed to write admin response", slog.Any("error", err))
+	}
+}
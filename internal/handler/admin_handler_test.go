@@ -0,0 +1,125 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package handler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"git-slack-bot/internal/deadletter"
+	"git-slack-bot/internal/handler"
+	mock_handler "git-slack-bot/internal/handler/mocks"
+	"git-slack-bot/internal/secret"
+	"net/http"
+	"net/http/httptest"
+
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AdminHandler", func() {
+	var (
+		deadLettersMock *mock_handler.MockDeadLetterAdmin
+		adminHandler    *handler.AdminHandler
+		mux             *http.ServeMux
+	)
+
+	serve := func(method, path, token string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		deadLettersMock = mock_handler.NewMockDeadLetterAdmin(gomock.NewController(GinkgoT()))
+		adminHandler = handler.NewAdminHandler(secret.Fixed("admin-token"), deadLettersMock)
+		mux = http.NewServeMux()
+		mux.HandleFunc("GET /admin/dead-letters", adminHandler.HandleListDeadLetters)
+		mux.HandleFunc("POST /admin/dead-letters/{id}/retry", adminHandler.HandleRetryDeadLetter)
+		mux.HandleFunc("DELETE /admin/dead-letters/{id}", adminHandler.HandleDiscardDeadLetter)
+	})
+
+	It("should reject requests without the admin token", func() {
+		Expect(serve(http.MethodGet, "/admin/dead-letters", "").Code).To(Equal(http.StatusUnauthorized))
+		Expect(serve(http.MethodDelete, "/admin/dead-letters/abc", "wrong-token").Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should reject every request without a configured token", func() {
+		adminHandler = handler.NewAdminHandler(secret.Fixed(""), deadLettersMock)
+
+		request := httptest.NewRequest(http.MethodGet, "/admin/dead-letters", nil)
+		request.Header.Set("Authorization", "Bearer ")
+		recorder := httptest.NewRecorder()
+		adminHandler.HandleListDeadLetters(recorder, request)
+
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should list the dead letters", func() {
+		deadLettersMock.EXPECT().List().Return([]deadletter.Entry{{ID: "abc", Operation: deadletter.OperationMessage, Error: "service_unavailable"}})
+
+		response := serve(http.MethodGet, "/admin/dead-letters", "admin-token")
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var entries []deadletter.Entry
+		Expect(json.Unmarshal(response.Body.Bytes(), &entries)).To(Succeed())
+		Expect(entries).To(ConsistOf(HaveField("ID", "abc")))
+	})
+
+	It("should list no dead letters as an empty array", func() {
+		deadLettersMock.EXPECT().List().Return(nil)
+
+		Expect(serve(http.MethodGet, "/admin/dead-letters", "admin-token").Body.String()).To(Equal("[]\n"))
+	})
+
+	It("should retry a dead letter", func() {
+		deadLettersMock.EXPECT().Retry(gomock.Any(), "abc").Return(nil)
+
+		Expect(serve(http.MethodPost, "/admin/dead-letters/abc/retry", "admin-token").Code).To(Equal(http.StatusNoContent))
+	})
+
+	It("should report a retry that failed again", func() {
+		deadLettersMock.EXPECT().Retry(gomock.Any(), "abc").Return(errors.New("service_unavailable"))
+
+		response := serve(http.MethodPost, "/admin/dead-letters/abc/retry", "admin-token")
+
+		Expect(response.Code).To(Equal(http.StatusBadGateway))
+		Expect(response.Body.String()).To(ContainSubstring("service_unavailable"))
+	})
+
+	It("should discard a dead letter", func() {
+		deadLettersMock.EXPECT().Discard("abc").Return(nil)
+
+		Expect(serve(http.MethodDelete, "/admin/dead-letters/abc", "admin-token").Code).To(Equal(http.StatusNoContent))
+	})
+
+	It("should answer 404 for unknown dead letters", func() {
+		deadLettersMock.EXPECT().Retry(gomock.Any(), "abc").Return(deadletter.ErrNotFound)
+		deadLettersMock.EXPECT().Discard("abc").Return(deadletter.ErrNotFound)
+
+		Expect(serve(http.MethodPost, "/admin/dead-letters/abc/retry", "admin-token").Code).To(Equal(http.StatusNotFound))
+		Expect(serve(http.MethodDelete, "/admin/dead-letters/abc", "admin-token").Code).To(Equal(http.StatusNotFound))
+	})
+})
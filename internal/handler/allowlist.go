@@ -0,0 +1,97 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+)
+
+// AllowList holds the address ranges webhooks may come from.
+type AllowList struct {
+	prefixes []netip.Prefix
+}
+
+// LoadAllowList reads the ranges from path, either the json returned by GitHub's meta API, of which the hooks ranges
+// are used, or one CIDR per line, with blank lines and lines starting with # skipped.
+func LoadAllowList(path string) (*AllowList, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // The path comes from the configuration.
+	if err != nil {
+		return nil, err
+	}
+
+	var ranges []string
+	var meta struct {
+		Hooks []string `json:"hooks"`
+	}
+	if json.Unmarshal(data, &meta) == nil {
+		ranges = meta.Hooks
+	} else {
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && !strings.HasPrefix(line, "#") {
+				ranges = append(ranges, line)
+			}
+		}
+	}
+
+	allowList := &AllowList{}
+	for _, r := range ranges {
+		prefix, err := netip.ParsePrefix(r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		allowList.prefixes = append(allowList.prefixes, prefix.Masked())
+	}
+	if len(allowList.prefixes) == 0 {
+		return nil, fmt.Errorf("%s: no address ranges", path)
+	}
+	return allowList, nil
+}
+
+// Contains reports whether addr is in one of the ranges.
+func (a *AllowList) Contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range a.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceAddr returns the address a request came from. Behind a proxy that is the last address of X-Forwarded-For,
+// the one added by the proxy itself, as any before it could have been sent by the client.
+func sourceAddr(r *http.Request, trustForwardedFor bool) (netip.Addr, error) {
+	if forwardedFor := r.Header.Values("X-Forwarded-For"); trustForwardedFor && len(forwardedFor) > 0 {
+		addresses := strings.Split(forwardedFor[len(forwardedFor)-1], ",")
+		return netip.ParseAddr(strings.TrimSpace(addresses[len(addresses)-1]))
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	return netip.ParseAddr(host)
+}
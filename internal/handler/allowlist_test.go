@@ -0,0 +1,76 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package handler_test
+
+import (
+	"git-slack-bot/internal/handler"
+	"net/netip"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoadAllowList", func() {
+	var path string
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "hooks")
+	})
+
+	It("should load the hooks ranges of GitHub's meta API", func() {
+		Expect(os.WriteFile(path, []byte(`{"hooks": ["192.30.252.0/22", "2a0a:a440::/29"], "web": ["140.82.112.0/20"]}`), 0o600)).To(Succeed())
+
+		allowList, err := handler.LoadAllowList(path)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowList.Contains(netip.MustParseAddr("192.30.253.1"))).To(BeTrue())
+		Expect(allowList.Contains(netip.MustParseAddr("::ffff:192.30.253.1"))).To(BeTrue())
+		Expect(allowList.Contains(netip.MustParseAddr("2a0a:a440::1"))).To(BeTrue())
+		Expect(allowList.Contains(netip.MustParseAddr("140.82.112.1"))).To(BeFalse())
+	})
+
+	It("should load one range per line", func() {
+		Expect(os.WriteFile(path, []byte("# github hooks\n192.30.252.0/22\n\n185.199.108.0/22\n"), 0o600)).To(Succeed())
+
+		allowList, err := handler.LoadAllowList(path)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowList.Contains(netip.MustParseAddr("185.199.108.1"))).To(BeTrue())
+		Expect(allowList.Contains(netip.MustParseAddr("10.0.0.1"))).To(BeFalse())
+	})
+
+	It("should fail on an invalid range", func() {
+		Expect(os.WriteFile(path, []byte("192.30.252.0/33\n"), 0o600)).To(Succeed())
+
+		_, err := handler.LoadAllowList(path)
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail without any ranges", func() {
+		Expect(os.WriteFile(path, []byte(`{"web": ["140.82.112.0/20"]}`), 0o600)).To(Succeed())
+
+		_, err := handler.LoadAllowList(path)
+
+		Expect(err).To(MatchError(ContainSubstring("no address ranges")))
+	})
+})
@@ -0,0 +1,228 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"git-slack-bot/internal/github"
+	messageBuilder "git-slack-bot/internal/messagebuilder"
+	"git-slack-bot/internal/user"
+	"log/slog"
+	"strings"
+	"time"
+
+	sl "github.com/slack-go/slack"
+)
+
+const (
+	defaultStaleAfter = 48 * time.Hour
+	// responseTimeout bounds posting the answer to a slash command to its response url.
+	responseTimeout = 10 * time.Second
+
+	prsUsage string = "Usage: `/prs mine`, `/prs team`, `/prs stale` or `/prs repo <name>`"
+)
+
+type SlashCommandHandler interface {
+	HandleSlashCommand(ctx context.Context, command sl.SlashCommand) *sl.Msg
+}
+
+// respondToSlashCommand posts the answer of commandHandler to the response url of command. Searching GitHub can take
+// longer than the 3 seconds slack waits for a slash command to be acknowledged, so commands are acknowledged first
+// and answered this way.
+func respondToSlashCommand(ctx context.Context, commandHandler SlashCommandHandler, command sl.SlashCommand) {
+	msg := commandHandler.HandleSlashCommand(ctx, command)
+	response := &sl.WebhookMessage{ResponseType: msg.ResponseType, Text: msg.Text}
+	if len(msg.Blocks.BlockSet) > 0 {
+		response.Blocks = &msg.Blocks
+	}
+	ctx, cancel := context.WithTimeout(ctx, responseTimeout)
+	defer cancel()
+	err := sl.PostWebhookContext(ctx, command.ResponseURL, response)
+	if err != nil {
+		slog.Error("Failed to answer slash command", slog.String("command", command.Command), slog.String("user", command.UserID), slog.Any("error", err))
+	}
+}
+
+// PRCommandHandler answers the `/prs` slash command with an ephemeral list of open pull requests.
+type PRCommandHandler struct {
+	githubConnector github.Interactor
+	userService     user.Service
+	messageBuilder  messageBuilder.MessageBuilder
+	staleAfter      time.Duration
+}
+
+// NewPRCommandHandler creates a PRCommandHandler. `/prs stale` lists pull requests that have not been updated for
+// staleAfter, which defaults to two days.
+func NewPRCommandHandler(githubConnector github.Interactor, userService user.Service, staleAfter time.Duration) *PRCommandHandler {
+	if staleAfter == 0 {
+		staleAfter = defaultStaleAfter
+	}
+	return &PRCommandHandler{
+		githubConnector: githubConnector,
+		userService:     userService,
+		messageBuilder:  messageBuilder.MessageBuilder{},
+		staleAfter:      staleAfter,
+	}
+}
+
+func (h *PRCommandHandler) HandleSlashCommand(ctx context.Context, command sl.SlashCommand) *sl.Msg {
+	args := strings.Fields(command.Text)
+	if len(args) == 0 {
+		return ephemeralText(prsUsage)
+	}
+
+	now := time.Now()
+	switch {
+	case args[0] == "mine" && len(args) == 1:
+		return h.mine(ctx, command.UserID, now)
+	case args[0] == "team" && len(args) == 1:
+		return h.team(ctx, now)
+	case args[0] == "stale" && len(args) == 1:
+		return h.stale(ctx, now)
+	case args[0] == "repo" && len(args) == 2:
+		return h.repo(ctx, args[1], now)
+	default:
+		return ephemeralText(prsUsage)
+	}
+}
+
+func (h *PRCommandHandler) mine(ctx context.Context, slackUserID string, now time.Time) *sl.Msg {
+	githubLogin, err := h.userService.GetGithubLogin(ctx, slackUserID)
+	if err != nil {
+		slog.Error("Failed to get github login of slack user", slog.String("user", slackUserID), slog.Any("error", err))
+		return ephemeralText("I couldn't find your GitHub account. Ask for it to be added to `githubEmailToSlackEmail`.")
+	}
+	toReview, err := h.githubConnector.SearchOpenPullRequests(ctx, github.SearchQuery{ReviewRequested: githubLogin})
+	if err != nil {
+		return searchFailed(err)
+	}
+	own, err := h.githubConnector.SearchOpenPullRequests(ctx, github.SearchQuery{Authors: []string{githubLogin}})
+	if err != nil {
+		return searchFailed(err)
+	}
+	return h.blocks("Your PRs", []messageBuilder.DigestGroup{
+		{Title: "Waiting on your review", Entries: h.entries(ctx, toReview, now)},
+		{Title: "Opened by you", Entries: h.entries(ctx, own, now)},
+	})
+}
+
+func (h *PRCommandHandler) team(ctx context.Context, now time.Time) *sl.Msg {
+	pullRequests, err := h.teamPullRequests(ctx)
+	if err != nil {
+		return searchFailed(err)
+	}
+	groups := []messageBuilder.DigestGroup{{Title: "Awaiting review"}, {Title: "Changes requested"}, {Title: "Approved"}}
+	for _, entry := range h.entries(ctx, pullRequests, now) {
+		switch entry.PullRequest.Review {
+		case github.ReviewChangesRequested:
+			groups[1].Entries = append(groups[1].Entries, entry)
+		case github.ReviewApproved:
+			groups[2].Entries = append(groups[2].Entries, entry)
+		default:
+			groups[0].Entries = append(groups[0].Entries, entry)
+		}
+	}
+	return h.blocks("Team PRs", groups)
+}
+
+func (h *PRCommandHandler) stale(ctx context.Context, now time.Time) *sl.Msg {
+	pullRequests, err := h.teamPullRequests(ctx)
+	if err != nil {
+		return searchFailed(err)
+	}
+	var stale []messageBuilder.DigestEntry
+	for _, entry := range h.entries(ctx, pullRequests, now) {
+		if entry.Stale {
+			stale = append(stale, entry)
+		}
+	}
+	return h.blocks("Stale team PRs", []messageBuilder.DigestGroup{
+		{Title: fmt.Sprintf("Not updated for %s", formatDuration(h.staleAfter)), Entries: stale},
+	})
+}
+
+func (h *PRCommandHandler) repo(ctx context.Context, repo string, now time.Time) *sl.Msg {
+	pullRequests, err := h.githubConnector.SearchOpenPullRequests(ctx, github.SearchQuery{Repo: repo})
+	if err != nil {
+		return searchFailed(err)
+	}
+	return h.blocks(fmt.Sprintf("PRs in %s", repo), []messageBuilder.DigestGroup{
+		{Title: "Open", Entries: h.entries(ctx, pullRequests, now)},
+	})
+}
+
+func (h *PRCommandHandler) teamPullRequests(ctx context.Context) ([]*github.OpenPullRequest, error) {
+	teamMembers := h.userService.GetTeamMembers()
+	if len(teamMembers) == 0 {
+		return nil, nil
+	}
+	return h.githubConnector.SearchOpenPullRequests(ctx, github.SearchQuery{Authors: teamMembers})
+}
+
+func (h *PRCommandHandler) entries(ctx context.Context, pullRequests []*github.OpenPullRequest, now time.Time) []messageBuilder.DigestEntry {
+	entries := make([]messageBuilder.DigestEntry, 0, len(pullRequests))
+	userDescriptors := make(map[string]string)
+	for _, pullRequest := range pullRequests {
+		userDescriptor, ok := userDescriptors[pullRequest.Author]
+		if !ok {
+			userDescriptor = h.userService.GetUserDescriptor(ctx, pullRequest.Author)
+			userDescriptors[pullRequest.Author] = userDescriptor
+		}
+		entries = append(entries, messageBuilder.DigestEntry{
+			UserDescriptor: userDescriptor,
+			PullRequest:    pullRequest,
+			Age:            now.Sub(pullRequest.CreatedAt),
+			Stale:          pullRequest.IsStale(now, h.staleAfter),
+		})
+	}
+	return entries
+}
+
+func (h *PRCommandHandler) blocks(title string, groups []messageBuilder.DigestGroup) *sl.Msg {
+	return &sl.Msg{
+		ResponseType: sl.ResponseTypeEphemeral,
+		Text:         title,
+		Blocks:       sl.Blocks{BlockSet: h.messageBuilder.BuildPRListBlocks(title, groups)},
+	}
+}
+
+func searchFailed(err error) *sl.Msg {
+	slog.Error("Failed to search pull requests for slash command", slog.Any("error", err))
+	return ephemeralText("Something went wrong while searching GitHub, please try again.")
+}
+
+func ephemeralText(text string) *sl.Msg {
+	return &sl.Msg{
+		ResponseType: sl.ResponseTypeEphemeral,
+		Text:         text,
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		days := int(d.Hours() / 24)
+		if days == 1 {
+			return "1 day"
+		}
+		return fmt.Sprintf("%d days", days)
+	}
+	return d.String()
+}
@@ -0,0 +1,160 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"git-slack-bot/internal/github"
+	mock_github "git-slack-bot/internal/github/mocks"
+	"git-slack-bot/internal/handler"
+	mock_user "git-slack-bot/internal/user/mocks"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PRCommandHandler", func() {
+	var (
+		githubMock     *mock_github.MockInteractor
+		userMock       *mock_user.MockService
+		commandHandler *handler.PRCommandHandler
+	)
+
+	pullRequest := func(number int, review github.ReviewState, updatedAgo time.Duration) *github.OpenPullRequest {
+		return &github.OpenPullRequest{
+			Number:    number,
+			Title:     "Add caching",
+			URL:       "https://github.com/org/repo/pull/1",
+			Repo:      "repo",
+			Author:    "alice",
+			CreatedAt: time.Now().Add(-updatedAgo),
+			UpdatedAt: time.Now().Add(-updatedAgo),
+			Review:    review,
+		}
+	}
+
+	sectionTexts := func(msg *slack.Msg) []string {
+		var texts []string
+		for _, block := range msg.Blocks.BlockSet {
+			if section, ok := block.(*slack.SectionBlock); ok {
+				texts = append(texts, section.Text.Text)
+			}
+		}
+		return texts
+	}
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		githubMock = mock_github.NewMockInteractor(mockCtrl)
+		userMock = mock_user.NewMockService(mockCtrl)
+		commandHandler = handler.NewPRCommandHandler(githubMock, userMock, 48*time.Hour)
+
+		userMock.EXPECT().GetUserDescriptor(gomock.Any(), "alice").Return("<@A>").AnyTimes()
+	})
+
+	It("should list the pull requests of the user", func() {
+		userMock.EXPECT().GetGithubLogin(gomock.Any(), "U123").Return("bob", nil)
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), github.SearchQuery{ReviewRequested: "bob"}).Return([]*github.OpenPullRequest{pullRequest(1, github.ReviewPending, time.Hour)}, nil)
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), github.SearchQuery{Authors: []string{"bob"}}).Return(nil, nil)
+
+		msg := commandHandler.HandleSlashCommand(context.Background(), slack.SlashCommand{UserID: "U123", Text: "mine"})
+
+		Expect(msg.ResponseType).To(Equal(slack.ResponseTypeEphemeral))
+		Expect(sectionTexts(msg)).To(Equal([]string{
+			"*Waiting on your review (1)*",
+			"• <https://github.com/org/repo/pull/1|Add caching> in `repo` by <@A>, opened today",
+			"*Opened by you (0)*",
+		}))
+	})
+
+	It("should tell unmapped users how to get mapped", func() {
+		userMock.EXPECT().GetGithubLogin(gomock.Any(), "U123").Return("", errors.New("not mapped"))
+
+		msg := commandHandler.HandleSlashCommand(context.Background(), slack.SlashCommand{UserID: "U123", Text: "mine"})
+
+		Expect(msg.Text).To(ContainSubstring("githubEmailToSlackEmail"))
+	})
+
+	It("should group the team's pull requests by review state", func() {
+		userMock.EXPECT().GetTeamMembers().Return([]string{"alice"})
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), github.SearchQuery{Authors: []string{"alice"}}).Return([]*github.OpenPullRequest{
+			pullRequest(1, github.ReviewApproved, time.Hour),
+			pullRequest(2, github.ReviewPending, time.Hour),
+		}, nil)
+
+		msg := commandHandler.HandleSlashCommand(context.Background(), slack.SlashCommand{Text: "team"})
+
+		Expect(msg.Text).To(Equal("Team PRs"))
+		Expect(sectionTexts(msg)).To(HaveExactElements(
+			"*Awaiting review (1)*",
+			HavePrefix("• "),
+			"*Changes requested (0)*",
+			"*Approved (1)*",
+			HavePrefix("• "),
+		))
+	})
+
+	It("should list only stale team pull requests", func() {
+		userMock.EXPECT().GetTeamMembers().Return([]string{"alice"})
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return([]*github.OpenPullRequest{
+			pullRequest(1, github.ReviewPending, 72*time.Hour),
+			pullRequest(2, github.ReviewPending, time.Hour),
+		}, nil)
+
+		msg := commandHandler.HandleSlashCommand(context.Background(), slack.SlashCommand{Text: "stale"})
+
+		Expect(sectionTexts(msg)).To(Equal([]string{
+			"*Not updated for 2 days (1)*",
+			"• <https://github.com/org/repo/pull/1|Add caching> in `repo` by <@A>, opened 3 days ago :hourglass_flowing_sand: *stale*",
+		}))
+	})
+
+	It("should list the pull requests of a repo", func() {
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), github.SearchQuery{Repo: "frontier"}).Return(nil, nil)
+
+		msg := commandHandler.HandleSlashCommand(context.Background(), slack.SlashCommand{Text: "repo frontier"})
+
+		Expect(msg.Text).To(Equal("PRs in frontier"))
+	})
+
+	It("should report failed searches", func() {
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return(nil, errors.New("rate limited"))
+
+		msg := commandHandler.HandleSlashCommand(context.Background(), slack.SlashCommand{Text: "repo frontier"})
+
+		Expect(msg.ResponseType).To(Equal(slack.ResponseTypeEphemeral))
+		Expect(msg.Text).To(ContainSubstring("went wrong"))
+	})
+
+	DescribeTable("should explain the usage of unknown subcommands", func(text string) {
+		msg := commandHandler.HandleSlashCommand(context.Background(), slack.SlashCommand{Text: text})
+
+		Expect(msg.Text).To(HavePrefix("Usage:"))
+	},
+		Entry("no subcommand", ""),
+		Entry("unknown subcommand", "everything"),
+		Entry("repo without name", "repo"),
+	)
+})
@@ -0,0 +1,118 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package handler
+
+import (
+	"context"
+	"git-slack-bot/internal/github"
+	messageBuilder "git-slack-bot/internal/messagebuilder"
+	"git-slack-bot/internal/slack"
+	"git-slack-bot/internal/user"
+	"log/slog"
+	"regexp"
+
+	"github.com/slack-go/slack/slackevents"
+)
+
+// announcedPullRequestPattern matches the bare pull request link of an announcement, which slack stores as <url>, and
+// not the <url|title> links of digests and other messages that merely mention a pull request.
+var announcedPullRequestPattern = regexp.MustCompile(`<(https://github\.com/[^/\s|>]+/[^/\s|>]+/pull/\d+)>`)
+
+type EventHandler interface {
+	HandleEvent(ctx context.Context, event slackevents.EventsAPIEvent)
+}
+
+// ThreadReplyHandler posts replies from mapped users in the threads of pull request announcements back to the pull
+// request as comments.
+type ThreadReplyHandler struct {
+	githubConnector github.Interactor
+	slackConnector  slack.Interactor
+	userService     user.Service
+	messageBuilder  messageBuilder.MessageBuilder
+	channelID       string
+}
+
+func NewThreadReplyHandler(githubConnector github.Interactor, slackConnector slack.Interactor, userService user.Service, channelID string) *ThreadReplyHandler {
+	return &ThreadReplyHandler{
+		githubConnector: githubConnector,
+		slackConnector:  slackConnector,
+		userService:     userService,
+		messageBuilder:  messageBuilder.MessageBuilder{},
+		channelID:       channelID,
+	}
+}
+
+func (h *ThreadReplyHandler) HandleEvent(ctx context.Context, event slackevents.EventsAPIEvent) {
+	if event.Type != slackevents.CallbackEvent {
+		return
+	}
+	if message, ok := event.InnerEvent.Data.(*slackevents.MessageEvent); ok {
+		h.handleMessage(ctx, message)
+	}
+}
+
+func (h *ThreadReplyHandler) handleMessage(ctx context.Context, message *slackevents.MessageEvent) {
+	if message.Channel != h.channelID || message.ThreadTimeStamp == "" || message.ThreadTimeStamp == message.TimeStamp {
+		return
+	}
+	// Bot messages include the bot's own mirrored github comments. Subtypes are edits, deletions and the like.
+	if message.BotID != "" || message.SubType != "" {
+		return
+	}
+
+	githubLogin, err := h.userService.GetGithubLogin(ctx, message.User)
+	if err != nil {
+		slog.Debug("Not syncing thread reply of unmapped slack user", slog.String("user", message.User), slog.Any("error", err))
+		return
+	}
+	parent, err := h.slackConnector.GetMessageByTimestamp(ctx, message.ThreadTimeStamp)
+	if err != nil {
+		slog.Error("Could not find thread of reply", slog.String("thread", message.ThreadTimeStamp), slog.Any("error", err))
+		return
+	}
+	// Only the bot's own announcements stand for a single pull request.
+	match := announcedPullRequestPattern.FindStringSubmatch(parent.Text)
+	if parent.BotID == "" || match == nil {
+		return
+	}
+	pullRequestURL := match[1]
+	owner, repo, number, err := github.ParsePullRequestURL(pullRequestURL)
+	if err != nil {
+		slog.Error("Invalid pull request in thread", slog.String("url", pullRequestURL), slog.Any("error", err))
+		return
+	}
+
+	err = h.githubConnector.CreateIssueComment(ctx, owner, repo, number, h.messageBuilder.BuildGitHubComment(message.Text, githubLogin, func(slackUserID string) string {
+		return h.mentionOf(ctx, slackUserID)
+	}))
+	if err != nil {
+		slog.Error("Failed to post thread reply to github", slog.String("pullRequest", pullRequestURL), slog.Any("error", err))
+		return
+	}
+	slog.Info("Posted thread reply to github", slog.String("pullRequest", pullRequestURL), slog.String("user", githubLogin))
+}
+
+func (h *ThreadReplyHandler) mentionOf(ctx context.Context, slackUserID string) string {
+	githubLogin, err := h.userService.GetGithubLogin(ctx, slackUserID)
+	if err != nil {
+		return "someone on Slack"
+	}
+	return "@" + githubLogin
+}
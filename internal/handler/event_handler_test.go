@@ -0,0 +1,129 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package handler_test
+
+import (
+	"context"
+	"errors"
+	mock_github "git-slack-bot/internal/github/mocks"
+	"git-slack-bot/internal/handler"
+	"git-slack-bot/internal/messagebuilder"
+	mock_slack "git-slack-bot/internal/slack/mocks"
+	mock_user "git-slack-bot/internal/user/mocks"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("ThreadReplyHandler", func() {
+	var (
+		githubMock *mock_github.MockInteractor
+		slackMock  *mock_slack.MockInteractor
+		userMock   *mock_user.MockService
+		reply      *slackevents.MessageEvent
+	)
+
+	event := func(message *slackevents.MessageEvent) slackevents.EventsAPIEvent {
+		return slackevents.EventsAPIEvent{
+			Type:       slackevents.CallbackEvent,
+			InnerEvent: slackevents.EventsAPIInnerEvent{Type: "message", Data: message},
+		}
+	}
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		githubMock = mock_github.NewMockInteractor(mockCtrl)
+		slackMock = mock_slack.NewMockInteractor(mockCtrl)
+		userMock = mock_user.NewMockService(mockCtrl)
+		reply = &slackevents.MessageEvent{
+			Channel:         "C123",
+			User:            "U123",
+			Text:            "Looks good, <@U456> can you double check?",
+			TimeStamp:       "1700000000.000200",
+			ThreadTimeStamp: "1700000000.000100",
+		}
+	})
+
+	It("should post a thread reply to the pull request", func() {
+		userMock.EXPECT().GetGithubLogin(gomock.Any(), "U123").Return("bob", nil)
+		userMock.EXPECT().GetGithubLogin(gomock.Any(), "U456").Return("carol", nil)
+		slackMock.EXPECT().GetMessageByTimestamp(gomock.Any(), "1700000000.000100").Return(&slack.Message{Msg: slack.Msg{
+			BotID: "B123",
+			Text:  "<@U789> Add caching:\n<https://github.com/org/repo/pull/7>",
+		}}, nil)
+		githubMock.EXPECT().CreateIssueComment(gomock.Any(), "org", "repo", 7, "Looks good, @carol can you double check?\n\n_via Slack by @bob_\n"+messagebuilder.SlackCommentMarker).Return(nil)
+
+		handler.NewThreadReplyHandler(githubMock, slackMock, userMock, "C123").HandleEvent(context.Background(), event(reply))
+	})
+
+	It("should not post replies of unmapped users", func() {
+		userMock.EXPECT().GetGithubLogin(gomock.Any(), "U123").Return("", errors.New("not mapped"))
+		githubMock.EXPECT().CreateIssueComment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		handler.NewThreadReplyHandler(githubMock, slackMock, userMock, "C123").HandleEvent(context.Background(), event(reply))
+	})
+
+	It("should not post replies in threads that aren't about a pull request", func() {
+		userMock.EXPECT().GetGithubLogin(gomock.Any(), "U123").Return("bob", nil)
+		slackMock.EXPECT().GetMessageByTimestamp(gomock.Any(), gomock.Any()).Return(&slack.Message{Msg: slack.Msg{Text: "Lunch?"}}, nil)
+		githubMock.EXPECT().CreateIssueComment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		handler.NewThreadReplyHandler(githubMock, slackMock, userMock, "C123").HandleEvent(context.Background(), event(reply))
+	})
+
+	It("should not post replies in the thread of a digest", func() {
+		userMock.EXPECT().GetGithubLogin(gomock.Any(), "U123").Return("bob", nil)
+		slackMock.EXPECT().GetMessageByTimestamp(gomock.Any(), gomock.Any()).Return(&slack.Message{Msg: slack.Msg{
+			BotID: "B123",
+			Text:  "*Open PRs*\n<https://github.com/org/repo/pull/7|Add caching> in `repo`, opened today\n<https://github.com/org/repo/pull/8|Fix login> in `repo`, opened today",
+		}}, nil)
+		githubMock.EXPECT().CreateIssueComment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		handler.NewThreadReplyHandler(githubMock, slackMock, userMock, "C123").HandleEvent(context.Background(), event(reply))
+	})
+
+	It("should not post replies in the thread of someone else's message linking a pull request", func() {
+		userMock.EXPECT().GetGithubLogin(gomock.Any(), "U123").Return("bob", nil)
+		slackMock.EXPECT().GetMessageByTimestamp(gomock.Any(), gomock.Any()).Return(&slack.Message{Msg: slack.Msg{
+			User: "U789",
+			Text: "Could someone look at <https://github.com/org/repo/pull/7>?",
+		}}, nil)
+		githubMock.EXPECT().CreateIssueComment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		handler.NewThreadReplyHandler(githubMock, slackMock, userMock, "C123").HandleEvent(context.Background(), event(reply))
+	})
+
+	DescribeTable("should ignore messages that aren't thread replies of people", func(change func(message *slackevents.MessageEvent)) {
+		change(reply)
+		userMock.EXPECT().GetGithubLogin(gomock.Any(), gomock.Any()).Times(0)
+		githubMock.EXPECT().CreateIssueComment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		handler.NewThreadReplyHandler(githubMock, slackMock, userMock, "C123").HandleEvent(context.Background(), event(reply))
+	},
+		Entry("bot message", func(message *slackevents.MessageEvent) { message.BotID = "B123" }),
+		Entry("edited message", func(message *slackevents.MessageEvent) { message.SubType = "message_changed" }),
+		Entry("other channel", func(message *slackevents.MessageEvent) { message.Channel = "C999" }),
+		Entry("top level message", func(message *slackevents.MessageEvent) { message.ThreadTimeStamp = "" }),
+		Entry("thread parent", func(message *slackevents.MessageEvent) { message.ThreadTimeStamp = message.TimeStamp }),
+	)
+})
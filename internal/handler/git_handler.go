@@ -20,16 +20,25 @@ Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/conflict"
+	"git-slack-bot/internal/logging"
 	messageBuilder "git-slack-bot/internal/messagebuilder"
+	"git-slack-bot/internal/metrics"
 	"git-slack-bot/internal/slack"
+	"git-slack-bot/internal/tracing"
 	"git-slack-bot/internal/user"
 	"log/slog"
 	"slices"
+	"strings"
+	"sync/atomic"
 
 	gh "github.com/google/go-github/v56/github"
+	"github.com/prometheus/client_golang/prometheus"
+	sl "github.com/slack-go/slack"
 )
 
 const (
@@ -39,101 +48,144 @@ const (
 	reopened       string = "reopened"
 	submitted      string = "submitted"
 	approved       string = "approved"
+
+	branchRefPrefix string = "refs/heads/"
 )
 
 type GitEventHandler interface {
-	HandlePullRequestEvent(body []byte)
-	HandlePullRequestReviewEvent(body []byte)
-	HandlePullRequestReviewCommentEvent(body []byte)
-	HandleIssueCommentEvent(body []byte)
+	HandlePullRequestEvent(ctx context.Context, body []byte)
+	HandlePullRequestReviewEvent(ctx context.Context, body []byte)
+	HandlePullRequestReviewCommentEvent(ctx context.Context, body []byte)
+	HandleIssueCommentEvent(ctx context.Context, body []byte)
+	HandlePushEvent(ctx context.Context, body []byte)
 }
 
 type GitHandler struct {
-	slackConnector slack.Interactor
-	messageBuilder messageBuilder.MessageBuilder
-	userService    user.Service
-	emoji          config.EmojiConfiguration
-	ignoredRepos   []string
+	slackConnector  slack.Interactor
+	messageBuilder  messageBuilder.MessageBuilder
+	userService     user.Service
+	conflictChecker conflict.Checker
+	prActions       messageBuilder.PRActions
+	settings        atomic.Pointer[gitHandlerSettings]
+}
+
+// gitHandlerSettings are the settings that can be reloaded while events are being handled.
+type gitHandlerSettings struct {
+	emoji        config.EmojiConfiguration
+	ignoredRepos []string
 }
 
-func NewGitHandler(slackConnector slack.Interactor, userService user.Service, emoji config.EmojiConfiguration, ignoredRepos []string) *GitHandler {
-	return &GitHandler{
-		slackConnector: slackConnector,
-		messageBuilder: messageBuilder.MessageBuilder{},
-		userService:    userService,
-		emoji:          emoji,
-		ignoredRepos:   ignoredRepos,
+// NewGitHandler creates a GitHandler. conflictChecker may be nil, in which case push events are ignored. Pull request
+// announcements carry the buttons enabled in prActions.
+func NewGitHandler(slackConnector slack.Interactor, userService user.Service, conflictChecker conflict.Checker, emoji config.EmojiConfiguration, prActions messageBuilder.PRActions, ignoredRepos []string) *GitHandler {
+	g := &GitHandler{
+		slackConnector:  slackConnector,
+		messageBuilder:  messageBuilder.MessageBuilder{},
+		userService:     userService,
+		conflictChecker: conflictChecker,
+		prActions:       prActions,
 	}
+	g.Reload(emoji, ignoredRepos)
+	return g
+}
+
+// Reload replaces the emoji and ignored repositories. Events being handled finish with the previous ones.
+func (g *GitHandler) Reload(emoji config.EmojiConfiguration, ignoredRepos []string) {
+	g.settings.Store(&gitHandlerSettings{emoji: emoji, ignoredRepos: ignoredRepos})
 }
 
-func (g *GitHandler) HandlePullRequestEvent(body []byte) {
+func (g *GitHandler) HandlePullRequestEvent(ctx context.Context, body []byte) {
+	defer prometheus.NewTimer(metrics.HandlerDuration.WithLabelValues(pullRequestEvent)).ObserveDuration()
+	ctx, span := tracing.Start(ctx, "GitHandler.HandlePullRequestEvent")
+	defer span.End()
+
 	var event gh.PullRequestEvent
 	err := json.Unmarshal(body, &event)
 	if err != nil {
-		slog.Error("Error parsing request body", slog.Any("error", err), slog.String("body", string(body)))
+		slog.ErrorContext(ctx, "Error parsing request body", slog.Any("error", err), slog.String("body", string(body)))
 		return
 	}
 	pullRequest := event.PullRequest
+	span.SetAttributes(tracing.Repository.String(event.GetRepo().GetFullName()), tracing.Action.String(event.GetAction()), tracing.PullRequestURL.String(pullRequest.GetHTMLURL()))
+	ctx = logging.With(ctx, slog.String("repo", event.GetRepo().GetFullName()), slog.Int("prNumber", pullRequest.GetNumber()))
 
 	if g.isIgnoredRepo(*event.Repo.Name) {
+		metrics.Filtered(metrics.FilteredIgnoredRepo)
 		return
 	}
 
 	if !g.userService.IsTeamMember(*pullRequest.User.Login) {
+		metrics.Filtered(metrics.FilteredNonTeamAuthor)
 		return
 	}
 
 	switch *event.Action {
 	case opened, readyForReview:
 		if pullRequest.Draft != nil && *pullRequest.Draft {
+			metrics.Filtered(metrics.FilteredDraft)
 			return
 		}
 		githubLogin := *pullRequest.User.Login
-		g.slackConnector.SendMessage(g.messageBuilder.BuildPRMessage(g.userService.GetUserDescriptor(githubLogin), pullRequest))
+		message := g.messageBuilder.BuildPRMessage(g.userService.GetUserDescriptor(ctx, githubLogin), pullRequest)
+		var blocks []sl.Block
+		if g.prActions.Any() {
+			blocks = g.messageBuilder.BuildPRBlocks(message, pullRequest, g.prActions)
+		}
+		g.slackConnector.Announce(ctx, pullRequest.GetHTMLURL(), message, blocks)
 	case closed:
 		if pullRequest.Draft != nil && *pullRequest.Draft {
+			metrics.Filtered(metrics.FilteredDraft)
 			return
 		}
 		messageKey := fmt.Sprintf("<%s>", *pullRequest.HTMLURL)
-		slackMessage, err := g.slackConnector.GetMessage(messageKey)
+		slackMessage, err := g.slackConnector.GetMessage(ctx, messageKey)
 		if err != nil {
-			slog.Error("Could not find message", slog.Any("messageKey", messageKey), slog.Any("error", err))
+			slog.ErrorContext(ctx, "Could not find message", slog.Any("messageKey", messageKey), slog.Any("error", err))
 			return
 		}
 		if event.PullRequest.MergedAt != nil {
-			g.slackConnector.AddReactionToMessage(g.emoji.Merge, slackMessage)
+			g.slackConnector.AddReactionToMessage(ctx, g.settings.Load().emoji.Merge, slackMessage)
 		} else {
-			g.slackConnector.AddReactionToMessage(g.emoji.Close, slackMessage)
+			g.slackConnector.AddReactionToMessage(ctx, g.settings.Load().emoji.Close, slackMessage)
 		}
 	case reopened:
 		messageKey := fmt.Sprintf("<%s>", *pullRequest.HTMLURL)
-		slackMessage, err := g.slackConnector.GetMessage(messageKey)
+		slackMessage, err := g.slackConnector.GetMessage(ctx, messageKey)
 		if err != nil {
-			slog.Error("Could not find message", slog.Any("messageKey", messageKey), slog.Any("error", err))
+			slog.ErrorContext(ctx, "Could not find message", slog.Any("messageKey", messageKey), slog.Any("error", err))
 			return
 		}
-		g.slackConnector.RemoveReactionFromMessage("x", slackMessage)
+		g.slackConnector.RemoveReactionFromMessage(ctx, "x", slackMessage)
 	}
 }
 
-func (g *GitHandler) HandlePullRequestReviewEvent(body []byte) {
+func (g *GitHandler) HandlePullRequestReviewEvent(ctx context.Context, body []byte) {
+	defer prometheus.NewTimer(metrics.HandlerDuration.WithLabelValues(pullRequestReviewEvent)).ObserveDuration()
+	ctx, span := tracing.Start(ctx, "GitHandler.HandlePullRequestReviewEvent")
+	defer span.End()
+
 	var event gh.PullRequestReviewEvent
 	err := json.Unmarshal(body, &event)
 	if err != nil {
-		slog.Error("Error parsing request body", slog.Any("body", string(body)), slog.Any("error", err))
+		slog.ErrorContext(ctx, "Error parsing request body", slog.Any("body", string(body)), slog.Any("error", err))
 		return
 	}
 	pullRequest := event.PullRequest
+	span.SetAttributes(tracing.Repository.String(event.GetRepo().GetFullName()), tracing.Action.String(event.GetAction()), tracing.PullRequestURL.String(pullRequest.GetHTMLURL()))
+	ctx = logging.With(ctx, slog.String("repo", event.GetRepo().GetFullName()), slog.Int("prNumber", pullRequest.GetNumber()))
 
 	if g.isIgnoredRepo(*event.Repo.Name) {
+		metrics.Filtered(metrics.FilteredIgnoredRepo)
 		return
 	}
 
 	if !g.userService.IsTeamMember(*pullRequest.User.Login) {
+		metrics.Filtered(metrics.FilteredNonTeamAuthor)
 		return
 	}
 
 	if g.userService.IsIgnoredReviewUser(*event.Review.User.Login) {
+		metrics.Filtered(metrics.FilteredIgnoredUser)
 		return
 	}
 
@@ -141,73 +193,127 @@ func (g *GitHandler) HandlePullRequestReviewEvent(body []byte) {
 		return
 	}
 	messageKey := fmt.Sprintf("<%s>", *pullRequest.HTMLURL)
-	slackMessage, err := g.slackConnector.GetMessage(messageKey)
+	slackMessage, err := g.slackConnector.GetMessage(ctx, messageKey)
 	if err != nil {
-		slog.Error("Could not find message", slog.Any("messageKey", messageKey), slog.Any("error", err))
+		slog.ErrorContext(ctx, "Could not find message", slog.Any("messageKey", messageKey), slog.Any("error", err))
 		return
 	}
-	g.slackConnector.AddReactionToMessage(g.emoji.Approve, slackMessage)
+	g.slackConnector.AddReactionToMessage(ctx, g.settings.Load().emoji.Approve, slackMessage)
 }
 
-func (g *GitHandler) HandlePullRequestReviewCommentEvent(body []byte) {
+func (g *GitHandler) HandlePullRequestReviewCommentEvent(ctx context.Context, body []byte) {
+	defer prometheus.NewTimer(metrics.HandlerDuration.WithLabelValues(pullRequestReviewCommentEvent)).ObserveDuration()
+	ctx, span := tracing.Start(ctx, "GitHandler.HandlePullRequestReviewCommentEvent")
+	defer span.End()
+
 	var event gh.PullRequestReviewCommentEvent
 	err := json.Unmarshal(body, &event)
 	if err != nil {
-		slog.Error("Error parsing request body", slog.Any("body", string(body)), slog.Any("error", err))
+		slog.ErrorContext(ctx, "Error parsing request body", slog.Any("body", string(body)), slog.Any("error", err))
 		return
 	}
 	pullRequest := event.PullRequest
+	span.SetAttributes(tracing.Repository.String(event.GetRepo().GetFullName()), tracing.Action.String(event.GetAction()), tracing.PullRequestURL.String(pullRequest.GetHTMLURL()))
+	ctx = logging.With(ctx, slog.String("repo", event.GetRepo().GetFullName()), slog.Int("prNumber", pullRequest.GetNumber()))
 
 	if g.isIgnoredRepo(*event.Repo.Name) {
+		metrics.Filtered(metrics.FilteredIgnoredRepo)
 		return
 	}
 
 	if !g.userService.IsTeamMember(*pullRequest.User.Login) {
+		metrics.Filtered(metrics.FilteredNonTeamAuthor)
 		return
 	}
 
 	if g.userService.IsIgnoredCommentUser(*event.Comment.User.Login) {
+		metrics.Filtered(metrics.FilteredIgnoredUser)
 		return
 	}
 
 	messageKey := fmt.Sprintf("<%s>", *pullRequest.HTMLURL)
-	slackMessage, err := g.slackConnector.GetMessage(messageKey)
+	slackMessage, err := g.slackConnector.GetMessage(ctx, messageKey)
 	if err != nil {
-		slog.Error("Could not find message", slog.Any("messageKey", messageKey), slog.Any("error", err))
+		slog.ErrorContext(ctx, "Could not find message", slog.Any("messageKey", messageKey), slog.Any("error", err))
 		return
 	}
-	g.slackConnector.SendReply(slackMessage, g.messageBuilder.BuildPRCommentMessage(g.userService.GetUserDescriptor(*event.Comment.User.Login), event))
+	g.slackConnector.SendReply(ctx, slackMessage, g.messageBuilder.BuildPRCommentMessage(g.userService.GetUserDescriptor(ctx, *event.Comment.User.Login), event))
 }
 
-func (g *GitHandler) HandleIssueCommentEvent(body []byte) {
+func (g *GitHandler) HandleIssueCommentEvent(ctx context.Context, body []byte) {
+	defer prometheus.NewTimer(metrics.HandlerDuration.WithLabelValues(issueCommentEvent)).ObserveDuration()
+	ctx, span := tracing.Start(ctx, "GitHandler.HandleIssueCommentEvent")
+	defer span.End()
+
 	var event gh.IssueCommentEvent
 	err := json.Unmarshal(body, &event)
 	if err != nil {
-		slog.Error("Error parsing request body", slog.Any("body", string(body)), slog.Any("error", err))
+		slog.ErrorContext(ctx, "Error parsing request body", slog.Any("body", string(body)), slog.Any("error", err))
 		return
 	}
+	span.SetAttributes(tracing.Repository.String(event.GetRepo().GetFullName()), tracing.Action.String(event.GetAction()), tracing.PullRequestURL.String(event.GetIssue().GetHTMLURL()))
+	ctx = logging.With(ctx, slog.String("repo", event.GetRepo().GetFullName()), slog.Int("prNumber", event.GetIssue().GetNumber()))
 
 	if g.isIgnoredRepo(*event.Repo.Name) {
+		metrics.Filtered(metrics.FilteredIgnoredRepo)
 		return
 	}
 
 	if !g.userService.IsTeamMember(*event.Issue.User.Login) {
+		metrics.Filtered(metrics.FilteredNonTeamAuthor)
+		return
+	}
+
+	// Comments posted from a slack thread are already in that thread.
+	if messageBuilder.IsSlackComment(event.Comment.GetBody()) {
+		metrics.Filtered(metrics.FilteredSlackComment)
 		return
 	}
 
 	if g.userService.IsIgnoredCommentUser(*event.Comment.User.Login) {
+		metrics.Filtered(metrics.FilteredIgnoredUser)
 		return
 	}
 
 	messageKey := fmt.Sprintf("<%s>", *event.Issue.HTMLURL)
-	slackMessage, err := g.slackConnector.GetMessage(messageKey)
+	slackMessage, err := g.slackConnector.GetMessage(ctx, messageKey)
 	if err != nil {
-		slog.Error("Could not find message", slog.Any("messageKey", messageKey), slog.Any("error", err))
+		slog.ErrorContext(ctx, "Could not find message", slog.Any("messageKey", messageKey), slog.Any("error", err))
 		return
 	}
-	g.slackConnector.SendReply(slackMessage, g.messageBuilder.BuildIssueCommentMessage(g.userService.GetUserDescriptor(*event.Comment.User.Login), event))
+	g.slackConnector.SendReply(ctx, slackMessage, g.messageBuilder.BuildIssueCommentMessage(g.userService.GetUserDescriptor(ctx, *event.Comment.User.Login), event))
+}
+
+func (g *GitHandler) HandlePushEvent(ctx context.Context, body []byte) {
+	defer prometheus.NewTimer(metrics.HandlerDuration.WithLabelValues(pushEvent)).ObserveDuration()
+	ctx, span := tracing.Start(ctx, "GitHandler.HandlePushEvent")
+	defer span.End()
+
+	if g.conflictChecker == nil {
+		return
+	}
+	var event gh.PushEvent
+	err := json.Unmarshal(body, &event)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error parsing request body", slog.Any("body", string(body)), slog.Any("error", err))
+		return
+	}
+	span.SetAttributes(tracing.Repository.String(event.GetRepo().GetFullName()))
+	ctx = logging.With(ctx, slog.String("repo", event.GetRepo().GetFullName()))
+
+	if g.isIgnoredRepo(event.GetRepo().GetName()) {
+		metrics.Filtered(metrics.FilteredIgnoredRepo)
+		return
+	}
+
+	if event.GetDeleted() || !strings.HasPrefix(event.GetRef(), branchRefPrefix) {
+		return
+	}
+
+	// The checker queues the check, as GitHub needs a while to recompute mergeability after a push.
+	g.conflictChecker.CheckBranch(ctx, event.GetRepo().GetOwner().GetLogin(), event.GetRepo().GetName(), strings.TrimPrefix(event.GetRef(), branchRefPrefix))
 }
 
 func (g *GitHandler) isIgnoredRepo(repoName string) bool {
-	return slices.Contains(g.ignoredRepos, repoName)
+	return slices.Contains(g.settings.Load().ignoredRepos, repoName)
 }
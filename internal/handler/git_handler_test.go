@@ -20,13 +20,20 @@ Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
 package handler_test
 
 import (
+	"context"
 	_ "embed"
+	"encoding/json"
 	"git-slack-bot/internal/config"
+	mock_conflict "git-slack-bot/internal/conflict/mocks"
 	"git-slack-bot/internal/handler"
+	"git-slack-bot/internal/messagebuilder"
+	"git-slack-bot/internal/metrics"
 	mock_slack "git-slack-bot/internal/slack/mocks"
 	mock_user "git-slack-bot/internal/user/mocks"
 
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/slack-go/slack"
 	"go.uber.org/mock/gomock"
 )
@@ -48,6 +55,8 @@ var (
 	prCommentJSONData []byte
 	//go:embed example-requests/pr-top-level-comment.json
 	prIssueCommentJSONData []byte
+	//go:embed example-requests/push.json
+	pushJSONData []byte
 )
 
 var _ = Describe("HandleGitEvents", func() {
@@ -55,6 +64,7 @@ var _ = Describe("HandleGitEvents", func() {
 		mockCtrl          *gomock.Controller
 		slackMock         *mock_slack.MockInteractor
 		userMock          *mock_user.MockService
+		conflictMock      *mock_conflict.MockChecker
 		ignoredReposEmpty []string
 	)
 
@@ -62,184 +72,246 @@ var _ = Describe("HandleGitEvents", func() {
 		mockCtrl = gomock.NewController(GinkgoT())
 		slackMock = mock_slack.NewMockInteractor(mockCtrl)
 		userMock = mock_user.NewMockService(mockCtrl)
+		conflictMock = mock_conflict.NewMockChecker(mockCtrl)
 		ignoredReposEmpty = []string{}
 	})
 
 	Context("HandlePullRequestEvents", func() {
 		It("should no-op if coming from a ignored repo", func() {
-			webHookHandler := handler.NewGitHandler(slackMock, userMock, validEmojis(), []string{"hotels-and-ancillaries"})
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, []string{"hotels-and-ancillaries"})
+			filtered := testutil.ToFloat64(metrics.EventsFiltered.WithLabelValues(metrics.FilteredIgnoredRepo))
 
 			userMock.EXPECT().IsTeamMember(gomock.Any()).Times(0)
-			slackMock.EXPECT().SendMessage(gomock.Any()).Times(0)
-			webHookHandler.HandlePullRequestEvent(prOpenedJSONData)
+			slackMock.EXPECT().Announce(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			webHookHandler.HandlePullRequestEvent(context.Background(), prOpenedJSONData)
+
+			Expect(testutil.ToFloat64(metrics.EventsFiltered.WithLabelValues(metrics.FilteredIgnoredRepo))).To(Equal(filtered + 1))
+		})
+
+		It("should no-op if coming from a repo ignored after a reload", func() {
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, ignoredReposEmpty)
+			webHookHandler.Reload(validEmojis(), []string{"hotels-and-ancillaries"})
+
+			userMock.EXPECT().IsTeamMember(gomock.Any()).Times(0)
+			slackMock.EXPECT().Announce(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			webHookHandler.HandlePullRequestEvent(context.Background(), prOpenedJSONData)
 		})
 
 		It("should post slack message when pull request opened", func() {
-			webHookHandler := handler.NewGitHandler(slackMock, userMock, validEmojis(), ignoredReposEmpty)
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, ignoredReposEmpty)
+
+			userMock.EXPECT().IsTeamMember(gomock.Any()).Return(true)
+			userMock.EXPECT().GetUserDescriptor(gomock.Any(), gomock.Any()).Return("<@123>")
+
+			expected := `<@123> [GS] Test slack id change:
+https://github.com/loveholidays/hotels-and-ancillaries/pull/808`
+
+			slackMock.EXPECT().Announce(gomock.Any(), "https://github.com/loveholidays/hotels-and-ancillaries/pull/808", expected, gomock.Nil())
+			webHookHandler.HandlePullRequestEvent(context.Background(), prOpenedJSONData)
+		})
+
+		It("should post slack message with buttons when pull request opened", func() {
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{ClaimReview: true, OpenDiff: true}, ignoredReposEmpty)
 
 			userMock.EXPECT().IsTeamMember(gomock.Any()).Return(true)
-			userMock.EXPECT().GetUserDescriptor(gomock.Any()).Return("<@123>")
+			userMock.EXPECT().GetUserDescriptor(gomock.Any(), gomock.Any()).Return("<@123>")
 
 			expected := `<@123> [GS] Test slack id change:
 https://github.com/loveholidays/hotels-and-ancillaries/pull/808`
 
-			slackMock.EXPECT().SendMessage(expected)
-			webHookHandler.HandlePullRequestEvent(prOpenedJSONData)
+			slackMock.EXPECT().Announce(gomock.Any(), "https://github.com/loveholidays/hotels-and-ancillaries/pull/808", expected, gomock.Len(2))
+			webHookHandler.HandlePullRequestEvent(context.Background(), prOpenedJSONData)
 		})
 
 		It("should post slack message when pull request ready for review", func() {
-			webHookHandler := handler.NewGitHandler(slackMock, userMock, validEmojis(), ignoredReposEmpty)
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, ignoredReposEmpty)
 
 			userMock.EXPECT().IsTeamMember(gomock.Any()).Return(true)
-			userMock.EXPECT().GetUserDescriptor(gomock.Any()).Return("<@123>")
+			userMock.EXPECT().GetUserDescriptor(gomock.Any(), gomock.Any()).Return("<@123>")
 
 			expected := `<@123> Moving duplicating configmaps to base:
 https://github.com/loveholidays/flux/pull/92504`
 
-			slackMock.EXPECT().SendMessage(expected)
-			webHookHandler.HandlePullRequestEvent(prReadyForReviewJSONData)
+			slackMock.EXPECT().Announce(gomock.Any(), "https://github.com/loveholidays/flux/pull/92504", expected, gomock.Nil())
+			webHookHandler.HandlePullRequestEvent(context.Background(), prReadyForReviewJSONData)
 		})
 
 		It("should add merged emoji to message when pull request merged", func() {
-			webHookHandler := handler.NewGitHandler(slackMock, userMock, validEmojis(), ignoredReposEmpty)
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, ignoredReposEmpty)
 
 			userMock.EXPECT().IsTeamMember(gomock.Any()).Return(true)
 			messageKey := &slack.Message{}
-			slackMock.EXPECT().GetMessage(gomock.Any()).Return(messageKey, nil)
+			slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(messageKey, nil)
 
-			slackMock.EXPECT().AddReactionToMessage("merged", messageKey)
-			webHookHandler.HandlePullRequestEvent(prMergedJSONData)
+			slackMock.EXPECT().AddReactionToMessage(gomock.Any(), "merged", messageKey)
+			webHookHandler.HandlePullRequestEvent(context.Background(), prMergedJSONData)
 		})
 
 		It("should add closed emoji when pull request closed", func() {
-			webHookHandler := handler.NewGitHandler(slackMock, userMock, validEmojis(), ignoredReposEmpty)
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, ignoredReposEmpty)
 
 			userMock.EXPECT().IsTeamMember(gomock.Any()).Return(true)
 			messageKey := &slack.Message{}
-			slackMock.EXPECT().GetMessage(gomock.Any()).Return(messageKey, nil)
+			slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(messageKey, nil)
 
-			slackMock.EXPECT().AddReactionToMessage("x", messageKey)
-			webHookHandler.HandlePullRequestEvent(prClosedJSONData)
+			slackMock.EXPECT().AddReactionToMessage(gomock.Any(), "x", messageKey)
+			webHookHandler.HandlePullRequestEvent(context.Background(), prClosedJSONData)
 		})
 
 		It("should remove closed emoji when pull request reopened", func() {
-			webHookHandler := handler.NewGitHandler(slackMock, userMock, validEmojis(), ignoredReposEmpty)
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, ignoredReposEmpty)
 
 			userMock.EXPECT().IsTeamMember(gomock.Any()).Return(true)
 			messageKey := &slack.Message{}
-			slackMock.EXPECT().GetMessage(gomock.Any()).Return(messageKey, nil)
+			slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(messageKey, nil)
 
-			slackMock.EXPECT().RemoveReactionFromMessage("x", messageKey)
-			webHookHandler.HandlePullRequestEvent(prReopenedJSONData)
+			slackMock.EXPECT().RemoveReactionFromMessage(gomock.Any(), "x", messageKey)
+			webHookHandler.HandlePullRequestEvent(context.Background(), prReopenedJSONData)
 		})
 	})
 
 	Context("HandlePullRequestReviewEvent", func() {
 		It("should no-op if coming from a ignored repo", func() {
-			webHookHandler := handler.NewGitHandler(slackMock, userMock, validEmojis(), []string{"frontier"})
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, []string{"frontier"})
 
 			userMock.EXPECT().IsTeamMember(gomock.Any()).Times(0)
-			slackMock.EXPECT().SendMessage(gomock.Any()).Times(0)
-			webHookHandler.HandlePullRequestReviewEvent(prApprovedJSONData)
+			slackMock.EXPECT().Announce(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			webHookHandler.HandlePullRequestReviewEvent(context.Background(), prApprovedJSONData)
 		})
 
 		It("should add tick emoji when pull request approved", func() {
-			webHookHandler := handler.NewGitHandler(slackMock, userMock, validEmojis(), ignoredReposEmpty)
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, ignoredReposEmpty)
 
 			userMock.EXPECT().IsTeamMember(gomock.Any()).Return(true)
 			userMock.EXPECT().IsIgnoredReviewUser(gomock.Any()).Return(false)
 			messageKey := &slack.Message{}
-			slackMock.EXPECT().GetMessage(gomock.Any()).Return(messageKey, nil)
+			slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(messageKey, nil)
 
-			slackMock.EXPECT().AddReactionToMessage("+1", messageKey)
-			webHookHandler.HandlePullRequestReviewEvent(prApprovedJSONData)
+			slackMock.EXPECT().AddReactionToMessage(gomock.Any(), "+1", messageKey)
+			webHookHandler.HandlePullRequestReviewEvent(context.Background(), prApprovedJSONData)
 		})
 
 		It("should not add tick emoji when pull request reviewer is ignored", func() {
-			webHookHandler := handler.NewGitHandler(slackMock, userMock, validEmojis(), ignoredReposEmpty)
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, ignoredReposEmpty)
 
 			userMock.EXPECT().IsTeamMember(gomock.Any()).Return(true)
 			userMock.EXPECT().IsIgnoredReviewUser(gomock.Any()).Return(true)
-			slackMock.EXPECT().GetMessage(gomock.Any()).Times(0)
-			slackMock.EXPECT().AddReactionToMessage(gomock.Any(), gomock.Any()).Times(0)
+			slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Times(0)
+			slackMock.EXPECT().AddReactionToMessage(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 
-			webHookHandler.HandlePullRequestReviewEvent(prApprovedJSONData)
+			webHookHandler.HandlePullRequestReviewEvent(context.Background(), prApprovedJSONData)
 		})
 	})
 
 	Context("HandlePullRequestReviewCommentEvent", func() {
 		It("should no-op if coming from a ignored repo", func() {
-			webHookHandler := handler.NewGitHandler(slackMock, userMock, validEmojis(), []string{"yielding-ui"})
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, []string{"yielding-ui"})
 
 			userMock.EXPECT().IsTeamMember(gomock.Any()).Times(0)
-			slackMock.EXPECT().SendMessage(gomock.Any()).Times(0)
-			webHookHandler.HandlePullRequestReviewCommentEvent(prCommentJSONData)
+			slackMock.EXPECT().Announce(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			webHookHandler.HandlePullRequestReviewCommentEvent(context.Background(), prCommentJSONData)
 		})
 
 		It("should post comment to slack as a reply when pull request commented on", func() {
-			webHookHandler := handler.NewGitHandler(slackMock, userMock, validEmojis(), ignoredReposEmpty)
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, ignoredReposEmpty)
 
 			userMock.EXPECT().IsTeamMember(gomock.Any()).Return(true)
-			userMock.EXPECT().GetUserDescriptor(gomock.Any()).Return("<@123>")
+			userMock.EXPECT().GetUserDescriptor(gomock.Any(), gomock.Any()).Return("<@123>")
 			userMock.EXPECT().IsIgnoredCommentUser("szmglh").Return(false)
 			messageKey := &slack.Message{}
-			slackMock.EXPECT().GetMessage(gomock.Any()).Return(messageKey, nil)
+			slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(messageKey, nil)
 
 			expected := `<@123> left a <https://github.com/loveholidays/yielding-ui/pull/61#discussion_r1425573584|comment>:
 > @L1 Dockerfile
 Sorry, that's not allowed since https://adrs.lvh.systems/adr/20231205-container-image-tagging/`
 
-			slackMock.EXPECT().SendReply(messageKey, expected)
-			webHookHandler.HandlePullRequestReviewCommentEvent(prCommentJSONData)
+			slackMock.EXPECT().SendReply(gomock.Any(), messageKey, expected)
+			webHookHandler.HandlePullRequestReviewCommentEvent(context.Background(), prCommentJSONData)
 		})
 
 		It("should ignore pull request commented on from ignored comment user", func() {
-			webHookHandler := handler.NewGitHandler(slackMock, userMock, validEmojis(), ignoredReposEmpty)
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, ignoredReposEmpty)
 
 			userMock.EXPECT().IsTeamMember(gomock.Any()).Return(true)
 			userMock.EXPECT().IsIgnoredCommentUser(gomock.Any()).Return(true)
 
-			slackMock.EXPECT().GetMessage(gomock.Any()).MaxTimes(0)
-			slackMock.EXPECT().SendReply(gomock.Any(), gomock.Any()).MaxTimes(0)
+			slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).MaxTimes(0)
+			slackMock.EXPECT().SendReply(gomock.Any(), gomock.Any(), gomock.Any()).MaxTimes(0)
 
-			webHookHandler.HandlePullRequestReviewCommentEvent(prCommentJSONData)
+			webHookHandler.HandlePullRequestReviewCommentEvent(context.Background(), prCommentJSONData)
 		})
 	})
 
 	Context("HandleIssueCommentEvent", func() {
 		It("should no-op if coming from a ignored repo", func() {
-			webHookHandler := handler.NewGitHandler(slackMock, userMock, validEmojis(), []string{"hotels-and-ancillaries"})
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, []string{"hotels-and-ancillaries"})
 
 			userMock.EXPECT().IsTeamMember(gomock.Any()).Times(0)
-			slackMock.EXPECT().SendMessage(gomock.Any()).Times(0)
-			webHookHandler.HandleIssueCommentEvent(prIssueCommentJSONData)
+			slackMock.EXPECT().Announce(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			webHookHandler.HandleIssueCommentEvent(context.Background(), prIssueCommentJSONData)
 		})
 
 		It("should post comment to slack as a reply when top level pull request comment is added to PR", func() {
-			webHookHandler := handler.NewGitHandler(slackMock, userMock, validEmojis(), ignoredReposEmpty)
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, ignoredReposEmpty)
 
 			userMock.EXPECT().IsTeamMember(gomock.Any()).Return(true)
-			userMock.EXPECT().GetUserDescriptor(gomock.Any()).Return("<@123>")
+			userMock.EXPECT().GetUserDescriptor(gomock.Any(), gomock.Any()).Return("<@123>")
 			userMock.EXPECT().IsIgnoredCommentUser("georgesmith96").Return(false)
 			messageKey := &slack.Message{}
-			slackMock.EXPECT().GetMessage(gomock.Any()).Return(messageKey, nil)
+			slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(messageKey, nil)
 
 			expected := `<@123> left a <https://github.com/loveholidays/hotels-and-ancillaries/pull/1015#issuecomment-1924011855|comment>:
 Just leaving a top level comment here`
 
-			slackMock.EXPECT().SendReply(messageKey, expected)
-			webHookHandler.HandleIssueCommentEvent(prIssueCommentJSONData)
+			slackMock.EXPECT().SendReply(gomock.Any(), messageKey, expected)
+			webHookHandler.HandleIssueCommentEvent(context.Background(), prIssueCommentJSONData)
 		})
 
 		It("should ignore top level pull request comment added to PR from ignored comment user", func() {
-			webHookHandler := handler.NewGitHandler(slackMock, userMock, validEmojis(), ignoredReposEmpty)
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, ignoredReposEmpty)
 
 			userMock.EXPECT().IsTeamMember(gomock.Any()).Return(true)
 			userMock.EXPECT().IsIgnoredCommentUser("georgesmith96").Return(true)
-			slackMock.EXPECT().GetMessage(gomock.Any()).MaxTimes(0)
+			slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).MaxTimes(0)
+
+			slackMock.EXPECT().SendReply(gomock.Any(), gomock.Any(), gomock.Any()).MaxTimes(0)
+			webHookHandler.HandleIssueCommentEvent(context.Background(), prIssueCommentJSONData)
+		})
+
+		It("should ignore top level pull request comment posted from slack", func() {
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, ignoredReposEmpty)
+			var event map[string]any
+			Expect(json.Unmarshal(prIssueCommentJSONData, &event)).To(Succeed())
+			event["comment"].(map[string]any)["body"] = "LGTM\n\n_via Slack by @george_\n" + messagebuilder.SlackCommentMarker
+			body, err := json.Marshal(event)
+			Expect(err).ToNot(HaveOccurred())
+
+			userMock.EXPECT().IsTeamMember(gomock.Any()).Return(true)
+			slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Times(0)
+			slackMock.EXPECT().SendReply(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			webHookHandler.HandleIssueCommentEvent(context.Background(), body)
+		})
+	})
+
+	Context("HandlePushEvent", func() {
+		It("should check pull requests of the pushed branch", func() {
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, conflictMock, validEmojis(), messagebuilder.PRActions{}, ignoredReposEmpty)
+
+			conflictMock.EXPECT().CheckBranch(gomock.Any(), "loveholidays", "hotels-and-ancillaries", "main")
+			webHookHandler.HandlePushEvent(context.Background(), pushJSONData)
+		})
+
+		It("should no-op if coming from a ignored repo", func() {
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, conflictMock, validEmojis(), messagebuilder.PRActions{}, []string{"hotels-and-ancillaries"})
+
+			conflictMock.EXPECT().CheckBranch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			webHookHandler.HandlePushEvent(context.Background(), pushJSONData)
+		})
+
+		It("should no-op if conflict detection is disabled", func() {
+			webHookHandler := handler.NewGitHandler(slackMock, userMock, nil, validEmojis(), messagebuilder.PRActions{}, ignoredReposEmpty)
 
-			slackMock.EXPECT().SendReply(gomock.Any(), gomock.Any()).MaxTimes(0)
-			webHookHandler.HandleIssueCommentEvent(prIssueCommentJSONData)
+			Expect(func() { webHookHandler.HandlePushEvent(context.Background(), pushJSONData) }).ToNot(Panic())
 		})
 	})
 })
@@ -20,10 +20,26 @@ Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
 package handler
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"git-slack-bot/internal/logging"
+	"git-slack-bot/internal/metrics"
+	"git-slack-bot/internal/secret"
+	"git-slack-bot/internal/tracing"
+	"io"
 	"log/slog"
+	"mime"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
 
-	gh "github.com/google/go-github/v56/github"
+	"go.opentelemetry.io/otel/codes"
 )
 
 type GithubPullRequestAction string
@@ -35,43 +51,174 @@ const (
 	pullRequestReviewEvent        string = "pull_request_review"
 	pullRequestReviewCommentEvent string = "pull_request_review_comment"
 	issueCommentEvent             string = "issue_comment"
+	pushEvent                     string = "push"
+
+	signatureHeader = "X-Hub-Signature-256"
 )
 
+// WebhookHandler receives github webhooks, which must be signed with one of its secrets.
 type WebhookHandler struct {
-	secretKey  []byte
-	gitHandler GitEventHandler
+	secretKeys        atomic.Pointer[[]*secret.Secret]
+	gitHandler        GitEventHandler
+	allowList         *AllowList
+	trustForwardedFor bool
+}
+
+// NewWebhookEventHandler creates a WebhookHandler accepting webhooks signed with any of secretKeys.
+func NewWebhookEventHandler(secretKeys []*secret.Secret, gitHandler GitEventHandler) *WebhookHandler {
+	return NewWebhookEventHandlerWithLimits(secretKeys, gitHandler, nil, false)
 }
 
-func NewWebhookEventHandler(secretKey []byte, gitHandler GitEventHandler) *WebhookHandler {
-	return &WebhookHandler{
-		secretKey:  secretKey,
-		gitHandler: gitHandler,
+// NewWebhookEventHandlerWithLimits creates a WebhookHandler which also rejects webhooks from outside allowList, unless
+// it is nil. With trustForwardedFor the source address is taken from the X-Forwarded-For header set by a proxy in
+// front of the bot. Payloads over the server's limit on request bodies are rejected as too large.
+func NewWebhookEventHandlerWithLimits(secretKeys []*secret.Secret, gitHandler GitEventHandler, allowList *AllowList, trustForwardedFor bool) *WebhookHandler {
+	h := &WebhookHandler{
+		gitHandler:        gitHandler,
+		allowList:         allowList,
+		trustForwardedFor: trustForwardedFor,
 	}
+	h.Reload(secretKeys)
+	return h
 }
 
-func (h *WebhookHandler) HandleHeathCheck(w http.ResponseWriter, _ *http.Request) {
-	w.WriteHeader(http.StatusOK)
+// Reload replaces the secrets webhooks may be signed with. Rotated values of the secrets are used without a reload.
+func (h *WebhookHandler) Reload(secretKeys []*secret.Secret) {
+	h.secretKeys.Store(&secretKeys)
 }
 
 func (h *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
-	body, err := gh.ValidatePayload(r, h.secretKey)
+	ctx, span := tracing.Start(r.Context(), "HandleWebhook", tracing.DeliveryID.String(r.Header.Get("X-GitHub-Delivery")), tracing.Event.String(r.Header.Get("X-GitHub-Event")))
+	defer span.End()
+	ctx = logging.With(ctx, slog.String("deliveryID", r.Header.Get("X-GitHub-Delivery")), slog.String("event", r.Header.Get("X-GitHub-Event")))
+
+	if h.allowList != nil {
+		addr, err := sourceAddr(r, h.trustForwardedFor)
+		if err != nil || !h.allowList.Contains(addr) {
+			slog.ErrorContext(ctx, "Webhook from outside the allowed addresses", slog.String("remoteAddr", r.RemoteAddr), slog.String("forwardedFor", r.Header.Get("X-Forwarded-For")))
+			span.SetStatus(codes.Error, "address not allowed")
+			metrics.WebhooksRejected.WithLabelValues(metrics.RejectedAddress).Inc()
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+	}
+
+	body, err := io.ReadAll(r.Body)
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		slog.ErrorContext(ctx, "Webhook body too large", slog.Int64("limit", maxBytesError.Limit))
+		span.SetStatus(codes.Error, "body too large")
+		metrics.WebhooksRejected.WithLabelValues(metrics.RejectedTooLarge).Inc()
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
 	if err != nil {
-		slog.Error("Error validating message", slog.Any("error", err))
-		http.Error(w, "Error reading request body", http.StatusInternalServerError)
-		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(ctx, "Error reading webhook body", slog.Any("error", err))
+		span.SetStatus(codes.Error, "unreadable body")
+		metrics.WebhooksRejected.WithLabelValues(metrics.RejectedMalformed).Inc()
+		http.Error(w, "Error reading request body", http.StatusBadRequest)
 		return
 	}
-	slog.Debug("webhook", slog.String("github-event", r.Header.Get("X-GitHub-Event")), slog.String("body", string(body)))
 
-	switch r.Header.Get("X-GitHub-Event") {
+	if !h.validSignature(r.Header.Get(signatureHeader), body) {
+		slog.ErrorContext(ctx, "Webhook signature does not match any secret")
+		span.SetStatus(codes.Error, "invalid signature")
+		metrics.SignatureFailures.Inc()
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	payload, err := webhookPayload(r.Header.Get("Content-Type"), body)
+	if err != nil {
+		slog.ErrorContext(ctx, "Malformed webhook payload", slog.Any("error", err))
+		span.SetStatus(codes.Error, "malformed payload")
+		metrics.WebhooksRejected.WithLabelValues(metrics.RejectedMalformed).Inc()
+		http.Error(w, "Malformed payload", http.StatusBadRequest)
+		return
+	}
+	slog.DebugContext(ctx, "webhook", slog.String("body", string(payload)))
+
+	metrics.WebhooksReceived.WithLabelValues(r.Header.Get("X-GitHub-Event"), webhookAction(payload)).Inc()
+
+	Dispatch(ctx, h.gitHandler, r.Header.Get("X-GitHub-Event"), payload)
+	w.WriteHeader(http.StatusOK)
+}
+
+// validSignature reports whether signature is the sha256 HMAC of body with one of the secrets. The sha1 signature
+// GitHub sends alongside is not accepted.
+func (h *WebhookHandler) validSignature(signature string, body []byte) bool {
+	hexMAC, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	signatureMAC, err := hex.DecodeString(hexMAC)
+	if err != nil {
+		return false
+	}
+	for _, secretKey := range *h.secretKeys.Load() {
+		mac := hmac.New(sha256.New, []byte(secretKey.Value()))
+		mac.Write(body)
+		if hmac.Equal(mac.Sum(nil), signatureMAC) {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookPayload returns the json payload of a webhook, which is either the body itself or its payload form field,
+// depending on the content type picked for the webhook.
+func webhookPayload(contentType string, body []byte) ([]byte, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	var payload []byte
+	switch mediaType {
+	case "application/json":
+		payload = body
+	case "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, err
+		}
+		payload = []byte(form.Get("payload"))
+	default:
+		return nil, fmt.Errorf("unsupported content type %q", mediaType)
+	}
+	if !json.Valid(payload) {
+		return nil, errors.New("payload is not valid json")
+	}
+	return payload, nil
+}
+
+// Dispatch passes the payload of a github event to the matching method of gitHandler. It reports false for event
+// types the bot does not handle.
+func Dispatch(ctx context.Context, gitHandler GitEventHandler, event string, body []byte) bool {
+	switch event {
 	case pullRequestEvent:
-		h.gitHandler.HandlePullRequestEvent(body)
+		gitHandler.HandlePullRequestEvent(ctx, body)
 	case pullRequestReviewEvent:
-		h.gitHandler.HandlePullRequestReviewEvent(body)
+		gitHandler.HandlePullRequestReviewEvent(ctx, body)
 	case pullRequestReviewCommentEvent:
-		h.gitHandler.HandlePullRequestReviewCommentEvent(body)
+		gitHandler.HandlePullRequestReviewCommentEvent(ctx, body)
 	case issueCommentEvent:
-		h.gitHandler.HandleIssueCommentEvent(body)
+		gitHandler.HandleIssueCommentEvent(ctx, body)
+	case pushEvent:
+		gitHandler.HandlePushEvent(ctx, body)
+	default:
+		return false
 	}
-	w.WriteHeader(http.StatusOK)
+	return true
+}
+
+// webhookAction returns the action of the event, empty for events without one, such as pushes.
+func webhookAction(body []byte) string {
+	var payload struct {
+		Action string `json:"action"`
+	}
+	err := json.Unmarshal(body, &payload)
+	if err != nil {
+		return ""
+	}
+	return payload.Action
 }
@@ -21,12 +21,22 @@ package handler_test
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // Only used to sign a webhook the way GitHub's deprecated signature does.
+	"crypto/sha256"
+	"encoding/hex"
 	"git-slack-bot/internal/handler"
 	mock_handler "git-slack-bot/internal/handler/mocks"
+	"git-slack-bot/internal/metrics"
+	"git-slack-bot/internal/secret"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"go.uber.org/mock/gomock"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -41,6 +51,7 @@ func TestHandler(t *testing.T) {
 var _ = Describe("HandleWebhook", func() {
 	var (
 		gitHandlerMock *mock_handler.MockGitEventHandler
+		secretKeys     = []*secret.Secret{secret.Fixed("It's a Secret to Everybody")}
 	)
 
 	BeforeEach(func() {
@@ -48,14 +59,14 @@ var _ = Describe("HandleWebhook", func() {
 	})
 
 	It("should handle pull request event", func() {
-		webhookHandler := handler.NewWebhookEventHandler([]byte("It's a Secret to Everybody"), gitHandlerMock)
+		webhookHandler := handler.NewWebhookEventHandler(secretKeys, gitHandlerMock)
 
 		headers := http.Header{}
 		headers.Add("Content-Type", "application/json")
-		headers.Add("X-Hub-Signature", "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17")
+		headers.Add("X-Hub-Signature-256", "sha256=5ccdd8275f57d608741ad8390e42e8696ebfcd85607d9c2ef890769dda8f7568")
 		headers.Add("X-Github-Event", "pull_request")
 
-		body := []byte("Hello, World!")
+		body := []byte(`{"action":"opened"}`)
 
 		request, err := http.NewRequest(http.MethodPost, "process-git-event", bytes.NewReader(body))
 		request.Header = headers
@@ -63,10 +74,10 @@ var _ = Describe("HandleWebhook", func() {
 
 		writer := httptest.NewRecorder()
 
-		gitHandlerMock.EXPECT().HandlePullRequestEvent(body).Times(1)
-		gitHandlerMock.EXPECT().HandlePullRequestReviewEvent(body).Times(0)
-		gitHandlerMock.EXPECT().HandlePullRequestReviewCommentEvent(body).Times(0)
-		gitHandlerMock.EXPECT().HandleIssueCommentEvent(body).Times(0)
+		gitHandlerMock.EXPECT().HandlePullRequestEvent(gomock.Any(), body).Times(1)
+		gitHandlerMock.EXPECT().HandlePullRequestReviewEvent(gomock.Any(), body).Times(0)
+		gitHandlerMock.EXPECT().HandlePullRequestReviewCommentEvent(gomock.Any(), body).Times(0)
+		gitHandlerMock.EXPECT().HandleIssueCommentEvent(gomock.Any(), body).Times(0)
 
 		webhookHandler.HandleWebhook(writer, request)
 
@@ -74,14 +85,14 @@ var _ = Describe("HandleWebhook", func() {
 	})
 
 	It("should handle pull request review event", func() {
-		webhookHandler := handler.NewWebhookEventHandler([]byte("It's a Secret to Everybody"), gitHandlerMock)
+		webhookHandler := handler.NewWebhookEventHandler(secretKeys, gitHandlerMock)
 
 		headers := http.Header{}
 		headers.Add("Content-Type", "application/json")
-		headers.Add("X-Hub-Signature", "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17")
+		headers.Add("X-Hub-Signature-256", "sha256=5ccdd8275f57d608741ad8390e42e8696ebfcd85607d9c2ef890769dda8f7568")
 		headers.Add("X-Github-Event", "pull_request_review")
 
-		body := []byte("Hello, World!")
+		body := []byte(`{"action":"opened"}`)
 
 		request, err := http.NewRequest(http.MethodPost, "process-git-event", bytes.NewReader(body))
 		request.Header = headers
@@ -89,10 +100,10 @@ var _ = Describe("HandleWebhook", func() {
 
 		writer := httptest.NewRecorder()
 
-		gitHandlerMock.EXPECT().HandlePullRequestEvent(body).Times(0)
-		gitHandlerMock.EXPECT().HandlePullRequestReviewEvent(body).Times(1)
-		gitHandlerMock.EXPECT().HandlePullRequestReviewCommentEvent(body).Times(0)
-		gitHandlerMock.EXPECT().HandleIssueCommentEvent(body).Times(0)
+		gitHandlerMock.EXPECT().HandlePullRequestEvent(gomock.Any(), body).Times(0)
+		gitHandlerMock.EXPECT().HandlePullRequestReviewEvent(gomock.Any(), body).Times(1)
+		gitHandlerMock.EXPECT().HandlePullRequestReviewCommentEvent(gomock.Any(), body).Times(0)
+		gitHandlerMock.EXPECT().HandleIssueCommentEvent(gomock.Any(), body).Times(0)
 
 		webhookHandler.HandleWebhook(writer, request)
 
@@ -100,14 +111,14 @@ var _ = Describe("HandleWebhook", func() {
 	})
 
 	It("should handle pull request review comment event", func() {
-		webhookHandler := handler.NewWebhookEventHandler([]byte("It's a Secret to Everybody"), gitHandlerMock)
+		webhookHandler := handler.NewWebhookEventHandler(secretKeys, gitHandlerMock)
 
 		headers := http.Header{}
 		headers.Add("Content-Type", "application/json")
-		headers.Add("X-Hub-Signature", "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17")
+		headers.Add("X-Hub-Signature-256", "sha256=5ccdd8275f57d608741ad8390e42e8696ebfcd85607d9c2ef890769dda8f7568")
 		headers.Add("X-Github-Event", "pull_request_review_comment")
 
-		body := []byte("Hello, World!")
+		body := []byte(`{"action":"opened"}`)
 
 		request, err := http.NewRequest(http.MethodPost, "process-git-event", bytes.NewReader(body))
 		request.Header = headers
@@ -115,10 +126,10 @@ var _ = Describe("HandleWebhook", func() {
 
 		writer := httptest.NewRecorder()
 
-		gitHandlerMock.EXPECT().HandlePullRequestEvent(body).Times(0)
-		gitHandlerMock.EXPECT().HandlePullRequestReviewEvent(body).Times(0)
-		gitHandlerMock.EXPECT().HandlePullRequestReviewCommentEvent(body).Times(1)
-		gitHandlerMock.EXPECT().HandleIssueCommentEvent(body).Times(0)
+		gitHandlerMock.EXPECT().HandlePullRequestEvent(gomock.Any(), body).Times(0)
+		gitHandlerMock.EXPECT().HandlePullRequestReviewEvent(gomock.Any(), body).Times(0)
+		gitHandlerMock.EXPECT().HandlePullRequestReviewCommentEvent(gomock.Any(), body).Times(1)
+		gitHandlerMock.EXPECT().HandleIssueCommentEvent(gomock.Any(), body).Times(0)
 
 		webhookHandler.HandleWebhook(writer, request)
 
@@ -126,14 +137,14 @@ var _ = Describe("HandleWebhook", func() {
 	})
 
 	It("should handle issue comment event", func() {
-		webhookHandler := handler.NewWebhookEventHandler([]byte("It's a Secret to Everybody"), gitHandlerMock)
+		webhookHandler := handler.NewWebhookEventHandler(secretKeys, gitHandlerMock)
 
 		headers := http.Header{}
 		headers.Add("Content-Type", "application/json")
-		headers.Add("X-Hub-Signature", "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17")
+		headers.Add("X-Hub-Signature-256", "sha256=5ccdd8275f57d608741ad8390e42e8696ebfcd85607d9c2ef890769dda8f7568")
 		headers.Add("X-Github-Event", "issue_comment")
 
-		body := []byte("Hello, World!")
+		body := []byte(`{"action":"opened"}`)
 
 		request, err := http.NewRequest(http.MethodPost, "process-git-event", bytes.NewReader(body))
 		request.Header = headers
@@ -141,13 +152,193 @@ var _ = Describe("HandleWebhook", func() {
 
 		writer := httptest.NewRecorder()
 
-		gitHandlerMock.EXPECT().HandlePullRequestEvent(body).Times(0)
-		gitHandlerMock.EXPECT().HandlePullRequestReviewEvent(body).Times(0)
-		gitHandlerMock.EXPECT().HandlePullRequestReviewCommentEvent(body).Times(0)
-		gitHandlerMock.EXPECT().HandleIssueCommentEvent(body).Times(1)
+		gitHandlerMock.EXPECT().HandlePullRequestEvent(gomock.Any(), body).Times(0)
+		gitHandlerMock.EXPECT().HandlePullRequestReviewEvent(gomock.Any(), body).Times(0)
+		gitHandlerMock.EXPECT().HandlePullRequestReviewCommentEvent(gomock.Any(), body).Times(0)
+		gitHandlerMock.EXPECT().HandleIssueCommentEvent(gomock.Any(), body).Times(1)
 
 		webhookHandler.HandleWebhook(writer, request)
 
 		Expect(writer.Code).To(Equal(http.StatusOK))
 	})
+
+	It("should handle push event", func() {
+		webhookHandler := handler.NewWebhookEventHandler(secretKeys, gitHandlerMock)
+
+		headers := http.Header{}
+		headers.Add("Content-Type", "application/json")
+		headers.Add("X-Hub-Signature-256", "sha256=5ccdd8275f57d608741ad8390e42e8696ebfcd85607d9c2ef890769dda8f7568")
+		headers.Add("X-Github-Event", "push")
+
+		body := []byte(`{"action":"opened"}`)
+
+		request, err := http.NewRequest(http.MethodPost, "process-git-event", bytes.NewReader(body))
+		request.Header = headers
+		Expect(err).ToNot(HaveOccurred())
+
+		writer := httptest.NewRecorder()
+
+		gitHandlerMock.EXPECT().HandlePullRequestEvent(gomock.Any(), body).Times(0)
+		gitHandlerMock.EXPECT().HandlePushEvent(gomock.Any(), body).Times(1)
+
+		webhookHandler.HandleWebhook(writer, request)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+	})
+
+	It("should count webhooks with an invalid signature", func() {
+		webhookHandler := handler.NewWebhookEventHandler(secretKeys, gitHandlerMock)
+
+		request, err := http.NewRequest(http.MethodPost, "process-git-event", bytes.NewReader([]byte(`{"action":"opened"}`)))
+		Expect(err).ToNot(HaveOccurred())
+		request.Header.Add("Content-Type", "application/json")
+		request.Header.Add("X-Hub-Signature-256", "sha256=0000000000000000000000000000000000000000000000000000000000000000")
+		request.Header.Add("X-Github-Event", "push")
+		failures := testutil.ToFloat64(metrics.SignatureFailures)
+
+		writer := httptest.NewRecorder()
+
+		webhookHandler.HandleWebhook(writer, request)
+
+		Expect(writer.Code).To(Equal(http.StatusUnauthorized))
+		Expect(testutil.ToFloat64(metrics.SignatureFailures)).To(Equal(failures + 1))
+	})
+
+	It("should accept webhooks signed with any of the secrets", func() {
+		webhookHandler := handler.NewWebhookEventHandler([]*secret.Secret{secret.Fixed("new secret"), secret.Fixed("old secret")}, gitHandlerMock)
+		body := []byte(`{"action":"opened"}`)
+
+		gitHandlerMock.EXPECT().HandlePullRequestEvent(gomock.Any(), body).Times(2)
+
+		for _, secret := range []string{"new secret", "old secret"} {
+			writer := httptest.NewRecorder()
+			webhookHandler.HandleWebhook(writer, signedRequest("pull_request", "application/json", body, secret))
+			Expect(writer.Code).To(Equal(http.StatusOK))
+		}
+	})
+
+	It("should reject webhooks signed with a secret removed by a reload", func() {
+		webhookHandler := handler.NewWebhookEventHandler([]*secret.Secret{secret.Fixed("old secret")}, gitHandlerMock)
+		webhookHandler.Reload([]*secret.Secret{secret.Fixed("new secret")})
+		writer := httptest.NewRecorder()
+
+		webhookHandler.HandleWebhook(writer, signedRequest("pull_request", "application/json", []byte(`{}`), "old secret"))
+
+		Expect(writer.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should reject sha1 signatures", func() {
+		webhookHandler := handler.NewWebhookEventHandler(secretKeys, gitHandlerMock)
+		body := []byte(`{"action":"opened"}`)
+		mac := hmac.New(sha1.New, []byte(secretKeys[0].Value()))
+		mac.Write(body)
+		request := httptest.NewRequest(http.MethodPost, "/git-event", bytes.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("X-Hub-Signature", "sha1="+hex.EncodeToString(mac.Sum(nil)))
+		request.Header.Set("X-Github-Event", "pull_request")
+		writer := httptest.NewRecorder()
+
+		webhookHandler.HandleWebhook(writer, request)
+
+		Expect(writer.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should reject malformed payloads", func() {
+		webhookHandler := handler.NewWebhookEventHandler(secretKeys, gitHandlerMock)
+		malformed := testutil.ToFloat64(metrics.WebhooksRejected.WithLabelValues(metrics.RejectedMalformed))
+
+		for _, request := range []*http.Request{
+			signedRequest("push", "application/json", []byte("Hello, World!"), "It's a Secret to Everybody"),
+			signedRequest("push", "text/plain", []byte(`{}`), "It's a Secret to Everybody"),
+			signedRequest("push", "application/x-www-form-urlencoded", []byte("other=value"), "It's a Secret to Everybody"),
+		} {
+			writer := httptest.NewRecorder()
+			webhookHandler.HandleWebhook(writer, request)
+			Expect(writer.Code).To(Equal(http.StatusBadRequest))
+		}
+		Expect(testutil.ToFloat64(metrics.WebhooksRejected.WithLabelValues(metrics.RejectedMalformed))).To(Equal(malformed + 3))
+	})
+
+	It("should handle form encoded payloads", func() {
+		webhookHandler := handler.NewWebhookEventHandler(secretKeys, gitHandlerMock)
+		body := []byte(url.Values{"payload": {`{"action":"opened"}`}}.Encode())
+		writer := httptest.NewRecorder()
+
+		gitHandlerMock.EXPECT().HandlePullRequestEvent(gomock.Any(), []byte(`{"action":"opened"}`))
+
+		webhookHandler.HandleWebhook(writer, signedRequest("pull_request", "application/x-www-form-urlencoded", body, "It's a Secret to Everybody"))
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+	})
+
+	It("should reject payloads over the server's limit", func() {
+		webhookHandler := handler.NewWebhookEventHandler(secretKeys, gitHandlerMock)
+		writer := httptest.NewRecorder()
+		failures := testutil.ToFloat64(metrics.SignatureFailures)
+
+		http.MaxBytesHandler(http.HandlerFunc(webhookHandler.HandleWebhook), 5).ServeHTTP(writer, signedRequest("push", "application/json", []byte(`{"action":"opened"}`), "It's a Secret to Everybody"))
+
+		Expect(writer.Code).To(Equal(http.StatusRequestEntityTooLarge))
+		Expect(testutil.ToFloat64(metrics.SignatureFailures)).To(Equal(failures))
+	})
+
+	Context("with an allow list", func() {
+		var allowList *handler.AllowList
+
+		BeforeEach(func() {
+			path := filepath.Join(GinkgoT().TempDir(), "hooks")
+			Expect(os.WriteFile(path, []byte("192.30.252.0/22\n"), 0o600)).To(Succeed())
+			var err error
+			allowList, err = handler.LoadAllowList(path)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should accept webhooks from the allowed addresses", func() {
+			webhookHandler := handler.NewWebhookEventHandlerWithLimits(secretKeys, gitHandlerMock, allowList, false)
+			request := signedRequest("push", "application/json", []byte(`{}`), "It's a Secret to Everybody")
+			request.RemoteAddr = "192.30.252.10:41234"
+			writer := httptest.NewRecorder()
+
+			gitHandlerMock.EXPECT().HandlePushEvent(gomock.Any(), gomock.Any())
+
+			webhookHandler.HandleWebhook(writer, request)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+		})
+
+		It("should reject webhooks from other addresses", func() {
+			webhookHandler := handler.NewWebhookEventHandlerWithLimits(secretKeys, gitHandlerMock, allowList, false)
+			request := signedRequest("push", "application/json", []byte(`{}`), "It's a Secret to Everybody")
+			request.RemoteAddr = "203.0.113.10:41234"
+			request.Header.Set("X-Forwarded-For", "192.30.252.10")
+			writer := httptest.NewRecorder()
+
+			webhookHandler.HandleWebhook(writer, request)
+
+			Expect(writer.Code).To(Equal(http.StatusForbidden))
+		})
+
+		It("should take the address added by a trusted proxy", func() {
+			webhookHandler := handler.NewWebhookEventHandlerWithLimits(secretKeys, gitHandlerMock, allowList, true)
+			request := signedRequest("push", "application/json", []byte(`{}`), "It's a Secret to Everybody")
+			request.RemoteAddr = "10.0.0.2:41234"
+			request.Header.Set("X-Forwarded-For", "192.30.252.10, 203.0.113.10")
+			writer := httptest.NewRecorder()
+
+			webhookHandler.HandleWebhook(writer, request)
+
+			Expect(writer.Code).To(Equal(http.StatusForbidden))
+		})
+	})
 })
+
+// signedRequest returns a webhook request with body signed with secret.
+func signedRequest(event, contentType string, body []byte, secret string) *http.Request {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	request := httptest.NewRequest(http.MethodPost, "/git-event", bytes.NewReader(body))
+	request.Header.Set("Content-Type", contentType)
+	request.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	request.Header.Set("X-Github-Event", event)
+	return request
+}
@@ -0,0 +1,102 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"git-slack-bot/internal/github"
+	messageBuilder "git-slack-bot/internal/messagebuilder"
+	"git-slack-bot/internal/reminder"
+	"git-slack-bot/internal/slack"
+	"git-slack-bot/internal/user"
+	"log/slog"
+
+	sl "github.com/slack-go/slack"
+)
+
+type InteractionHandler interface {
+	HandleInteraction(ctx context.Context, callback sl.InteractionCallback)
+}
+
+// PRActionHandler handles clicks on the buttons of pull request announcements.
+type PRActionHandler struct {
+	githubConnector github.Interactor
+	slackConnector  slack.Interactor
+	userService     user.Service
+	snoozer         reminder.Snoozer
+	messageBuilder  messageBuilder.MessageBuilder
+}
+
+// NewPRActionHandler creates a PRActionHandler. snoozer may be nil if reminders are disabled.
+func NewPRActionHandler(githubConnector github.Interactor, slackConnector slack.Interactor, userService user.Service, snoozer reminder.Snoozer) *PRActionHandler {
+	return &PRActionHandler{
+		githubConnector: githubConnector,
+		slackConnector:  slackConnector,
+		userService:     userService,
+		snoozer:         snoozer,
+		messageBuilder:  messageBuilder.MessageBuilder{},
+	}
+}
+
+func (h *PRActionHandler) HandleInteraction(ctx context.Context, callback sl.InteractionCallback) {
+	if callback.Type != sl.InteractionTypeBlockActions {
+		return
+	}
+	for _, action := range callback.ActionCallback.BlockActions {
+		switch action.ActionID {
+		case messageBuilder.ActionClaimReview:
+			h.claimReview(ctx, callback.User.ID, action.Value, &callback.Message)
+		case messageBuilder.ActionSnoozeReminders:
+			h.snooze(ctx, callback.User.ID, action.Value)
+		}
+	}
+}
+
+func (h *PRActionHandler) claimReview(ctx context.Context, slackUserID, pullRequestURL string, slackMessage *sl.Message) {
+	githubLogin, err := h.userService.GetGithubLogin(ctx, slackUserID)
+	if err != nil {
+		slog.Error("Failed to get github login of slack user", slog.String("user", slackUserID), slog.Any("error", err))
+		h.slackConnector.SendEphemeral(ctx, slackUserID, "I couldn't find your GitHub account. Ask for it to be added to `githubEmailToSlackEmail`.")
+		return
+	}
+	owner, repo, number, err := github.ParsePullRequestURL(pullRequestURL)
+	if err != nil {
+		slog.Error("Invalid pull request in button", slog.String("value", pullRequestURL), slog.Any("error", err))
+		return
+	}
+	err = h.githubConnector.RequestReviewer(ctx, owner, repo, number, githubLogin)
+	if err != nil {
+		slog.Error("Failed to request reviewer", slog.String("pullRequest", pullRequestURL), slog.String("user", githubLogin), slog.Any("error", err))
+		h.slackConnector.SendEphemeral(ctx, slackUserID, fmt.Sprintf("I couldn't add you as a reviewer of <%s|this PR>.", pullRequestURL))
+		return
+	}
+	slog.Info("Claimed review", slog.String("pullRequest", pullRequestURL), slog.String("user", githubLogin))
+	h.slackConnector.SendReply(ctx, slackMessage, h.messageBuilder.BuildReviewClaimedMessage(slackUserID))
+}
+
+func (h *PRActionHandler) snooze(ctx context.Context, slackUserID, pullRequestURL string) {
+	if h.snoozer == nil {
+		h.slackConnector.SendEphemeral(ctx, slackUserID, "Review reminders are not enabled.")
+		return
+	}
+	until := h.snoozer.Snooze(ctx, pullRequestURL)
+	h.slackConnector.SendEphemeral(ctx, slackUserID, h.messageBuilder.BuildSnoozedMessage(pullRequestURL, until))
+}
@@ -0,0 +1,109 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package handler_test
+
+import (
+	"context"
+	"errors"
+	mock_github "git-slack-bot/internal/github/mocks"
+	"git-slack-bot/internal/handler"
+	mock_reminder "git-slack-bot/internal/reminder/mocks"
+	mock_slack "git-slack-bot/internal/slack/mocks"
+	mock_user "git-slack-bot/internal/user/mocks"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("PRActionHandler", func() {
+	const pullRequestURL = "https://github.com/org/repo/pull/7"
+
+	var (
+		githubMock  *mock_github.MockInteractor
+		slackMock   *mock_slack.MockInteractor
+		userMock    *mock_user.MockService
+		snoozerMock *mock_reminder.MockSnoozer
+	)
+
+	click := func(actionID string) slack.InteractionCallback {
+		return slack.InteractionCallback{
+			Type:    slack.InteractionTypeBlockActions,
+			User:    slack.User{ID: "U123"},
+			Message: slack.Message{Msg: slack.Msg{Timestamp: "1700000000.000100"}},
+			ActionCallback: slack.ActionCallbacks{
+				BlockActions: []*slack.BlockAction{{ActionID: actionID, Value: pullRequestURL}},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		githubMock = mock_github.NewMockInteractor(mockCtrl)
+		slackMock = mock_slack.NewMockInteractor(mockCtrl)
+		userMock = mock_user.NewMockService(mockCtrl)
+		snoozerMock = mock_reminder.NewMockSnoozer(mockCtrl)
+	})
+
+	It("should request a review from the user who claimed it", func() {
+		userMock.EXPECT().GetGithubLogin(gomock.Any(), "U123").Return("bob", nil)
+		githubMock.EXPECT().RequestReviewer(gomock.Any(), "org", "repo", 7, "bob").Return(nil)
+		slackMock.EXPECT().SendReply(gomock.Any(), &slack.Message{Msg: slack.Msg{Timestamp: "1700000000.000100"}}, "<@U123> will review this PR")
+
+		handler.NewPRActionHandler(githubMock, slackMock, userMock, snoozerMock).HandleInteraction(context.Background(), click("claim_review"))
+	})
+
+	It("should tell the user if the review could not be requested", func() {
+		userMock.EXPECT().GetGithubLogin(gomock.Any(), "U123").Return("alice", nil)
+		githubMock.EXPECT().RequestReviewer(gomock.Any(), "org", "repo", 7, "alice").Return(errors.New("author can't review"))
+		slackMock.EXPECT().SendReply(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		slackMock.EXPECT().SendEphemeral(gomock.Any(), "U123", "I couldn't add you as a reviewer of <https://github.com/org/repo/pull/7|this PR>.")
+
+		handler.NewPRActionHandler(githubMock, slackMock, userMock, snoozerMock).HandleInteraction(context.Background(), click("claim_review"))
+	})
+
+	It("should tell unmapped users how to get mapped", func() {
+		userMock.EXPECT().GetGithubLogin(gomock.Any(), "U123").Return("", errors.New("not mapped"))
+		githubMock.EXPECT().RequestReviewer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		slackMock.EXPECT().SendEphemeral(gomock.Any(), "U123", gomock.Any())
+
+		handler.NewPRActionHandler(githubMock, slackMock, userMock, snoozerMock).HandleInteraction(context.Background(), click("claim_review"))
+	})
+
+	It("should snooze reminders", func() {
+		until := time.Date(2025, time.March, 4, 14, 0, 0, 0, time.UTC)
+		snoozerMock.EXPECT().Snooze(gomock.Any(), pullRequestURL).Return(until)
+		slackMock.EXPECT().SendEphemeral(gomock.Any(), "U123", "Review reminders for <https://github.com/org/repo/pull/7|this PR> are snoozed until <!date^1741096800^{date_short_pretty} at {time}|Tue, 04 Mar 2025 14:00:00 UTC>")
+
+		handler.NewPRActionHandler(githubMock, slackMock, userMock, snoozerMock).HandleInteraction(context.Background(), click("snooze_reminders"))
+	})
+
+	It("should tell the user if reminders are disabled", func() {
+		slackMock.EXPECT().SendEphemeral(gomock.Any(), "U123", "Review reminders are not enabled.")
+
+		handler.NewPRActionHandler(githubMock, slackMock, userMock, nil).HandleInteraction(context.Background(), click("snooze_reminders"))
+	})
+
+	It("should ignore clicks on link buttons", func() {
+		handler.NewPRActionHandler(githubMock, slackMock, userMock, snoozerMock).HandleInteraction(context.Background(), click("open_diff"))
+	})
+})
@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handler/admin_handler.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/handler/admin_handler.go -destination=internal/handler/mocks/admin_handler.go
+//
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	context "context"
+	deadletter "git-slack-bot/internal/deadletter"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDeadLetterAdmin is a mock of DeadLetterAdmin interface.
+type MockDeadLetterAdmin struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeadLetterAdminMockRecorder
+	isgomock struct{}
+}
+
+// MockDeadLetterAdminMockRecorder is the mock recorder for MockDeadLetterAdmin.
+type MockDeadLetterAdminMockRecorder struct {
+	mock *MockDeadLetterAdmin
+}
+
+// NewMockDeadLetterAdmin creates a new mock instance.
+func NewMockDeadLetterAdmin(ctrl *gomock.Controller) *MockDeadLetterAdmin {
+	mock := &MockDeadLetterAdmin{ctrl: ctrl}
+	mock.recorder = &MockDeadLetterAdminMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeadLetterAdmin) EXPECT() *MockDeadLetterAdminMockRecorder {
+	return m.recorder
+}
+
+// Discard mocks base method.
+func (m *MockDeadLetterAdmin) Discard(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Discard", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Discard indicates an expected call of Discard.
+func (mr *MockDeadLetterAdminMockRecorder) Discard(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Discard", reflect.TypeOf((*MockDeadLetterAdmin)(nil).Discard), id)
+}
+
+// List mocks base method.
+func (m *MockDeadLetterAdmin) List() []deadletter.Entry {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List")
+	ret0, _ := ret[0].([]deadletter.Entry)
+	return ret0
+}
+
+// List indicates an expected call of List.
+func (mr *MockDeadLetterAdminMockRecorder) List() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDeadLetterAdmin)(nil).List))
+}
+
+// Retry mocks base method.
+func (m *MockDeadLetterAdmin) Retry(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Retry", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Retry indicates an expected call of Retry.
+func (mr *MockDeadLetterAdminMockRecorder) Retry(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Retry", reflect.TypeOf((*MockDeadLetterAdmin)(nil).Retry), ctx, id)
+}
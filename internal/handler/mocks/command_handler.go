@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handler/command_handler.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/handler/command_handler.go -destination=internal/handler/mocks/command_handler.go
+//
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	context "context"
+	reflect "reflect"
+
+	slack "github.com/slack-go/slack"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSlashCommandHandler is a mock of SlashCommandHandler interface.
+type MockSlashCommandHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockSlashCommandHandlerMockRecorder
+	isgomock struct{}
+}
+
+// MockSlashCommandHandlerMockRecorder is the mock recorder for MockSlashCommandHandler.
+type MockSlashCommandHandlerMockRecorder struct {
+	mock *MockSlashCommandHandler
+}
+
+// NewMockSlashCommandHandler creates a new mock instance.
+func NewMockSlashCommandHandler(ctrl *gomock.Controller) *MockSlashCommandHandler {
+	mock := &MockSlashCommandHandler{ctrl: ctrl}
+	mock.recorder = &MockSlashCommandHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSlashCommandHandler) EXPECT() *MockSlashCommandHandlerMockRecorder {
+	return m.recorder
+}
+
+// HandleSlashCommand mocks base method.
+func (m *MockSlashCommandHandler) HandleSlashCommand(ctx context.Context, command slack.SlashCommand) *slack.Msg {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleSlashCommand", ctx, command)
+	ret0, _ := ret[0].(*slack.Msg)
+	return ret0
+}
+
+// HandleSlashCommand indicates an expected call of HandleSlashCommand.
+func (mr *MockSlashCommandHandlerMockRecorder) HandleSlashCommand(ctx, command any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleSlashCommand", reflect.TypeOf((*MockSlashCommandHandler)(nil).HandleSlashCommand), ctx, command)
+}
@@ -1,10 +1,16 @@
 // Code generated by MockGen. DO NOT EDIT.
 // Source: internal/handler/git_handler.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/handler/git_handler.go -destination=internal/handler/mocks/git_handler.go
+//
 
 // Package mock_handler is a generated GoMock package.
 package mock_handler
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "go.uber.org/mock/gomock"
@@ -14,6 +20,7 @@ import (
 type MockGitEventHandler struct {
 	ctrl     *gomock.Controller
 	recorder *MockGitEventHandlerMockRecorder
+	isgomock struct{}
 }
 
 // MockGitEventHandlerMockRecorder is the mock recorder for MockGitEventHandler.
@@ -34,49 +41,61 @@ func (m *MockGitEventHandler) EXPECT() *MockGitEventHandlerMockRecorder {
 }
 
 // HandleIssueCommentEvent mocks base method.
-func (m *MockGitEventHandler) HandleIssueCommentEvent(body []byte) {
+func (m *MockGitEventHandler) HandleIssueCommentEvent(ctx context.Context, body []byte) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "HandleIssueCommentEvent", body)
+	m.ctrl.Call(m, "HandleIssueCommentEvent", ctx, body)
 }
 
 // HandleIssueCommentEvent indicates an expected call of HandleIssueCommentEvent.
-func (mr *MockGitEventHandlerMockRecorder) HandleIssueCommentEvent(body interface{}) *gomock.Call {
+func (mr *MockGitEventHandlerMockRecorder) HandleIssueCommentEvent(ctx, body any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleIssueCommentEvent", reflect.TypeOf((*MockGitEventHandler)(nil).HandleIssueCommentEvent), body)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleIssueCommentEvent", reflect.TypeOf((*MockGitEventHandler)(nil).HandleIssueCommentEvent), ctx, body)
 }
 
 // HandlePullRequestEvent mocks base method.
-func (m *MockGitEventHandler) HandlePullRequestEvent(body []byte) {
+func (m *MockGitEventHandler) HandlePullRequestEvent(ctx context.Context, body []byte) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "HandlePullRequestEvent", body)
+	m.ctrl.Call(m, "HandlePullRequestEvent", ctx, body)
 }
 
 // HandlePullRequestEvent indicates an expected call of HandlePullRequestEvent.
-func (mr *MockGitEventHandlerMockRecorder) HandlePullRequestEvent(body interface{}) *gomock.Call {
+func (mr *MockGitEventHandlerMockRecorder) HandlePullRequestEvent(ctx, body any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandlePullRequestEvent", reflect.TypeOf((*MockGitEventHandler)(nil).HandlePullRequestEvent), body)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandlePullRequestEvent", reflect.TypeOf((*MockGitEventHandler)(nil).HandlePullRequestEvent), ctx, body)
 }
 
 // HandlePullRequestReviewCommentEvent mocks base method.
-func (m *MockGitEventHandler) HandlePullRequestReviewCommentEvent(body []byte) {
+func (m *MockGitEventHandler) HandlePullRequestReviewCommentEvent(ctx context.Context, body []byte) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "HandlePullRequestReviewCommentEvent", body)
+	m.ctrl.Call(m, "HandlePullRequestReviewCommentEvent", ctx, body)
 }
 
 // HandlePullRequestReviewCommentEvent indicates an expected call of HandlePullRequestReviewCommentEvent.
-func (mr *MockGitEventHandlerMockRecorder) HandlePullRequestReviewCommentEvent(body interface{}) *gomock.Call {
+func (mr *MockGitEventHandlerMockRecorder) HandlePullRequestReviewCommentEvent(ctx, body any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandlePullRequestReviewCommentEvent", reflect.TypeOf((*MockGitEventHandler)(nil).HandlePullRequestReviewCommentEvent), body)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandlePullRequestReviewCommentEvent", reflect.TypeOf((*MockGitEventHandler)(nil).HandlePullRequestReviewCommentEvent), ctx, body)
 }
 
 // HandlePullRequestReviewEvent mocks base method.
-func (m *MockGitEventHandler) HandlePullRequestReviewEvent(body []byte) {
+func (m *MockGitEventHandler) HandlePullRequestReviewEvent(ctx context.Context, body []byte) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "HandlePullRequestReviewEvent", body)
+	m.ctrl.Call(m, "HandlePullRequestReviewEvent", ctx, body)
 }
 
 // HandlePullRequestReviewEvent indicates an expected call of HandlePullRequestReviewEvent.
-func (mr *MockGitEventHandlerMockRecorder) HandlePullRequestReviewEvent(body interface{}) *gomock.Call {
+func (mr *MockGitEventHandlerMockRecorder) HandlePullRequestReviewEvent(ctx, body any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandlePullRequestReviewEvent", reflect.TypeOf((*MockGitEventHandler)(nil).HandlePullRequestReviewEvent), body)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandlePullRequestReviewEvent", reflect.TypeOf((*MockGitEventHandler)(nil).HandlePullRequestReviewEvent), ctx, body)
+}
+
+// HandlePushEvent mocks base method.
+func (m *MockGitEventHandler) HandlePushEvent(ctx context.Context, body []byte) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HandlePushEvent", ctx, body)
+}
+
+// HandlePushEvent indicates an expected call of HandlePushEvent.
+func (mr *MockGitEventHandlerMockRecorder) HandlePushEvent(ctx, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandlePushEvent", reflect.TypeOf((*MockGitEventHandler)(nil).HandlePushEvent), ctx, body)
 }
@@ -0,0 +1,54 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handler/interaction_handler.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/handler/interaction_handler.go -destination=internal/handler/mocks/interaction_handler.go
+//
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	context "context"
+	reflect "reflect"
+
+	slack "github.com/slack-go/slack"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockInteractionHandler is a mock of InteractionHandler interface.
+type MockInteractionHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockInteractionHandlerMockRecorder
+	isgomock struct{}
+}
+
+// MockInteractionHandlerMockRecorder is the mock recorder for MockInteractionHandler.
+type MockInteractionHandlerMockRecorder struct {
+	mock *MockInteractionHandler
+}
+
+// NewMockInteractionHandler creates a new mock instance.
+func NewMockInteractionHandler(ctrl *gomock.Controller) *MockInteractionHandler {
+	mock := &MockInteractionHandler{ctrl: ctrl}
+	mock.recorder = &MockInteractionHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInteractionHandler) EXPECT() *MockInteractionHandlerMockRecorder {
+	return m.recorder
+}
+
+// HandleInteraction mocks base method.
+func (m *MockInteractionHandler) HandleInteraction(ctx context.Context, callback slack.InteractionCallback) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HandleInteraction", ctx, callback)
+}
+
+// HandleInteraction indicates an expected call of HandleInteraction.
+func (mr *MockInteractionHandlerMockRecorder) HandleInteraction(ctx, callback any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleInteraction", reflect.TypeOf((*MockInteractionHandler)(nil).HandleInteraction), ctx, callback)
+}
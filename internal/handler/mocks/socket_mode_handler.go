@@ -0,0 +1,114 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handler/socket_mode_handler.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/handler/socket_mode_handler.go -destination=internal/handler/mocks/socket_mode_handler.go
+//
+
+// Package mock_handler is a generated GoMock package.
+package mock_handler
+
+import (
+	context "context"
+	reflect "reflect"
+
+	socketmode "github.com/slack-go/slack/socketmode"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSocketModeAcknowledger is a mock of SocketModeAcknowledger interface.
+type MockSocketModeAcknowledger struct {
+	ctrl     *gomock.Controller
+	recorder *MockSocketModeAcknowledgerMockRecorder
+	isgomock struct{}
+}
+
+// MockSocketModeAcknowledgerMockRecorder is the mock recorder for MockSocketModeAcknowledger.
+type MockSocketModeAcknowledgerMockRecorder struct {
+	mock *MockSocketModeAcknowledger
+}
+
+// NewMockSocketModeAcknowledger creates a new mock instance.
+func NewMockSocketModeAcknowledger(ctrl *gomock.Controller) *MockSocketModeAcknowledger {
+	mock := &MockSocketModeAcknowledger{ctrl: ctrl}
+	mock.recorder = &MockSocketModeAcknowledgerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSocketModeAcknowledger) EXPECT() *MockSocketModeAcknowledgerMockRecorder {
+	return m.recorder
+}
+
+// Ack mocks base method.
+func (m *MockSocketModeAcknowledger) Ack(request socketmode.Request, payload ...any) {
+	m.ctrl.T.Helper()
+	varargs := []any{request}
+	for _, a := range payload {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Ack", varargs...)
+}
+
+// Ack indicates an expected call of Ack.
+func (mr *MockSocketModeAcknowledgerMockRecorder) Ack(request any, payload ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{request}, payload...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ack", reflect.TypeOf((*MockSocketModeAcknowledger)(nil).Ack), varargs...)
+}
+
+// MockSocketModeClient is a mock of SocketModeClient interface.
+type MockSocketModeClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockSocketModeClientMockRecorder
+	isgomock struct{}
+}
+
+// MockSocketModeClientMockRecorder is the mock recorder for MockSocketModeClient.
+type MockSocketModeClientMockRecorder struct {
+	mock *MockSocketModeClient
+}
+
+// NewMockSocketModeClient creates a new mock instance.
+func NewMockSocketModeClient(ctrl *gomock.Controller) *MockSocketModeClient {
+	mock := &MockSocketModeClient{ctrl: ctrl}
+	mock.recorder = &MockSocketModeClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSocketModeClient) EXPECT() *MockSocketModeClientMockRecorder {
+	return m.recorder
+}
+
+// Ack mocks base method.
+func (m *MockSocketModeClient) Ack(request socketmode.Request, payload ...any) {
+	m.ctrl.T.Helper()
+	varargs := []any{request}
+	for _, a := range payload {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Ack", varargs...)
+}
+
+// Ack indicates an expected call of Ack.
+func (mr *MockSocketModeClientMockRecorder) Ack(request any, payload ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{request}, payload...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ack", reflect.TypeOf((*MockSocketModeClient)(nil).Ack), varargs...)
+}
+
+// RunContext mocks base method.
+func (m *MockSocketModeClient) RunContext(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunContext", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunContext indicates an expected call of RunContext.
+func (mr *MockSocketModeClientMockRecorder) RunContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunContext", reflect.TypeOf((*MockSocketModeClient)(nil).RunContext), ctx)
+}
@@ -0,0 +1,156 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"git-slack-bot/internal/secret"
+	"io"
+	"log/slog"
+	"net/http"
+
+	sl "github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// SlackHandler receives requests sent by slack, such as slash commands, button clicks and events, and verifies their
+// signature.
+type SlackHandler struct {
+	signingSecret      *secret.Secret
+	commandHandler     SlashCommandHandler
+	interactionHandler InteractionHandler
+	eventHandler       EventHandler
+}
+
+// NewSlackHandler creates a SlackHandler verifying requests with the latest value of signingSecret.
+func NewSlackHandler(signingSecret *secret.Secret, commandHandler SlashCommandHandler, interactionHandler InteractionHandler, eventHandler EventHandler) *SlackHandler {
+	return &SlackHandler{
+		signingSecret:      signingSecret,
+		commandHandler:     commandHandler,
+		interactionHandler: interactionHandler,
+		eventHandler:       eventHandler,
+	}
+}
+
+// HandleSlashCommand acknowledges the slash command straight away, as slack expects a response within 3 seconds, and
+// answers it in the background.
+func (h *SlackHandler) HandleSlashCommand(w http.ResponseWriter, r *http.Request) {
+	if !h.verify(r) {
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+	command, err := sl.SlashCommandParse(r)
+	if err != nil {
+		slog.Error("Error parsing slash command", slog.Any("error", err))
+		http.Error(w, "Invalid slash command", http.StatusBadRequest)
+		return
+	}
+	slog.Debug("slash command", slog.String("command", command.Command), slog.String("text", command.Text), slog.String("user", command.UserID))
+
+	go respondToSlashCommand(context.WithoutCancel(r.Context()), h.commandHandler, command)
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleInteraction acknowledges the interaction straight away, as slack expects a response within 3 seconds, and
+// handles it in the background.
+func (h *SlackHandler) HandleInteraction(w http.ResponseWriter, r *http.Request) {
+	if !h.verify(r) {
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+	var callback sl.InteractionCallback
+	err := json.Unmarshal([]byte(r.PostFormValue("payload")), &callback)
+	if err != nil {
+		slog.Error("Error parsing interaction", slog.Any("error", err))
+		http.Error(w, "Invalid interaction", http.StatusBadRequest)
+		return
+	}
+	slog.Debug("interaction", slog.String("type", string(callback.Type)), slog.String("user", callback.User.ID))
+
+	go h.interactionHandler.HandleInteraction(context.WithoutCancel(r.Context()), callback)
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleEvent answers the url verification challenge of the Events API and handles other events in the background.
+// Retries are dropped, as slack only retries events that were not acknowledged in time and those have been handled
+// already.
+func (h *SlackHandler) HandleEvent(w http.ResponseWriter, r *http.Request) {
+	if !h.verify(r) {
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		slog.Error("Error reading slack event", slog.Any("error", err))
+		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		return
+	}
+	event, err := slackevents.ParseEvent(body, slackevents.OptionNoVerifyToken())
+	if err != nil {
+		slog.Error("Error parsing slack event", slog.Any("error", err))
+		http.Error(w, "Invalid event", http.StatusBadRequest)
+		return
+	}
+	slog.Debug("slack event", slog.String("type", event.Type), slog.String("innerType", event.InnerEvent.Type))
+
+	switch {
+	case event.Type == slackevents.URLVerification:
+		var challenge slackevents.ChallengeResponse
+		err = json.Unmarshal(body, &challenge)
+		if err != nil {
+			slog.Error("Error parsing url verification", slog.Any("error", err))
+			http.Error(w, "Invalid challenge", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, err = w.Write([]byte(challenge.Challenge))
+		if err != nil {
+			slog.Error("Error writing url verification response", slog.Any("error", err))
+		}
+	case r.Header.Get("X-Slack-Retry-Num") != "":
+		w.WriteHeader(http.StatusOK)
+	default:
+		go h.eventHandler.HandleEvent(context.WithoutCancel(r.Context()), event)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// verify checks the request against the slack signing secret and leaves the body in place to be read again.
+func (h *SlackHandler) verify(r *http.Request) bool {
+	verifier, err := sl.NewSecretsVerifier(r.Header, h.signingSecret.Value())
+	if err != nil {
+		slog.Error("Error validating slack request", slog.Any("error", err))
+		return false
+	}
+	body, err := io.ReadAll(io.TeeReader(r.Body, &verifier))
+	if err != nil {
+		slog.Error("Error reading slack request", slog.Any("error", err))
+		return false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	err = verifier.Ensure()
+	if err != nil {
+		slog.Error("Error validating slack request", slog.Any("error", err))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,253 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package handler_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"git-slack-bot/internal/handler"
+	mock_handler "git-slack-bot/internal/handler/mocks"
+	"git-slack-bot/internal/secret"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// responseURL serves a slash command response url, passing on the answers posted to it.
+func responseURL() (string, <-chan slack.WebhookMessage) {
+	answers := make(chan slack.WebhookMessage, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer GinkgoRecover()
+		var answer slack.WebhookMessage
+		Expect(json.NewDecoder(r.Body).Decode(&answer)).To(Succeed())
+		answers <- answer
+	}))
+	DeferCleanup(server.Close)
+	return server.URL, answers
+}
+
+var _ = Describe("HandleSlashCommand", func() {
+	const signingSecret = "It's a Secret to Everybody"
+
+	var (
+		commandHandlerMock     *mock_handler.MockSlashCommandHandler
+		interactionHandlerMock *mock_handler.MockInteractionHandler
+		answers                <-chan slack.WebhookMessage
+		body                   string
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		commandHandlerMock = mock_handler.NewMockSlashCommandHandler(mockCtrl)
+		interactionHandlerMock = mock_handler.NewMockInteractionHandler(mockCtrl)
+		var address string
+		address, answers = responseURL()
+		body = "command=%2Fprs&text=mine&user_id=U123&response_url=" + url.QueryEscape(address)
+	})
+
+	signedRequest := func(secret string) *http.Request {
+		return signedSlackRequest(secret, "/slack/commands", body)
+	}
+
+	It("should acknowledge a signed slash command and answer it in the background", func() {
+		slackHandler := handler.NewSlackHandler(secret.Fixed(signingSecret), commandHandlerMock, interactionHandlerMock, nil)
+		writer := httptest.NewRecorder()
+
+		commandHandlerMock.EXPECT().HandleSlashCommand(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, command slack.SlashCommand) *slack.Msg {
+			Expect(command.Command).To(Equal("/prs"))
+			Expect(command.Text).To(Equal("mine"))
+			Expect(command.UserID).To(Equal("U123"))
+			return &slack.Msg{ResponseType: slack.ResponseTypeEphemeral, Text: "Your PRs"}
+		})
+
+		slackHandler.HandleSlashCommand(writer, signedRequest(signingSecret))
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(BeEmpty())
+		var answer slack.WebhookMessage
+		Eventually(answers).Should(Receive(&answer))
+		Expect(answer.ResponseType).To(Equal(slack.ResponseTypeEphemeral))
+		Expect(answer.Text).To(Equal("Your PRs"))
+	})
+
+	It("should reject a slash command with an invalid signature", func() {
+		slackHandler := handler.NewSlackHandler(secret.Fixed(signingSecret), commandHandlerMock, interactionHandlerMock, nil)
+		writer := httptest.NewRecorder()
+
+		commandHandlerMock.EXPECT().HandleSlashCommand(gomock.Any(), gomock.Any()).Times(0)
+
+		slackHandler.HandleSlashCommand(writer, signedRequest("wrong secret"))
+
+		Expect(writer.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should reject a slash command without a signature", func() {
+		slackHandler := handler.NewSlackHandler(secret.Fixed(signingSecret), commandHandlerMock, interactionHandlerMock, nil)
+		writer := httptest.NewRecorder()
+
+		commandHandlerMock.EXPECT().HandleSlashCommand(gomock.Any(), gomock.Any()).Times(0)
+
+		slackHandler.HandleSlashCommand(writer, httptest.NewRequest(http.MethodPost, "/slack/commands", strings.NewReader(body)))
+
+		Expect(writer.Code).To(Equal(http.StatusUnauthorized))
+	})
+})
+
+var _ = Describe("HandleInteraction", func() {
+	const signingSecret = "It's a Secret to Everybody"
+
+	var (
+		commandHandlerMock     *mock_handler.MockSlashCommandHandler
+		interactionHandlerMock *mock_handler.MockInteractionHandler
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		commandHandlerMock = mock_handler.NewMockSlashCommandHandler(mockCtrl)
+		interactionHandlerMock = mock_handler.NewMockInteractionHandler(mockCtrl)
+	})
+
+	It("should hand a signed interaction to the interaction handler", func() {
+		slackHandler := handler.NewSlackHandler(secret.Fixed(signingSecret), commandHandlerMock, interactionHandlerMock, nil)
+		writer := httptest.NewRecorder()
+		payload := `{"type":"block_actions","user":{"id":"U123"},"actions":[{"block_id":"pr_actions","action_id":"claim_review","value":"https://github.com/org/repo/pull/1"}]}`
+		handled := make(chan slack.InteractionCallback, 1)
+
+		interactionHandlerMock.EXPECT().HandleInteraction(gomock.Any(), gomock.Any()).Do(func(_ context.Context, callback slack.InteractionCallback) {
+			handled <- callback
+		})
+
+		slackHandler.HandleInteraction(writer, signedSlackRequest(signingSecret, "/slack/interactivity", "payload="+url.QueryEscape(payload)))
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		var callback slack.InteractionCallback
+		Eventually(handled).Should(Receive(&callback))
+		Expect(callback.User.ID).To(Equal("U123"))
+		Expect(callback.ActionCallback.BlockActions[0].ActionID).To(Equal("claim_review"))
+	})
+
+	It("should reject an interaction with an invalid signature", func() {
+		slackHandler := handler.NewSlackHandler(secret.Fixed(signingSecret), commandHandlerMock, interactionHandlerMock, nil)
+		writer := httptest.NewRecorder()
+
+		interactionHandlerMock.EXPECT().HandleInteraction(gomock.Any(), gomock.Any()).Times(0)
+
+		slackHandler.HandleInteraction(writer, signedSlackRequest("wrong secret", "/slack/interactivity", "payload=%7B%7D"))
+
+		Expect(writer.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should reject an invalid payload", func() {
+		slackHandler := handler.NewSlackHandler(secret.Fixed(signingSecret), commandHandlerMock, interactionHandlerMock, nil)
+		writer := httptest.NewRecorder()
+
+		interactionHandlerMock.EXPECT().HandleInteraction(gomock.Any(), gomock.Any()).Times(0)
+
+		slackHandler.HandleInteraction(writer, signedSlackRequest(signingSecret, "/slack/interactivity", "payload=nope"))
+
+		Expect(writer.Code).To(Equal(http.StatusBadRequest))
+	})
+})
+
+func signedSlackRequest(secret, target, body string) *http.Request {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	request := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	request.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return request
+}
+
+var _ = Describe("HandleEvent", func() {
+	const signingSecret = "It's a Secret to Everybody"
+
+	var eventHandlerMock *mock_handler.MockEventHandler
+
+	BeforeEach(func() {
+		eventHandlerMock = mock_handler.NewMockEventHandler(gomock.NewController(GinkgoT()))
+	})
+
+	It("should answer the url verification challenge", func() {
+		slackHandler := handler.NewSlackHandler(secret.Fixed(signingSecret), nil, nil, eventHandlerMock)
+		writer := httptest.NewRecorder()
+
+		eventHandlerMock.EXPECT().HandleEvent(gomock.Any(), gomock.Any()).Times(0)
+
+		slackHandler.HandleEvent(writer, signedSlackRequest(signingSecret, "/slack/events", `{"type":"url_verification","challenge":"abc123"}`))
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(Equal("abc123"))
+	})
+
+	It("should hand message events to the event handler", func() {
+		slackHandler := handler.NewSlackHandler(secret.Fixed(signingSecret), nil, nil, eventHandlerMock)
+		writer := httptest.NewRecorder()
+		handled := make(chan slackevents.EventsAPIEvent, 1)
+
+		eventHandlerMock.EXPECT().HandleEvent(gomock.Any(), gomock.Any()).Do(func(_ context.Context, event slackevents.EventsAPIEvent) {
+			handled <- event
+		})
+
+		slackHandler.HandleEvent(writer, signedSlackRequest(signingSecret, "/slack/events", `{"type":"event_callback","event":{"type":"message","channel":"C123","user":"U123","text":"LGTM","ts":"2.0","thread_ts":"1.0"}}`))
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		var event slackevents.EventsAPIEvent
+		Eventually(handled).Should(Receive(&event))
+		Expect(event.InnerEvent.Data).To(BeAssignableToTypeOf(&slackevents.MessageEvent{}))
+	})
+
+	It("should drop retried events", func() {
+		slackHandler := handler.NewSlackHandler(secret.Fixed(signingSecret), nil, nil, eventHandlerMock)
+		writer := httptest.NewRecorder()
+		request := signedSlackRequest(signingSecret, "/slack/events", `{"type":"event_callback","event":{"type":"message","channel":"C123","user":"U123","text":"LGTM","ts":"2.0","thread_ts":"1.0"}}`)
+		request.Header.Set("X-Slack-Retry-Num", "1")
+
+		eventHandlerMock.EXPECT().HandleEvent(gomock.Any(), gomock.Any()).Times(0)
+
+		slackHandler.HandleEvent(writer, request)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+	})
+
+	It("should reject an event with an invalid signature", func() {
+		slackHandler := handler.NewSlackHandler(secret.Fixed(signingSecret), nil, nil, eventHandlerMock)
+		writer := httptest.NewRecorder()
+
+		slackHandler.HandleEvent(writer, signedSlackRequest("wrong secret", "/slack/events", `{"type":"url_verification","challenge":"abc123"}`))
+
+		Expect(writer.Code).To(Equal(http.StatusUnauthorized))
+	})
+})
@@ -0,0 +1,201 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package handler
+
+import (
+	"context"
+	"git-slack-bot/internal/secret"
+	"log/slog"
+	"time"
+
+	sl "github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+// defaultTokenCheckInterval is how often SocketMode checks whether the tokens changed.
+const defaultTokenCheckInterval = time.Minute
+
+type SocketModeAcknowledger interface {
+	Ack(request socketmode.Request, payload ...interface{})
+}
+
+// SocketModeClient is a Socket Mode connection to slack.
+type SocketModeClient interface {
+	SocketModeAcknowledger
+	RunContext(ctx context.Context) error
+}
+
+// SocketModeConnector creates a Socket Mode connection with the bot and app-level tokens, along with the channel of
+// what slack sends over it.
+type SocketModeConnector func(botToken, appToken string) (SocketModeClient, <-chan socketmode.Event)
+
+// SocketMode keeps a Socket Mode connection to slack, which is made again with the new tokens whenever either of them
+// changes, such as after they were rotated in the secret store.
+type SocketMode struct {
+	botToken           *secret.Secret
+	appToken           *secret.Secret
+	commandHandler     SlashCommandHandler
+	interactionHandler InteractionHandler
+	eventHandler       EventHandler
+	connect            SocketModeConnector
+	interval           time.Duration
+}
+
+// NewSocketMode creates a SocketMode routing what slack sends to the handlers like NewSocketModeHandler.
+func NewSocketMode(botToken, appToken *secret.Secret, commandHandler SlashCommandHandler, interactionHandler InteractionHandler, eventHandler EventHandler) *SocketMode {
+	return NewSocketModeWithConnector(botToken, appToken, commandHandler, interactionHandler, eventHandler, connectSocketMode, defaultTokenCheckInterval)
+}
+
+// NewSocketModeWithConnector creates a SocketMode which connects with connect and checks the tokens for changes every
+// interval.
+func NewSocketModeWithConnector(botToken, appToken *secret.Secret, commandHandler SlashCommandHandler, interactionHandler InteractionHandler, eventHandler EventHandler, connect SocketModeConnector, interval time.Duration) *SocketMode {
+	return &SocketMode{
+		botToken:           botToken,
+		appToken:           appToken,
+		commandHandler:     commandHandler,
+		interactionHandler: interactionHandler,
+		eventHandler:       eventHandler,
+		connect:            connect,
+		interval:           interval,
+	}
+}
+
+func connectSocketMode(botToken, appToken string) (SocketModeClient, <-chan socketmode.Event) {
+	client := socketmode.New(sl.New(botToken, sl.OptionAppLevelToken(appToken)))
+	return client, client.Events
+}
+
+// Run stays connected until ctx is cancelled.
+func (s *SocketMode) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		botToken, appToken := s.botToken.Value(), s.appToken.Value()
+		disconnect := s.run(ctx, botToken, appToken)
+		for s.botToken.Value() == botToken && s.appToken.Value() == appToken {
+			select {
+			case <-ctx.Done():
+				disconnect()
+				return
+			case <-ticker.C:
+			}
+		}
+		slog.Info("Slack tokens changed, reconnecting in socket mode")
+		disconnect()
+	}
+}
+
+// run connects with the tokens and handles what slack sends until the returned function is called, which waits for
+// the connection to close.
+func (s *SocketMode) run(ctx context.Context, botToken, appToken string) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	client, events := s.connect(botToken, appToken)
+	go NewSocketModeHandler(client, s.commandHandler, s.interactionHandler, s.eventHandler).Listen(ctx, events)
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		err := client.RunContext(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("Socket mode error", slog.Any("error", err))
+		}
+	}()
+	return func() {
+		cancel()
+		<-closed
+	}
+}
+
+// SocketModeHandler routes slash commands, button clicks and events received over a Socket Mode connection to the
+// same handlers as the http endpoints, so that slack needs no public ingress.
+type SocketModeHandler struct {
+	acknowledger       SocketModeAcknowledger
+	commandHandler     SlashCommandHandler
+	interactionHandler InteractionHandler
+	eventHandler       EventHandler
+}
+
+// NewSocketModeHandler creates a SocketModeHandler. eventHandler may be nil, in which case events are only
+// acknowledged.
+func NewSocketModeHandler(acknowledger SocketModeAcknowledger, commandHandler SlashCommandHandler, interactionHandler InteractionHandler, eventHandler EventHandler) *SocketModeHandler {
+	return &SocketModeHandler{
+		acknowledger:       acknowledger,
+		commandHandler:     commandHandler,
+		interactionHandler: interactionHandler,
+		eventHandler:       eventHandler,
+	}
+}
+
+// Listen handles events until the channel is closed or ctx is cancelled.
+func (h *SocketModeHandler) Listen(ctx context.Context, events <-chan socketmode.Event) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			h.HandleSocketModeEvent(ctx, event)
+		}
+	}
+}
+
+func (h *SocketModeHandler) HandleSocketModeEvent(ctx context.Context, event socketmode.Event) {
+	switch event.Type {
+	case socketmode.EventTypeConnecting:
+		slog.Info("Connecting to slack in socket mode")
+	case socketmode.EventTypeConnected:
+		slog.Info("Connected to slack in socket mode")
+	case socketmode.EventTypeConnectionError, socketmode.EventTypeInvalidAuth:
+		slog.Error("Socket mode connection failed", slog.String("type", string(event.Type)), slog.Any("error", event.Data))
+	case socketmode.EventTypeSlashCommand:
+		command, ok := event.Data.(sl.SlashCommand)
+		if !ok {
+			slog.Error("Unexpected socket mode slash command", slog.Any("data", event.Data))
+			return
+		}
+		slog.Debug("slash command", slog.String("command", command.Command), slog.String("text", command.Text), slog.String("user", command.UserID))
+		h.acknowledger.Ack(*event.Request)
+		go respondToSlashCommand(ctx, h.commandHandler, command)
+	case socketmode.EventTypeInteractive:
+		callback, ok := event.Data.(sl.InteractionCallback)
+		if !ok {
+			slog.Error("Unexpected socket mode interaction", slog.Any("data", event.Data))
+			return
+		}
+		slog.Debug("interaction", slog.String("type", string(callback.Type)), slog.String("user", callback.User.ID))
+		h.acknowledger.Ack(*event.Request)
+		go h.interactionHandler.HandleInteraction(ctx, callback)
+	case socketmode.EventTypeEventsAPI:
+		eventsAPIEvent, ok := event.Data.(slackevents.EventsAPIEvent)
+		if !ok {
+			slog.Error("Unexpected socket mode event", slog.Any("data", event.Data))
+			return
+		}
+		slog.Debug("slack event", slog.String("type", eventsAPIEvent.Type), slog.String("innerType", eventsAPIEvent.InnerEvent.Type))
+		h.acknowledger.Ack(*event.Request)
+		// Retries are only sent for events that were not acknowledged in time and those have been handled already.
+		if h.eventHandler == nil || event.Request.RetryAttempt > 0 {
+			return
+		}
+		go h.eventHandler.HandleEvent(ctx, eventsAPIEvent)
+	}
+}
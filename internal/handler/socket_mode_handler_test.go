@@ -0,0 +1,189 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package handler_test
+
+import (
+	"context"
+	"git-slack-bot/internal/handler"
+	mock_handler "git-slack-bot/internal/handler/mocks"
+	"git-slack-bot/internal/secret"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SocketModeHandler", func() {
+	var (
+		acknowledgerMock       *mock_handler.MockSocketModeAcknowledger
+		commandHandlerMock     *mock_handler.MockSlashCommandHandler
+		interactionHandlerMock *mock_handler.MockInteractionHandler
+		eventHandlerMock       *mock_handler.MockEventHandler
+		socketModeHandler      *handler.SocketModeHandler
+		request                *socketmode.Request
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		acknowledgerMock = mock_handler.NewMockSocketModeAcknowledger(mockCtrl)
+		commandHandlerMock = mock_handler.NewMockSlashCommandHandler(mockCtrl)
+		interactionHandlerMock = mock_handler.NewMockInteractionHandler(mockCtrl)
+		eventHandlerMock = mock_handler.NewMockEventHandler(mockCtrl)
+		socketModeHandler = handler.NewSocketModeHandler(acknowledgerMock, commandHandlerMock, interactionHandlerMock, eventHandlerMock)
+		request = &socketmode.Request{EnvelopeID: "envelope"}
+	})
+
+	It("should acknowledge slash commands and answer them in the background", func() {
+		address, answers := responseURL()
+		command := slack.SlashCommand{Command: "/prs", Text: "team", ResponseURL: address}
+		response := &slack.Msg{ResponseType: slack.ResponseTypeEphemeral, Text: "Team PRs"}
+
+		acknowledgerMock.EXPECT().Ack(*request)
+		commandHandlerMock.EXPECT().HandleSlashCommand(gomock.Any(), command).Return(response)
+
+		socketModeHandler.HandleSocketModeEvent(context.Background(), socketmode.Event{Type: socketmode.EventTypeSlashCommand, Data: command, Request: request})
+
+		var answer slack.WebhookMessage
+		Eventually(answers).Should(Receive(&answer))
+		Expect(answer.Text).To(Equal("Team PRs"))
+	})
+
+	It("should acknowledge interactions and handle them in the background", func() {
+		callback := slack.InteractionCallback{Type: slack.InteractionTypeBlockActions, User: slack.User{ID: "U123"}}
+		handled := make(chan struct{})
+
+		acknowledgerMock.EXPECT().Ack(*request)
+		interactionHandlerMock.EXPECT().HandleInteraction(gomock.Any(), callback).Do(func(_ context.Context, _ slack.InteractionCallback) {
+			close(handled)
+		})
+
+		socketModeHandler.HandleSocketModeEvent(context.Background(), socketmode.Event{Type: socketmode.EventTypeInteractive, Data: callback, Request: request})
+
+		Eventually(handled).Should(BeClosed())
+	})
+
+	It("should acknowledge events and handle them in the background", func() {
+		event := slackevents.EventsAPIEvent{Type: slackevents.CallbackEvent}
+		handled := make(chan struct{})
+
+		acknowledgerMock.EXPECT().Ack(*request)
+		eventHandlerMock.EXPECT().HandleEvent(gomock.Any(), event).Do(func(_ context.Context, _ slackevents.EventsAPIEvent) {
+			close(handled)
+		})
+
+		socketModeHandler.HandleSocketModeEvent(context.Background(), socketmode.Event{Type: socketmode.EventTypeEventsAPI, Data: event, Request: request})
+
+		Eventually(handled).Should(BeClosed())
+	})
+
+	It("should only acknowledge retried events", func() {
+		request.RetryAttempt = 1
+
+		acknowledgerMock.EXPECT().Ack(*request)
+		eventHandlerMock.EXPECT().HandleEvent(gomock.Any(), gomock.Any()).Times(0)
+
+		socketModeHandler.HandleSocketModeEvent(context.Background(), socketmode.Event{Type: socketmode.EventTypeEventsAPI, Data: slackevents.EventsAPIEvent{}, Request: request})
+	})
+
+	It("should only acknowledge events without an event handler", func() {
+		socketModeHandler = handler.NewSocketModeHandler(acknowledgerMock, commandHandlerMock, interactionHandlerMock, nil)
+
+		acknowledgerMock.EXPECT().Ack(*request)
+
+		socketModeHandler.HandleSocketModeEvent(context.Background(), socketmode.Event{Type: socketmode.EventTypeEventsAPI, Data: slackevents.EventsAPIEvent{}, Request: request})
+	})
+
+	It("should handle events until the channel is closed", func() {
+		events := make(chan socketmode.Event, 2)
+		events <- socketmode.Event{Type: socketmode.EventTypeConnected}
+		events <- socketmode.Event{Type: socketmode.EventTypeEventsAPI, Data: slackevents.EventsAPIEvent{}, Request: request}
+		close(events)
+		request.RetryAttempt = 1
+
+		acknowledgerMock.EXPECT().Ack(*request)
+
+		socketModeHandler.Listen(context.Background(), events)
+	})
+
+	It("should stop listening when the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		socketModeHandler.Listen(ctx, make(chan socketmode.Event))
+	})
+})
+
+var _ = Describe("SocketMode", func() {
+	var (
+		mockCtrl *gomock.Controller
+		store    *secret.Store
+		path     string
+		appToken *secret.Secret
+		tokens   chan string
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		path = filepath.Join(GinkgoT().TempDir(), "app-token")
+		Expect(os.WriteFile(path, []byte("xapp-1"), 0o600)).To(Succeed())
+		store = secret.NewStoreWithProviders(time.Hour, map[string]secret.Provider{"file": secret.NewFileProvider()})
+		var err error
+		appToken, err = store.Resolve(context.Background(), "file://"+path)
+		Expect(err).ToNot(HaveOccurred())
+		tokens = make(chan string, 2)
+	})
+
+	// connect hands out clients which stay connected until their context is cancelled.
+	connect := func(botToken, appToken string) (handler.SocketModeClient, <-chan socketmode.Event) {
+		tokens <- botToken + " " + appToken
+		client := mock_handler.NewMockSocketModeClient(mockCtrl)
+		client.EXPECT().RunContext(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		return client, make(chan socketmode.Event)
+	}
+
+	It("should reconnect with the new tokens once they change", func() {
+		socketMode := handler.NewSocketModeWithConnector(secret.Fixed("xoxb-1"), appToken, nil, nil, nil, connect, time.Millisecond)
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			socketMode.Run(ctx)
+		}()
+
+		Eventually(tokens).Should(Receive(Equal("xoxb-1 xapp-1")))
+		Expect(os.WriteFile(path, []byte("xapp-2"), 0o600)).To(Succeed())
+		Expect(store.Refresh(context.Background())).To(BeTrue())
+		Eventually(tokens).Should(Receive(Equal("xoxb-1 xapp-2")))
+
+		cancel()
+		Eventually(stopped).Should(BeClosed())
+		Consistently(tokens).ShouldNot(Receive())
+	})
+})
@@ -0,0 +1,101 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"git-slack-bot/internal/health"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestHealth(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Health tests")
+}
+
+var _ = Describe("Checker", func() {
+	var checker *health.Checker
+
+	readiness := func() (int, health.Report) {
+		writer := httptest.NewRecorder()
+		checker.HandleReadiness(writer, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var report health.Report
+		Expect(json.Unmarshal(writer.Body.Bytes(), &report)).To(Succeed())
+		return writer.Code, report
+	}
+
+	BeforeEach(func() {
+		checker = health.NewChecker(50 * time.Millisecond)
+		checker.Add("slack", func(context.Context) error { return nil })
+	})
+
+	It("is live without running the checks", func() {
+		checker.Add("github", func(context.Context) error { return errors.New("bad credentials") })
+		writer := httptest.NewRecorder()
+
+		checker.HandleLiveness(writer, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{"status": "ok"}`))
+	})
+
+	It("is ready when every check passes", func() {
+		checker.Add("github", func(context.Context) error { return nil })
+
+		code, report := readiness()
+
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(report.Status).To(Equal(health.StatusOK))
+		Expect(report.Checks).To(HaveKeyWithValue("slack", HaveField("Status", health.StatusOK)))
+		Expect(report.Checks).To(HaveKeyWithValue("github", HaveField("Status", health.StatusOK)))
+	})
+
+	It("is not ready when a check fails", func() {
+		checker.Add("github", func(context.Context) error { return errors.New("bad credentials") })
+
+		code, report := readiness()
+
+		Expect(code).To(Equal(http.StatusServiceUnavailable))
+		Expect(report.Status).To(Equal(health.StatusFailed))
+		Expect(report.Checks["slack"].Status).To(Equal(health.StatusOK))
+		Expect(report.Checks["github"]).To(Equal(health.CheckResult{Status: health.StatusFailed, LatencyMs: report.Checks["github"].LatencyMs, Error: "bad credentials"}))
+	})
+
+	It("fails checks that take longer than the timeout", func() {
+		checker.Add("github", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		code, report := readiness()
+
+		Expect(code).To(Equal(http.StatusServiceUnavailable))
+		Expect(report.Checks["github"].Error).To(Equal(context.DeadlineExceeded.Error()))
+		Expect(report.Checks["github"].LatencyMs).To(BeNumerically(">=", 50))
+	})
+})
@@ -0,0 +1,159 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"git-slack-bot/internal/config"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Formats of the log lines.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+const redacted = "[REDACTED]"
+
+// DryRunKey groups the parameters of the calls a dry run reports. Showing what would have been posted is the point of
+// a dry run, so the bodies and emails in the group are left alone, unlike the tokens.
+const DryRunKey = "dryRun"
+
+var (
+	// secretKeys are parts of the attribute keys whose values are always masked.
+	secretKeys = []string{"token", "secret", "password", "authorization", "signature"}
+	// bodyKeys are the attribute keys holding payloads and comments, masked unless logSensitiveData is enabled.
+	bodyKeys = map[string]bool{"body": true, "text": true, "message": true, "comment": true}
+
+	tokenPattern = regexp.MustCompile(`\b(?:xox[abposre]-[A-Za-z0-9-]+|xapp-[A-Za-z0-9-]+|gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,}|hvs\.[A-Za-z0-9_-]{20,})|(?i:bearer\s+[A-Za-z0-9._~+/=-]+)`)
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@((?:[A-Za-z0-9-]+\.)+[A-Za-z]{2,})`)
+)
+
+type contextKey struct{}
+
+// With returns a copy of ctx whose log lines carry attrs, such as the delivery, repository and pull request of the
+// event being handled. Only lines logged with the Context functions of slog, such as slog.ErrorContext, carry them.
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(contextKey{}).([]slog.Attr)
+	return context.WithValue(ctx, contextKey{}, append(existing[:len(existing):len(existing)], attrs...))
+}
+
+// NewLogger creates a logger writing to w at the level and in the format of cfg, which the LOG_LEVEL and LOG_FORMAT
+// environment variables override. Tokens are masked, and so are emails and bodies unless cfg enables logging them.
+func NewLogger(w io.Writer, cfg config.LoggingConfiguration) (*slog.Logger, error) {
+	levelName := cfg.Level
+	if env := os.Getenv("LOG_LEVEL"); env != "" {
+		levelName = env
+	}
+	var level slog.Level
+	if levelName != "" {
+		err := level.UnmarshalText([]byte(levelName))
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level %q, expected debug, info, warn or error", levelName)
+		}
+	}
+
+	options := &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			return redact(attr, cfg.LogSensitiveData || slices.Contains(groups, DryRunKey))
+		},
+	}
+	format := cfg.Format
+	if env := os.Getenv("LOG_FORMAT"); env != "" {
+		format = env
+	}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", FormatJSON:
+		handler = slog.NewJSONHandler(w, options)
+	case FormatText:
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return nil, fmt.Errorf("invalid log format %q, expected json or text", format)
+	}
+	return slog.New(&contextHandler{Handler: handler}), nil
+}
+
+// redact masks the secrets in attr. The message, level and time of a line are left alone.
+func redact(attr slog.Attr, logSensitiveData bool) slog.Attr {
+	switch attr.Key {
+	case slog.MessageKey, slog.LevelKey, slog.TimeKey, slog.SourceKey:
+		return attr
+	}
+	key := strings.ToLower(attr.Key)
+	for _, secretKey := range secretKeys {
+		if strings.Contains(key, secretKey) {
+			return slog.String(attr.Key, redacted)
+		}
+	}
+
+	var value string
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		value = attr.Value.String()
+	case slog.KindAny:
+		err, ok := attr.Value.Any().(error)
+		if !ok {
+			return attr
+		}
+		value = err.Error()
+	default:
+		return attr
+	}
+	if !logSensitiveData && bodyKeys[key] {
+		return slog.String(attr.Key, fmt.Sprintf("[REDACTED %d bytes]", len(value)))
+	}
+	masked := tokenPattern.ReplaceAllString(value, redacted)
+	if !logSensitiveData {
+		masked = emailPattern.ReplaceAllString(masked, "***@${1}")
+	}
+	if masked == value {
+		return attr
+	}
+	return slog.String(attr.Key, masked)
+}
+
+// contextHandler adds the attributes of the context to each line.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs, ok := ctx.Value(contextKey{}).([]slog.Attr); ok {
+		record.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -21,14 +21,124 @@ package messagebuilder
 
 import (
 	"fmt"
+	"git-slack-bot/internal/github"
+	"regexp"
+	"strings"
+	"time"
+
 	gh "github.com/google/go-github/v56/github"
+	"github.com/slack-go/slack"
+)
+
+// DigestGroup is a titled section of the open pull request digest.
+type DigestGroup struct {
+	Title   string
+	Entries []DigestEntry
+}
+
+type DigestEntry struct {
+	UserDescriptor string
+	PullRequest    *github.OpenPullRequest
+	Age            time.Duration
+	Stale          bool
+}
+
+// Action ids of the buttons on pull request announcements.
+const (
+	ActionClaimReview     string = "claim_review"
+	ActionSnoozeReminders string = "snooze_reminders"
+	ActionOpenDiff        string = "open_diff"
+)
+
+// PRActions selects the buttons added to pull request announcements.
+type PRActions struct {
+	ClaimReview bool
+	Snooze      bool
+	OpenDiff    bool
+}
+
+// Any reports whether any button is enabled.
+func (a PRActions) Any() bool {
+	return a.ClaimReview || a.Snooze || a.OpenDiff
+}
+
+// SlackCommentMarker is hidden in the github comments posted from slack threads, so they are not mirrored back.
+const SlackCommentMarker string = "<!-- git-slack-bot: posted from slack -->"
+
+var (
+	slackMention = regexp.MustCompile(`<@([A-Z0-9]+)(?:\|[^>]*)?>`)
+	slackLink    = regexp.MustCompile(`<((?:https?|mailto):[^|>]+)(?:\|([^>]+))?>`)
+	slackSpecial = regexp.MustCompile(`<!(here|channel|everyone)(?:\|[^>]*)?>`)
 )
 
+var slackUnescaper = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">")
+
+// linesPerSection keeps section blocks well below slack's 3000 character limit.
+const linesPerSection = 10
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
 type MessageBuilder struct{}
 
 func (m *MessageBuilder) BuildPRMessage(userDescriptor string, pullRequest *gh.PullRequest) string {
 	return fmt.Sprintf("%s %s:\n%s", userDescriptor, *pullRequest.Title, *pullRequest.HTMLURL)
 }
+
+// BuildPRBlocks builds the announcement of a pull request as blocks, with the text of BuildPRMessage followed by the
+// enabled buttons. The buttons carry the pull request's url as their value.
+func (m *MessageBuilder) BuildPRBlocks(message string, pullRequest *gh.PullRequest, actions PRActions) []slack.Block {
+	blocks := []slack.Block{slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, message, false, false), nil, nil)}
+
+	var buttons []slack.BlockElement
+	if actions.ClaimReview {
+		button := slack.NewButtonBlockElement(ActionClaimReview, pullRequest.GetHTMLURL(), slack.NewTextBlockObject(slack.PlainTextType, "I'll review", true, false))
+		buttons = append(buttons, button.WithStyle(slack.StylePrimary))
+	}
+	if actions.Snooze {
+		buttons = append(buttons, slack.NewButtonBlockElement(ActionSnoozeReminders, pullRequest.GetHTMLURL(), slack.NewTextBlockObject(slack.PlainTextType, "Snooze reminders", true, false)))
+	}
+	if actions.OpenDiff {
+		button := slack.NewButtonBlockElement(ActionOpenDiff, pullRequest.GetHTMLURL(), slack.NewTextBlockObject(slack.PlainTextType, "Open diff", true, false))
+		button.URL = pullRequest.GetHTMLURL() + "/files"
+		buttons = append(buttons, button)
+	}
+	if len(buttons) > 0 {
+		blocks = append(blocks, slack.NewActionBlock("pr_actions", buttons...))
+	}
+	return blocks
+}
+
+func (m *MessageBuilder) BuildReviewClaimedMessage(slackUserID string) string {
+	return fmt.Sprintf("<@%s> will review this PR", slackUserID)
+}
+
+// BuildSnoozedMessage uses slack's date formatting, so that every user sees until when in their own timezone.
+func (m *MessageBuilder) BuildSnoozedMessage(pullRequestURL string, until time.Time) string {
+	return fmt.Sprintf("Review reminders for <%s|this PR> are snoozed until <!date^%d^{date_short_pretty} at {time}|%s>", pullRequestURL, until.Unix(), until.UTC().Format(time.RFC1123))
+}
+
+// BuildGitHubComment turns a slack thread reply into a github comment attributed to githubLogin. Slack mentions are
+// replaced by what mentionOf returns for the slack user id.
+func (m *MessageBuilder) BuildGitHubComment(text, githubLogin string, mentionOf func(slackUserID string) string) string {
+	text = slackMention.ReplaceAllStringFunc(text, func(mention string) string {
+		return mentionOf(slackMention.FindStringSubmatch(mention)[1])
+	})
+	text = slackLink.ReplaceAllStringFunc(text, func(link string) string {
+		parts := slackLink.FindStringSubmatch(link)
+		if parts[2] == "" {
+			return parts[1]
+		}
+		return fmt.Sprintf("[%s](%s)", parts[2], parts[1])
+	})
+	text = slackSpecial.ReplaceAllString(text, "@$1")
+	return fmt.Sprintf("%s\n\n_via Slack by @%s_\n%s", slackUnescaper.Replace(text), githubLogin, SlackCommentMarker)
+}
+
+// IsSlackComment reports whether a github comment was posted from slack by BuildGitHubComment.
+func IsSlackComment(body string) bool {
+	return strings.Contains(body, SlackCommentMarker)
+}
+
 func (m *MessageBuilder) BuildPRCommentMessage(userDescriptor string, event gh.PullRequestReviewCommentEvent) string {
 	return fmt.Sprintf("%s left a <%s|comment>:\n> @L%v %s\n%s", userDescriptor, event.Comment.GetHTMLURL(), event.Comment.GetLine(), event.GetComment().GetPath(), event.Comment.GetBody())
 }
@@ -36,3 +146,101 @@ func (m *MessageBuilder) BuildPRCommentMessage(userDescriptor string, event gh.P
 func (m *MessageBuilder) BuildIssueCommentMessage(userDescriptor string, event gh.IssueCommentEvent) string {
 	return fmt.Sprintf("%s left a <%s|comment>:\n%s", userDescriptor, event.Comment.GetHTMLURL(), event.Comment.GetBody())
 }
+
+func (m *MessageBuilder) BuildConflictMessage(userDescriptor string, pullRequest *gh.PullRequest) string {
+	return fmt.Sprintf("%s this PR has merge conflicts with `%s` that need to be resolved before it can be merged", userDescriptor, pullRequest.GetBase().GetRef())
+}
+
+func (m *MessageBuilder) BuildBehindMessage(userDescriptor string, pullRequest *gh.PullRequest) string {
+	return fmt.Sprintf("%s this PR is behind `%s` and needs to be updated before it can be merged", userDescriptor, pullRequest.GetBase().GetRef())
+}
+
+func (m *MessageBuilder) BuildReminderMessage(mentions string, waiting time.Duration) string {
+	return fmt.Sprintf("%s this PR has been waiting for a review for %d working hours", mentions, int(waiting.Hours()))
+}
+
+func (m *MessageBuilder) BuildEscalationMessage(mentions string, waiting time.Duration) string {
+	return fmt.Sprintf(":rotating_light: %s this PR still hasn't been reviewed after %d working hours, could someone pick it up?", mentions, int(waiting.Hours()))
+}
+
+func (m *MessageBuilder) BuildDigestMessage(groups []DigestGroup) string {
+	total := 0
+	for _, group := range groups {
+		total += len(group.Entries)
+	}
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "*Open PRs: %d*", total)
+	for _, group := range groups {
+		fmt.Fprintf(&message, "\n\n*%s*", group.Title)
+		for _, entry := range group.Entries {
+			fmt.Fprintf(&message, "\n• %s", m.buildDigestLine(entry))
+		}
+	}
+	return message.String()
+}
+
+// BuildReviewQueueMessage lists the pull requests waiting on a user's review and the user's own pull requests that
+// got new feedback. Empty sections are left out.
+func (m *MessageBuilder) BuildReviewQueueMessage(toReview, feedback []DigestEntry) string {
+	var message strings.Builder
+	message.WriteString("*Your review queue for today*")
+	if len(toReview) > 0 {
+		fmt.Fprintf(&message, "\n\n*Waiting on your review (%d)*", len(toReview))
+		for _, entry := range toReview {
+			fmt.Fprintf(&message, "\n• %s", m.buildDigestLine(entry))
+		}
+	}
+	if len(feedback) > 0 {
+		fmt.Fprintf(&message, "\n\n*New feedback on your PRs (%d)*", len(feedback))
+		for _, entry := range feedback {
+			fmt.Fprintf(&message, "\n• %s", m.buildDigestLine(entry))
+		}
+	}
+	return message.String()
+}
+
+// BuildPRListBlocks builds a Block Kit list of pull requests under a header, with one titled section per group.
+func (m *MessageBuilder) BuildPRListBlocks(title string, groups []DigestGroup) []slack.Block {
+	blocks := []slack.Block{slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, title, false, false))}
+	for _, group := range groups {
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*%s (%d)*", group.Title, len(group.Entries)), false, false), nil, nil))
+		if len(group.Entries) == 0 {
+			blocks = append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, "Nothing here :tada:", false, false)))
+			continue
+		}
+		for start := 0; start < len(group.Entries); start += linesPerSection {
+			end := min(start+linesPerSection, len(group.Entries))
+			lines := make([]string, 0, end-start)
+			for _, entry := range group.Entries[start:end] {
+				lines = append(lines, "• "+m.buildDigestLine(entry))
+			}
+			blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, strings.Join(lines, "\n"), false, false), nil, nil))
+		}
+	}
+	return blocks
+}
+
+func (m *MessageBuilder) buildDigestLine(entry DigestEntry) string {
+	line := fmt.Sprintf("<%s|%s> in `%s`", entry.PullRequest.URL, slackEscaper.Replace(entry.PullRequest.Title), entry.PullRequest.Repo)
+	if entry.UserDescriptor != "" {
+		line += " by " + entry.UserDescriptor
+	}
+	line += ", " + formatAge(entry.Age)
+	if entry.Stale {
+		line += " :hourglass_flowing_sand: *stale*"
+	}
+	return line
+}
+
+func formatAge(age time.Duration) string {
+	days := int(age.Hours() / 24)
+	switch days {
+	case 0:
+		return "opened today"
+	case 1:
+		return "opened 1 day ago"
+	default:
+		return fmt.Sprintf("opened %d days ago", days)
+	}
+}
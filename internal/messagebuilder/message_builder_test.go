@@ -22,9 +22,14 @@ package messagebuilder
 import (
 	_ "embed"
 	"encoding/json"
+	"git-slack-bot/internal/github"
+	"strings"
+	"time"
+
 	gh "github.com/google/go-github/v56/github"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
 	"testing"
 )
 
@@ -58,6 +63,59 @@ https://github.com/loveholidays/hotels-and-ancillaries/pull/808`
 		Expect(actual).To(Equal(expected))
 	})
 
+	It("should build PR blocks with the enabled buttons", func() {
+		messageBuilder := MessageBuilder{}
+		pullRequest := &gh.PullRequest{HTMLURL: gh.String("https://github.com/loveholidays/frontier/pull/1")}
+
+		actual := messageBuilder.BuildPRBlocks("@George Add caching:\nhttps://github.com/loveholidays/frontier/pull/1", pullRequest, PRActions{ClaimReview: true, OpenDiff: true})
+
+		Expect(actual).To(HaveLen(2))
+		Expect(actual[0].(*slack.SectionBlock).Text.Text).To(Equal("@George Add caching:\nhttps://github.com/loveholidays/frontier/pull/1"))
+		buttons := actual[1].(*slack.ActionBlock).Elements.ElementSet
+		Expect(buttons).To(HaveLen(2))
+		Expect(buttons[0].(*slack.ButtonBlockElement).ActionID).To(Equal(ActionClaimReview))
+		Expect(buttons[0].(*slack.ButtonBlockElement).Value).To(Equal("https://github.com/loveholidays/frontier/pull/1"))
+		Expect(buttons[1].(*slack.ButtonBlockElement).ActionID).To(Equal(ActionOpenDiff))
+		Expect(buttons[1].(*slack.ButtonBlockElement).URL).To(Equal("https://github.com/loveholidays/frontier/pull/1/files"))
+	})
+
+	It("should build PR blocks without buttons", func() {
+		messageBuilder := MessageBuilder{}
+
+		actual := messageBuilder.BuildPRBlocks("message", &gh.PullRequest{}, PRActions{})
+
+		Expect(actual).To(HaveLen(1))
+	})
+
+	It("should build a review claimed message", func() {
+		messageBuilder := MessageBuilder{}
+
+		Expect(messageBuilder.BuildReviewClaimedMessage("U123")).To(Equal("<@U123> will review this PR"))
+	})
+
+	It("should build a snoozed message", func() {
+		messageBuilder := MessageBuilder{}
+
+		actual := messageBuilder.BuildSnoozedMessage("https://github.com/loveholidays/frontier/pull/1", time.Date(2025, time.March, 4, 14, 0, 0, 0, time.UTC))
+
+		Expect(actual).To(Equal("Review reminders for <https://github.com/loveholidays/frontier/pull/1|this PR> are snoozed until <!date^1741096800^{date_short_pretty} at {time}|Tue, 04 Mar 2025 14:00:00 UTC>"))
+	})
+
+	It("should build a github comment from a slack reply", func() {
+		messageBuilder := MessageBuilder{}
+		mentionOf := func(slackUserID string) string {
+			return "@login-of-" + slackUserID
+		}
+
+		actual := messageBuilder.BuildGitHubComment("<@U123> see <https://example.com/docs|the docs> &amp; <https://example.com>, <!here> 1 &lt; 2", "bob", mentionOf)
+
+		Expect(actual).To(Equal("@login-of-U123 see [the docs](https://example.com/docs) & https://example.com, @here 1 < 2\n\n" +
+			"_via Slack by @bob_\n" +
+			"<!-- git-slack-bot: posted from slack -->"))
+		Expect(IsSlackComment(actual)).To(BeTrue())
+		Expect(IsSlackComment("LGTM")).To(BeFalse())
+	})
+
 	It("should build a PR comment message", func() {
 		messageBuilder := MessageBuilder{}
 
@@ -88,4 +146,181 @@ Just leaving a top level comment here`
 
 		Expect(actual).To(Equal(expected))
 	})
+
+	It("should build a merge conflict message", func() {
+		messageBuilder := MessageBuilder{}
+		var pullRequestEvent gh.PullRequestEvent
+		err := json.Unmarshal(prJSONData, &pullRequestEvent)
+		Expect(err).ToNot(HaveOccurred())
+
+		actual := messageBuilder.BuildConflictMessage("@George", pullRequestEvent.PullRequest)
+
+		Expect(actual).To(Equal("@George this PR has merge conflicts with `main` that need to be resolved before it can be merged"))
+	})
+
+	It("should build a behind base message", func() {
+		messageBuilder := MessageBuilder{}
+		var pullRequestEvent gh.PullRequestEvent
+		err := json.Unmarshal(prJSONData, &pullRequestEvent)
+		Expect(err).ToNot(HaveOccurred())
+
+		actual := messageBuilder.BuildBehindMessage("@George", pullRequestEvent.PullRequest)
+
+		Expect(actual).To(Equal("@George this PR is behind `main` and needs to be updated before it can be merged"))
+	})
+
+	It("should build a reminder message", func() {
+		messageBuilder := MessageBuilder{}
+
+		actual := messageBuilder.BuildReminderMessage("<@123> <@456>", 8*time.Hour+30*time.Minute)
+
+		Expect(actual).To(Equal("<@123> <@456> this PR has been waiting for a review for 8 working hours"))
+	})
+
+	It("should build an escalation message", func() {
+		messageBuilder := MessageBuilder{}
+
+		actual := messageBuilder.BuildEscalationMessage("<!here>", 24*time.Hour)
+
+		Expect(actual).To(Equal(":rotating_light: <!here> this PR still hasn't been reviewed after 24 working hours, could someone pick it up?"))
+	})
+
+	It("should build a digest message", func() {
+		messageBuilder := MessageBuilder{}
+
+		actual := messageBuilder.BuildDigestMessage([]DigestGroup{
+			{
+				Title: "Awaiting review – opened this week",
+				Entries: []DigestEntry{
+					{
+						UserDescriptor: "<@123>",
+						PullRequest: &github.OpenPullRequest{
+							Title: "Fix <script> & styles",
+							URL:   "https://github.com/loveholidays/frontier/pull/1",
+							Repo:  "frontier",
+						},
+						Age:   50 * time.Hour,
+						Stale: true,
+					},
+					{
+						UserDescriptor: "george",
+						PullRequest: &github.OpenPullRequest{
+							Title: "Add caching",
+							URL:   "https://github.com/loveholidays/frontier/pull/2",
+							Repo:  "frontier",
+						},
+						Age: 26 * time.Hour,
+					},
+				},
+			},
+			{
+				Title: "Approved – opened today",
+				Entries: []DigestEntry{
+					{
+						UserDescriptor: "<@456>",
+						PullRequest: &github.OpenPullRequest{
+							Title: "Bump version",
+							URL:   "https://github.com/loveholidays/flux/pull/3",
+							Repo:  "flux",
+						},
+						Age: time.Hour,
+					},
+				},
+			},
+		})
+
+		expected := "*Open PRs: 3*\n\n" +
+			"*Awaiting review – opened this week*\n" +
+			"• <https://github.com/loveholidays/frontier/pull/1|Fix &lt;script&gt; &amp; styles> in `frontier` by <@123>, opened 2 days ago :hourglass_flowing_sand: *stale*\n" +
+			"• <https://github.com/loveholidays/frontier/pull/2|Add caching> in `frontier` by george, opened 1 day ago\n\n" +
+			"*Approved – opened today*\n" +
+			"• <https://github.com/loveholidays/flux/pull/3|Bump version> in `flux` by <@456>, opened today"
+
+		Expect(actual).To(Equal(expected))
+	})
+
+	It("should build a review queue message", func() {
+		messageBuilder := MessageBuilder{}
+
+		actual := messageBuilder.BuildReviewQueueMessage(
+			[]DigestEntry{
+				{
+					UserDescriptor: "<@123>",
+					PullRequest: &github.OpenPullRequest{
+						Title: "Add caching",
+						URL:   "https://github.com/loveholidays/frontier/pull/2",
+						Repo:  "frontier",
+					},
+					Age: 26 * time.Hour,
+				},
+			},
+			[]DigestEntry{
+				{
+					PullRequest: &github.OpenPullRequest{
+						Title: "Bump version",
+						URL:   "https://github.com/loveholidays/flux/pull/3",
+						Repo:  "flux",
+					},
+					Age: time.Hour,
+				},
+			},
+		)
+
+		expected := "*Your review queue for today*\n\n" +
+			"*Waiting on your review (1)*\n" +
+			"• <https://github.com/loveholidays/frontier/pull/2|Add caching> in `frontier` by <@123>, opened 1 day ago\n\n" +
+			"*New feedback on your PRs (1)*\n" +
+			"• <https://github.com/loveholidays/flux/pull/3|Bump version> in `flux`, opened today"
+
+		Expect(actual).To(Equal(expected))
+	})
+
+	It("should leave out empty review queue sections", func() {
+		messageBuilder := MessageBuilder{}
+
+		actual := messageBuilder.BuildReviewQueueMessage(nil, []DigestEntry{
+			{
+				PullRequest: &github.OpenPullRequest{
+					Title: "Bump version",
+					URL:   "https://github.com/loveholidays/flux/pull/3",
+					Repo:  "flux",
+				},
+				Age: time.Hour,
+			},
+		})
+
+		Expect(actual).To(Equal("*Your review queue for today*\n\n" +
+			"*New feedback on your PRs (1)*\n" +
+			"• <https://github.com/loveholidays/flux/pull/3|Bump version> in `flux`, opened today"))
+	})
+
+	It("should build a pull request list as blocks", func() {
+		messageBuilder := MessageBuilder{}
+		entries := make([]DigestEntry, 11)
+		for i := range entries {
+			entries[i] = DigestEntry{
+				UserDescriptor: "<@123>",
+				PullRequest: &github.OpenPullRequest{
+					Title: "Add caching",
+					URL:   "https://github.com/loveholidays/frontier/pull/2",
+					Repo:  "frontier",
+				},
+				Age: 26 * time.Hour,
+			}
+		}
+
+		actual := messageBuilder.BuildPRListBlocks("Team PRs", []DigestGroup{
+			{Title: "Awaiting review", Entries: entries},
+			{Title: "Approved"},
+		})
+
+		Expect(actual).To(HaveLen(6))
+		Expect(actual[0].(*slack.HeaderBlock).Text.Text).To(Equal("Team PRs"))
+		Expect(actual[1].(*slack.SectionBlock).Text.Text).To(Equal("*Awaiting review (11)*"))
+		Expect(actual[2].(*slack.SectionBlock).Text.Text).To(HavePrefix("• <https://github.com/loveholidays/frontier/pull/2|Add caching> in `frontier` by <@123>, opened 1 day ago\n"))
+		Expect(strings.Count(actual[2].(*slack.SectionBlock).Text.Text, "•")).To(Equal(10))
+		Expect(strings.Count(actual[3].(*slack.SectionBlock).Text.Text, "•")).To(Equal(1))
+		Expect(actual[4].(*slack.SectionBlock).Text.Text).To(Equal("*Approved (0)*"))
+		Expect(actual[5]).To(BeAssignableToTypeOf(&slack.ContextBlock{}))
+	})
 })
@@ -0,0 +1,175 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "git_slack_bot"
+
+// Reasons for events to be filtered out before anything is posted.
+const (
+	FilteredIgnoredRepo   string = "ignored_repo"
+	FilteredNonTeamAuthor string = "non_team_author"
+	FilteredIgnoredUser   string = "ignored_user"
+	FilteredDraft         string = "draft"
+	FilteredSlackComment  string = "slack_comment"
+)
+
+// Reasons for webhooks to be rejected, other than their signature.
+const (
+	RejectedAddress   string = "address"
+	RejectedTooLarge  string = "too_large"
+	RejectedMalformed string = "malformed"
+)
+
+// Reasons for slack api calls to be retried.
+const (
+	RetryRateLimited string = "rate_limited"
+	RetryServerError string = "server_error"
+)
+
+// Results of retrying a dead letter.
+const (
+	DeadLetterDelivered string = "delivered"
+	DeadLetterFailed    string = "failed"
+)
+
+// Results of looking up the slack message of a pull request.
+const (
+	LookupHit  string = "hit"
+	LookupMiss string = "miss"
+)
+
+// Directions of user resolution.
+const (
+	ResolveSlackUser   string = "slack_user"
+	ResolveGithubLogin string = "github_login"
+)
+
+var (
+	WebhooksReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhooks_received_total",
+		Help:      "Github webhooks received, by event and action.",
+	}, []string{"event", "action"})
+
+	SignatureFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_signature_failures_total",
+		Help:      "Github webhooks rejected because of an invalid signature.",
+	})
+
+	WebhooksRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhooks_rejected_total",
+		Help:      "Github webhooks rejected for a reason other than their signature.",
+	}, []string{"reason"})
+
+	EventsFiltered = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_filtered_total",
+		Help:      "Github events dropped without posting to slack, by reason.",
+	}, []string{"reason"})
+
+	SlackAPICalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "slack_api_calls_total",
+		Help:      "Slack api calls, by method.",
+	}, []string{"method"})
+
+	SlackAPIErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "slack_api_errors_total",
+		Help:      "Failed slack api calls, by method.",
+	}, []string{"method"})
+
+	SlackAPIRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "slack_api_retries_total",
+		Help:      "Slack api calls retried, by method and reason.",
+	}, []string{"method", "reason"})
+
+	DeadLetters = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "dead_letters",
+		Help:      "Failed slack operations waiting to be retried or discarded.",
+	})
+
+	DeadLetterRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "dead_letter_retries_total",
+		Help:      "Retries of failed slack operations, by result.",
+	}, []string{"result"})
+
+	MessageLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "message_lookups_total",
+		Help:      "Lookups of the slack message of a pull request, by result.",
+	}, []string{"result"})
+
+	UserResolutionFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "user_resolution_failures_total",
+		Help:      "Failures to map a github user to slack or back, by what was being resolved.",
+	}, []string{"resolving"})
+
+	HandlerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "handler_duration_seconds",
+		Help:      "Time taken to handle a github event, by handler.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"handler"})
+
+	ConfigReloads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "config_reloads_total",
+		Help:      "Reloads of the configuration file, by whether it was applied or rejected.",
+	}, []string{"result"})
+
+	ConfigLastReloadSuccess = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "config_last_reload_success",
+		Help:      "Whether the latest content of the configuration file was applied (1) or rejected (0).",
+	})
+)
+
+// SlackAPICall counts a call of a slack api method and whether it failed.
+func SlackAPICall(method string, err error) {
+	SlackAPICalls.WithLabelValues(method).Inc()
+	if err != nil {
+		SlackAPIErrors.WithLabelValues(method).Inc()
+	}
+}
+
+// Filtered counts an event dropped for reason.
+func Filtered(reason string) {
+	EventsFiltered.WithLabelValues(reason).Inc()
+}
+
+// Handler serves the metrics in the prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
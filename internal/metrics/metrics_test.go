@@ -0,0 +1,63 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package metrics_test
+
+import (
+	"errors"
+	"git-slack-bot/internal/metrics"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMetrics(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Metrics tests")
+}
+
+var _ = Describe("SlackAPICall", func() {
+	It("counts calls and errors by method", func() {
+		calls := testutil.ToFloat64(metrics.SlackAPICalls.WithLabelValues("reactions.add"))
+		errs := testutil.ToFloat64(metrics.SlackAPIErrors.WithLabelValues("reactions.add"))
+
+		metrics.SlackAPICall("reactions.add", nil)
+		metrics.SlackAPICall("reactions.add", errors.New("already_reacted"))
+
+		Expect(testutil.ToFloat64(metrics.SlackAPICalls.WithLabelValues("reactions.add"))).To(Equal(calls + 2))
+		Expect(testutil.ToFloat64(metrics.SlackAPIErrors.WithLabelValues("reactions.add"))).To(Equal(errs + 1))
+	})
+})
+
+var _ = Describe("Handler", func() {
+	It("serves the counters", func() {
+		metrics.Filtered(metrics.FilteredDraft)
+		writer := httptest.NewRecorder()
+
+		metrics.Handler().ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(ContainSubstring(`git_slack_bot_events_filtered_total{reason="draft"}`))
+	})
+})
@@ -0,0 +1,267 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package poller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"git-slack-bot/internal/github"
+	"git-slack-bot/internal/handler"
+	"git-slack-bot/internal/logging"
+	"git-slack-bot/internal/tracing"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v56/github"
+)
+
+const (
+	defaultInterval = time.Minute
+
+	// maxPages is as far back as the events API goes.
+	maxPages = 3
+	// minRateRemaining leaves some of the rate limit for everything else the bot does.
+	minRateRemaining = 100
+)
+
+// Cursor is the position in the organization's events up to which they have been handled.
+type Cursor struct {
+	LastEventID int64  `json:"lastEventID"`
+	ETag        string `json:"etag"`
+}
+
+// Poller polls the organization's events and passes the pull request, review, comment and push events to the
+// GitEventHandler as if they had been delivered by a webhook. The cursor is saved to a file after every poll, so that
+// a restart continues where it left off. Without a cursor file polling starts from the latest event rather than
+// replaying history.
+type Poller struct {
+	githubConnector github.Interactor
+	gitHandler      handler.GitEventHandler
+	cursorFile      string
+	interval        time.Duration
+	cursor          Cursor
+}
+
+// NewPoller creates a Poller which polls no more often than interval, or than GitHub asks for. A zero interval
+// defaults to a minute.
+func NewPoller(githubConnector github.Interactor, gitHandler handler.GitEventHandler, cursorFile string, interval time.Duration) *Poller {
+	if interval == 0 {
+		interval = defaultInterval
+	}
+	p := &Poller{
+		githubConnector: githubConnector,
+		gitHandler:      gitHandler,
+		cursorFile:      cursorFile,
+		interval:        interval,
+	}
+	p.loadCursor()
+	return p
+}
+
+// Run polls until ctx is cancelled.
+func (p *Poller) Run(ctx context.Context) {
+	for {
+		wait := p.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Poll handles the events since the cursor, oldest first, and returns how long to wait until the next poll.
+func (p *Poller) Poll(ctx context.Context) time.Duration {
+	ctx, span := tracing.Start(ctx, "poller.Poll")
+	defer span.End()
+
+	firstPage, err := p.githubConnector.ListOrganizationEvents(ctx, 1, p.cursor.ETag)
+	if err != nil {
+		return p.backoff(err)
+	}
+	wait := p.nextPoll(firstPage)
+	if firstPage.NotModified {
+		return wait
+	}
+
+	events, complete, err := p.eventsSinceCursor(ctx, firstPage)
+	if err != nil {
+		return p.backoff(err)
+	}
+	switch {
+	case p.cursor.LastEventID == 0:
+		slog.Info("Starting to poll github events from the latest event")
+	case !complete:
+		slog.Warn("Some github events may have been missed, as they are older than the events API goes back")
+		fallthrough
+	default:
+		for i := len(events) - 1; i >= 0; i-- {
+			p.dispatch(ctx, events[i])
+		}
+	}
+
+	if len(firstPage.Events) > 0 {
+		p.cursor.LastEventID = max(p.cursor.LastEventID, eventID(firstPage.Events[0]))
+	}
+	p.cursor.ETag = firstPage.ETag
+	p.saveCursor()
+	return wait
+}
+
+// eventsSinceCursor returns the events newer than the cursor, newest first, and whether they reach back to it.
+func (p *Poller) eventsSinceCursor(ctx context.Context, page *github.EventsPage) ([]*gh.Event, bool, error) {
+	var events []*gh.Event
+	for pageNumber := 1; ; pageNumber++ {
+		for _, event := range page.Events {
+			if eventID(event) <= p.cursor.LastEventID {
+				return events, true, nil
+			}
+			events = append(events, event)
+		}
+		if page.NextPage == 0 || pageNumber == maxPages {
+			return events, false, nil
+		}
+		var err error
+		page, err = p.githubConnector.ListOrganizationEvents(ctx, page.NextPage, "")
+		if err != nil {
+			return nil, false, err
+		}
+	}
+}
+
+func (p *Poller) dispatch(ctx context.Context, event *gh.Event) {
+	ctx, span := tracing.Start(ctx, "poller.dispatch", tracing.Event.String(event.GetType()), tracing.Repository.String(event.GetRepo().GetName()))
+	defer span.End()
+	ctx = logging.With(ctx, slog.String("eventID", event.GetID()), slog.String("event", event.GetType()))
+
+	body, err := withRepository(event)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error adding repository to event payload", slog.Any("error", err))
+		return
+	}
+	slog.DebugContext(ctx, "polled event", slog.String("repo", event.GetRepo().GetName()))
+
+	switch event.GetType() {
+	case "PullRequestEvent":
+		p.gitHandler.HandlePullRequestEvent(ctx, body)
+	case "PullRequestReviewEvent":
+		p.gitHandler.HandlePullRequestReviewEvent(ctx, body)
+	case "PullRequestReviewCommentEvent":
+		p.gitHandler.HandlePullRequestReviewCommentEvent(ctx, body)
+	case "IssueCommentEvent":
+		p.gitHandler.HandleIssueCommentEvent(ctx, body)
+	case "PushEvent":
+		p.gitHandler.HandlePushEvent(ctx, body)
+	}
+}
+
+// nextPoll waits for at least the configured interval and the interval GitHub asks for, and until the rate limit
+// resets if little of it is left.
+func (p *Poller) nextPoll(page *github.EventsPage) time.Duration {
+	wait := max(p.interval, page.PollInterval)
+	if page.Rate.Limit > 0 && page.Rate.Remaining < minRateRemaining {
+		wait = max(wait, time.Until(page.Rate.Reset.Time))
+	}
+	return wait
+}
+
+func (p *Poller) backoff(err error) time.Duration {
+	var rateLimitErr *gh.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		slog.Warn("Rate limited polling github events", slog.Time("reset", rateLimitErr.Rate.Reset.Time))
+		return max(p.interval, time.Until(rateLimitErr.Rate.Reset.Time))
+	}
+	var abuseErr *gh.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		slog.Warn("Secondary rate limit polling github events", slog.Any("error", err))
+		return max(p.interval, abuseErr.GetRetryAfter())
+	}
+	slog.Error("Failed to poll github events", slog.Any("error", err))
+	return p.interval
+}
+
+func (p *Poller) loadCursor() {
+	data, err := os.ReadFile(p.cursorFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to read github events cursor", slog.String("file", p.cursorFile), slog.Any("error", err))
+		return
+	}
+	err = json.Unmarshal(data, &p.cursor)
+	if err != nil {
+		slog.Error("Failed to parse github events cursor", slog.String("file", p.cursorFile), slog.Any("error", err))
+	}
+}
+
+// saveCursor replaces the cursor file in one go, so that a crash never leaves half a cursor behind.
+func (p *Poller) saveCursor() {
+	data, err := json.Marshal(p.cursor)
+	if err != nil {
+		slog.Error("Failed to serialise github events cursor", slog.Any("error", err))
+		return
+	}
+	tempFile := filepath.Join(filepath.Dir(p.cursorFile), "."+filepath.Base(p.cursorFile)+".tmp")
+	err = os.WriteFile(tempFile, data, 0o600)
+	if err == nil {
+		err = os.Rename(tempFile, p.cursorFile)
+	}
+	if err != nil {
+		slog.Error("Failed to save github events cursor", slog.String("file", p.cursorFile), slog.Any("error", err))
+	}
+}
+
+// withRepository adds the repository to the payload, which the events API leaves out in favour of the event's repo.
+func withRepository(event *gh.Event) ([]byte, error) {
+	payload := make(map[string]json.RawMessage)
+	if event.RawPayload != nil {
+		err := json.Unmarshal(*event.RawPayload, &payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+	fullName := event.GetRepo().GetName()
+	owner, name, _ := strings.Cut(fullName, "/")
+	repository, err := json.Marshal(map[string]any{
+		"name":      name,
+		"full_name": fullName,
+		"owner":     map[string]string{"login": owner},
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload["repository"] = repository
+	return json.Marshal(payload)
+}
+
+func eventID(event *gh.Event) int64 {
+	id, err := strconv.ParseInt(event.GetID(), 10, 64)
+	if err != nil {
+		slog.Error("Invalid github event id", slog.String("id", event.GetID()))
+		return 0
+	}
+	return id
+}
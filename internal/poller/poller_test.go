@@ -0,0 +1,192 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package poller_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"git-slack-bot/internal/github"
+	mock_github "git-slack-bot/internal/github/mocks"
+	mock_handler "git-slack-bot/internal/handler/mocks"
+	"git-slack-bot/internal/poller"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v56/github"
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPoller(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Poller tests")
+}
+
+var _ = Describe("Poll", func() {
+	var (
+		githubMock     *mock_github.MockInteractor
+		gitHandlerMock *mock_handler.MockGitEventHandler
+		cursorFile     string
+	)
+
+	event := func(id int, eventType string) *gh.Event {
+		payload := json.RawMessage(fmt.Sprintf(`{"action":"opened","number":%d}`, id))
+		return &gh.Event{
+			ID:         gh.String(fmt.Sprint(id)),
+			Type:       gh.String(eventType),
+			Repo:       &gh.Repository{Name: gh.String("TestOrg/frontier")},
+			RawPayload: &payload,
+		}
+	}
+
+	writeCursor := func(cursor poller.Cursor) {
+		data, err := json.Marshal(cursor)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(cursorFile, data, 0o600)).To(Succeed())
+	}
+
+	readCursor := func() poller.Cursor {
+		var cursor poller.Cursor
+		data, err := os.ReadFile(cursorFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json.Unmarshal(data, &cursor)).To(Succeed())
+		return cursor
+	}
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		githubMock = mock_github.NewMockInteractor(mockCtrl)
+		gitHandlerMock = mock_handler.NewMockGitEventHandler(mockCtrl)
+		cursorFile = filepath.Join(GinkgoT().TempDir(), "cursor.json")
+	})
+
+	It("should handle the events since the cursor oldest first", func() {
+		writeCursor(poller.Cursor{LastEventID: 10, ETag: `"old"`})
+		githubMock.EXPECT().ListOrganizationEvents(gomock.Any(), 1, `"old"`).Return(&github.EventsPage{
+			Events: []*gh.Event{event(13, "IssueCommentEvent"), event(12, "PullRequestEvent"), event(11, "WatchEvent"), event(10, "PullRequestEvent")},
+			ETag:   `"new"`,
+		}, nil)
+		gomock.InOrder(
+			gitHandlerMock.EXPECT().HandlePullRequestEvent(gomock.Any(), gomock.Any()).Do(func(_ context.Context, body []byte) {
+				var payload gh.PullRequestEvent
+				Expect(json.Unmarshal(body, &payload)).To(Succeed())
+				Expect(payload.GetNumber()).To(Equal(12))
+				Expect(payload.GetRepo().GetName()).To(Equal("frontier"))
+				Expect(payload.GetRepo().GetFullName()).To(Equal("TestOrg/frontier"))
+			}),
+			gitHandlerMock.EXPECT().HandleIssueCommentEvent(gomock.Any(), gomock.Any()),
+		)
+
+		poller.NewPoller(githubMock, gitHandlerMock, cursorFile, time.Minute).Poll(context.Background())
+
+		Expect(readCursor()).To(Equal(poller.Cursor{LastEventID: 13, ETag: `"new"`}))
+	})
+
+	It("should follow the pages back to the cursor", func() {
+		writeCursor(poller.Cursor{LastEventID: 10})
+		gomock.InOrder(
+			githubMock.EXPECT().ListOrganizationEvents(gomock.Any(), 1, "").Return(&github.EventsPage{
+				Events:   []*gh.Event{event(12, "PushEvent")},
+				ETag:     `"new"`,
+				NextPage: 2,
+			}, nil),
+			githubMock.EXPECT().ListOrganizationEvents(gomock.Any(), 2, "").Return(&github.EventsPage{
+				Events:   []*gh.Event{event(11, "PullRequestReviewEvent"), event(10, "PushEvent")},
+				NextPage: 3,
+			}, nil),
+		)
+		gomock.InOrder(
+			gitHandlerMock.EXPECT().HandlePullRequestReviewEvent(gomock.Any(), gomock.Any()),
+			gitHandlerMock.EXPECT().HandlePushEvent(gomock.Any(), gomock.Any()).Do(func(_ context.Context, body []byte) {
+				var payload gh.PushEvent
+				Expect(json.Unmarshal(body, &payload)).To(Succeed())
+				Expect(payload.GetRepo().GetOwner().GetLogin()).To(Equal("TestOrg"))
+				Expect(payload.GetRepo().GetName()).To(Equal("frontier"))
+			}),
+		)
+
+		poller.NewPoller(githubMock, gitHandlerMock, cursorFile, time.Minute).Poll(context.Background())
+
+		Expect(readCursor().LastEventID).To(Equal(int64(12)))
+	})
+
+	It("should start from the latest event without a cursor", func() {
+		githubMock.EXPECT().ListOrganizationEvents(gomock.Any(), 1, "").Return(&github.EventsPage{
+			Events: []*gh.Event{event(12, "PullRequestEvent"), event(11, "PullRequestEvent")},
+			ETag:   `"new"`,
+		}, nil)
+		gitHandlerMock.EXPECT().HandlePullRequestEvent(gomock.Any(), gomock.Any()).Times(0)
+
+		poller.NewPoller(githubMock, gitHandlerMock, cursorFile, time.Minute).Poll(context.Background())
+
+		Expect(readCursor()).To(Equal(poller.Cursor{LastEventID: 12, ETag: `"new"`}))
+	})
+
+	It("should do nothing if there are no new events", func() {
+		writeCursor(poller.Cursor{LastEventID: 10, ETag: `"old"`})
+		githubMock.EXPECT().ListOrganizationEvents(gomock.Any(), 1, `"old"`).Return(&github.EventsPage{NotModified: true, ETag: `"old"`, PollInterval: 2 * time.Minute}, nil)
+
+		wait := poller.NewPoller(githubMock, gitHandlerMock, cursorFile, time.Minute).Poll(context.Background())
+
+		Expect(wait).To(Equal(2 * time.Minute))
+		Expect(readCursor()).To(Equal(poller.Cursor{LastEventID: 10, ETag: `"old"`}))
+	})
+
+	It("should not move the cursor if polling fails", func() {
+		writeCursor(poller.Cursor{LastEventID: 10})
+		gomock.InOrder(
+			githubMock.EXPECT().ListOrganizationEvents(gomock.Any(), 1, "").Return(&github.EventsPage{Events: []*gh.Event{event(12, "PushEvent")}, NextPage: 2}, nil),
+			githubMock.EXPECT().ListOrganizationEvents(gomock.Any(), 2, "").Return(nil, errors.New("bad gateway")),
+		)
+		gitHandlerMock.EXPECT().HandlePushEvent(gomock.Any(), gomock.Any()).Times(0)
+
+		wait := poller.NewPoller(githubMock, gitHandlerMock, cursorFile, time.Minute).Poll(context.Background())
+
+		Expect(wait).To(Equal(time.Minute))
+		Expect(readCursor().LastEventID).To(Equal(int64(10)))
+	})
+
+	It("should wait for the rate limit to reset", func() {
+		reset := time.Now().Add(30 * time.Minute)
+		githubMock.EXPECT().ListOrganizationEvents(gomock.Any(), 1, "").Return(nil, &gh.RateLimitError{Rate: gh.Rate{Reset: gh.Timestamp{Time: reset}}})
+
+		wait := poller.NewPoller(githubMock, gitHandlerMock, cursorFile, time.Minute).Poll(context.Background())
+
+		Expect(wait).To(BeNumerically("~", 30*time.Minute, time.Minute))
+	})
+
+	It("should slow down when the rate limit runs low", func() {
+		reset := time.Now().Add(20 * time.Minute)
+		githubMock.EXPECT().ListOrganizationEvents(gomock.Any(), 1, "").Return(&github.EventsPage{
+			NotModified: true,
+			Rate:        gh.Rate{Limit: 5000, Remaining: 10, Reset: gh.Timestamp{Time: reset}},
+		}, nil)
+
+		wait := poller.NewPoller(githubMock, gitHandlerMock, cursorFile, time.Minute).Poll(context.Background())
+
+		Expect(wait).To(BeNumerically("~", 20*time.Minute, time.Minute))
+	})
+})
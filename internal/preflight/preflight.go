@@ -0,0 +1,322 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package preflight
+
+//go:generate mockgen -destination=./mocks/preflight.go . Inspector
+
+import (
+	"context"
+	"fmt"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/github"
+	"git-slack-bot/internal/handler"
+	"git-slack-bot/internal/logging"
+	"git-slack-bot/internal/reminder"
+	"git-slack-bot/internal/secret"
+	"git-slack-bot/internal/slack"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	sl "github.com/slack-go/slack"
+)
+
+type Status string
+
+const (
+	StatusOK      Status = "ok"
+	StatusWarning Status = "warn"
+	StatusFailed  Status = "FAIL"
+)
+
+// timeout bounds all checks together, as they may run at every start.
+const timeout = time.Minute
+
+// requiredScopes are the slack scopes without which the bot can't post or react at all.
+var requiredScopes = []string{"chat:write", "reactions:write"}
+
+// Inspector lists what the slack token may do and which emoji the workspace has.
+type Inspector interface {
+	Scopes(ctx context.Context) ([]string, error)
+	Emoji(ctx context.Context) (map[string]bool, error)
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Check   string
+	Status  Status
+	Message string
+}
+
+// Report is the outcome of all checks, in the order they ran.
+type Report struct {
+	Results []Result
+}
+
+// Failed reports whether any check failed. Warnings don't count as failures.
+func (r Report) Failed() bool {
+	return slices.ContainsFunc(r.Results, func(result Result) bool {
+		return result.Status == StatusFailed
+	})
+}
+
+// Write prints the report one check per line, followed by a summary.
+func (r Report) Write(w io.Writer) error {
+	failed := 0
+	for _, result := range r.Results {
+		if result.Status == StatusFailed {
+			failed++
+		}
+		_, err := fmt.Fprintf(w, "%-4s  %-13s %s\n", result.Status, result.Check, result.Message)
+		if err != nil {
+			return err
+		}
+	}
+	summary := "\nThe configuration is ok.\n"
+	if failed > 0 {
+		summary = fmt.Sprintf("\n%d of %d checks failed.\n", failed, len(r.Results))
+	}
+	_, err := fmt.Fprint(w, summary)
+	return err
+}
+
+// Checker checks that a configuration is valid and works with the github organization and slack workspace it
+// points at.
+type Checker struct {
+	cfg          config.Configuration
+	githubClient github.Client
+	slackClient  slack.Client
+	inspector    Inspector
+	results      []Result
+}
+
+func NewChecker(cfg config.Configuration, githubClient github.Client, slackClient slack.Client, inspector Inspector) *Checker {
+	return &Checker{
+		cfg:          cfg,
+		githubClient: githubClient,
+		slackClient:  slackClient,
+		inspector:    inspector,
+	}
+}
+
+// Run runs all checks. Checks against github and slack run even if the configuration has problems, so that all of
+// them are reported at once.
+func (c *Checker) Run(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	c.results = nil
+	c.checkConfiguration()
+	c.checkGithubTeam(ctx)
+	c.checkSlackScopes(ctx)
+	c.checkSlackChannel(ctx, "channel", c.cfg.Slack.ChannelID)
+	if c.cfg.Slack.ShadowChannelID != "" {
+		c.checkSlackChannel(ctx, "shadow channel", c.cfg.Slack.ShadowChannelID)
+	}
+	c.checkEmoji(ctx)
+	c.checkSlackUsers(ctx)
+	return Report{Results: c.results}
+}
+
+func (c *Checker) add(check string, status Status, format string, args ...any) {
+	c.results = append(c.results, Result{Check: check, Status: status, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *Checker) checkConfiguration() {
+	problems := Validate(c.cfg)
+	for _, problem := range problems {
+		c.add("configuration", StatusFailed, "%s", problem)
+	}
+	if len(problems) == 0 {
+		c.add("configuration", StatusOK, "the configuration is valid")
+	}
+}
+
+// Validate returns the problems with the settings of cfg that can be found without calling github or slack.
+func Validate(cfg config.Configuration) []string {
+	var problems []string
+	_, err := time.LoadLocation(cfg.Schedule.Timezone)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("schedule.timezone %q is not a known timezone", cfg.Schedule.Timezone))
+	}
+	for field, spec := range map[string]string{"schedule.digest.cron": cfg.Schedule.Digest.Cron, "schedule.reminders.cron": cfg.Schedule.Reminders.Cron} {
+		if spec == "" {
+			continue
+		}
+		_, err = cron.ParseStandard(spec)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s %q is not a valid cron expression: %s", field, spec, err))
+		}
+	}
+	workingHours := cfg.Schedule.WorkingHours
+	if workingHours != (config.WorkingHoursConfiguration{}) && (workingHours.Start < 0 || workingHours.End > 24 || workingHours.Start >= workingHours.End) {
+		problems = append(problems, fmt.Sprintf("schedule.workingHours %d to %d is not a working day", workingHours.Start, workingHours.End))
+	}
+	for _, rule := range cfg.Schedule.Reminders.Rules {
+		if rule.Mention != "" && rule.Mention != reminder.MentionReviewers && rule.Mention != reminder.MentionTeam {
+			problems = append(problems, fmt.Sprintf("reminder rule %q mentions %q instead of %q or %q", rule.Name, rule.Mention, reminder.MentionReviewers, reminder.MentionTeam))
+		}
+		if rule.RemindAfterHours <= 0 {
+			problems = append(problems, fmt.Sprintf("reminder rule %q needs a positive remindAfterHours", rule.Name))
+		}
+		if rule.EscalateAfterHours != 0 && rule.EscalateAfterHours < rule.RemindAfterHours {
+			problems = append(problems, fmt.Sprintf("reminder rule %q escalates before it reminds", rule.Name))
+		}
+	}
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		problems = append(problems, fmt.Sprintf("tracing.sampleRatio %v is not between 0 and 1", cfg.Tracing.SampleRatio))
+	}
+	for i, mapping := range cfg.Slack.GithubEmailToSlackEmail {
+		if mapping.GithubEmail == "" || mapping.SlackEmail == "" {
+			problems = append(problems, fmt.Sprintf("slack.githubEmailToSlackEmail entry %d needs both githubEmail and slackEmail", i+1))
+		}
+	}
+	app := cfg.GitHub.App
+	switch {
+	case app.ID == 0 && cfg.GitHub.Token == "":
+		problems = append(problems, "github needs either a token or an app")
+	case app.ID != 0 && (app.PrivateKey == "") == (app.PrivateKeyFile == ""):
+		problems = append(problems, "github.app needs either privateKey or privateKeyFile")
+	}
+	if cfg.GitHub.Polling.Enabled && app.ID != 0 {
+		problems = append(problems, "github.polling needs a token, a GitHub App can't list the events of private repositories")
+	}
+	if cfg.GitHub.WebhookEnabled() && len(cfg.GitHub.WebhookSecrets()) == 0 {
+		problems = append(problems, "github needs a secretKey or secretKeys to verify webhooks with, unless it only polls")
+	}
+	for i, installation := range app.Installations {
+		if installation.Org == "" {
+			problems = append(problems, fmt.Sprintf("github.app.installations entry %d needs an org", i+1))
+		}
+	}
+	if cfg.Secrets.Vault.Address == "" {
+		for field, value := range map[string]string{"github.token": cfg.GitHub.Token, "github.secretKey": cfg.GitHub.SecretKey, "slack.token": cfg.Slack.Token, "slack.appToken": cfg.Slack.AppToken, "slack.signingSecret": cfg.Slack.SigningSecret, "server.adminToken": cfg.Server.AdminToken} {
+			if secret.Scheme(value) == "vault" {
+				problems = append(problems, fmt.Sprintf("%s refers to vault, which needs secrets.vault.address", field))
+			}
+		}
+	}
+	if cfg.Slack.Retry.MaxAttempts < 0 {
+		problems = append(problems, "slack.retry.maxAttempts can't be negative")
+	}
+	if cfg.Slack.DeadLetters.MaxAttempts < 0 {
+		problems = append(problems, "slack.deadLetters.maxAttempts can't be negative")
+	}
+	_, err = logging.NewLogger(io.Discard, cfg.Logging)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("logging: %s", err))
+	}
+	if cfg.Slack.AnnouncementWindow < 0 {
+		problems = append(problems, "slack.announcementWindow can't be negative")
+	}
+	if cfg.GitHub.Webhook.AllowedIPsFile != "" {
+		_, err = handler.LoadAllowList(cfg.GitHub.Webhook.AllowedIPsFile)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("github.webhook.allowedIPsFile can't be loaded: %s", err))
+		}
+	}
+	if (cfg.Server.TLS.CertFile == "") != (cfg.Server.TLS.KeyFile == "") {
+		problems = append(problems, "server.tls needs both certFile and keyFile")
+	}
+	slices.Sort(problems)
+	return problems
+}
+
+func (c *Checker) checkGithubTeam(ctx context.Context) {
+	connector, err := github.NewGitHubConnector(ctx, c.cfg.GitHub, c.githubClient)
+	if err != nil {
+		c.add("github", StatusFailed, "could not find team %q in organization %q: %s", c.cfg.GitHub.Team, c.cfg.GitHub.Org, err)
+		return
+	}
+	members := connector.GetTeamMembers(ctx)
+	if len(members) == 0 {
+		c.add("github", StatusFailed, "team %q of organization %q has no members the bot can see", c.cfg.GitHub.Team, c.cfg.GitHub.Org)
+		return
+	}
+	c.add("github", StatusOK, "team %q of organization %q has %d members", c.cfg.GitHub.Team, c.cfg.GitHub.Org, len(members))
+}
+
+func (c *Checker) checkSlackScopes(ctx context.Context) {
+	scopes, err := c.inspector.Scopes(ctx)
+	if err != nil {
+		c.add("slack token", StatusFailed, "the token does not work: %s", err)
+		return
+	}
+	var missing []string
+	for _, scope := range requiredScopes {
+		if !slices.Contains(scopes, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		c.add("slack token", StatusFailed, "the token is missing the %s scopes", strings.Join(missing, ", "))
+		return
+	}
+	c.add("slack token", StatusOK, "the token has the %s scopes", strings.Join(requiredScopes, ", "))
+}
+
+func (c *Checker) checkSlackChannel(ctx context.Context, check, channelID string) {
+	_, err := c.slackClient.GetConversationHistoryContext(ctx, &sl.GetConversationHistoryParameters{ChannelID: channelID, Limit: 1})
+	if err != nil {
+		c.add(check, StatusFailed, "channel %s can't be read, make sure the bot was added to it: %s", channelID, err)
+		return
+	}
+	c.add(check, StatusOK, "channel %s is accessible", channelID)
+}
+
+func (c *Checker) checkEmoji(ctx context.Context) {
+	available, err := c.inspector.Emoji(ctx)
+	if err != nil {
+		c.add("emoji", StatusWarning, "could not list the emoji of the workspace, which needs the emoji:read scope: %s", err)
+		return
+	}
+	emoji := c.cfg.Slack.EmojiConfiguration.WithDefaults()
+	var missing []string
+	for _, name := range []string{emoji.Approve, emoji.Merge, emoji.Close, emoji.Conflict, emoji.Behind} {
+		// Skin tones are not listed separately.
+		name, _, _ = strings.Cut(name, "::")
+		if !available[name] && !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		c.add("emoji", StatusFailed, "the workspace has no :%s: emoji", strings.Join(missing, ":, :"))
+		return
+	}
+	c.add("emoji", StatusOK, "all configured emoji exist")
+}
+
+func (c *Checker) checkSlackUsers(ctx context.Context) {
+	mappings := c.cfg.Slack.GithubEmailToSlackEmail
+	resolved := 0
+	for _, mapping := range mappings {
+		_, err := c.slackClient.GetUserByEmailContext(ctx, mapping.SlackEmail)
+		if err != nil {
+			c.add("slack users", StatusFailed, "%s of github user %s does not resolve to a slack user: %s", mapping.SlackEmail, mapping.GithubEmail, err)
+			continue
+		}
+		resolved++
+	}
+	if resolved == len(mappings) {
+		c.add("slack users", StatusOK, "all %d mapped slack emails resolve", resolved)
+	}
+}
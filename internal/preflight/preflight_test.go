@@ -0,0 +1,228 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package preflight_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"git-slack-bot/internal/config"
+	mock_github "git-slack-bot/internal/github/mocks"
+	"git-slack-bot/internal/preflight"
+	mock_preflight "git-slack-bot/internal/preflight/mocks"
+	mock_slack "git-slack-bot/internal/slack/mocks"
+	"testing"
+
+	gh "github.com/google/go-github/v56/github"
+	sl "github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPreflight(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Preflight tests")
+}
+
+var _ = Describe("Checker", func() {
+	var (
+		githubMock    *mock_github.MockClient
+		slackMock     *mock_slack.MockClient
+		inspectorMock *mock_preflight.MockInspector
+		cfg           config.Configuration
+		standardEmoji map[string]bool
+	)
+
+	results := func() map[string]preflight.Result {
+		report := preflight.NewChecker(cfg, githubMock, slackMock, inspectorMock).Run(context.Background())
+		byCheck := make(map[string]preflight.Result)
+		for _, result := range report.Results {
+			if existing, ok := byCheck[result.Check]; !ok || existing.Status != preflight.StatusFailed {
+				byCheck[result.Check] = result
+			}
+		}
+		return byCheck
+	}
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		githubMock = mock_github.NewMockClient(mockCtrl)
+		slackMock = mock_slack.NewMockClient(mockCtrl)
+		inspectorMock = mock_preflight.NewMockInspector(mockCtrl)
+		cfg = config.Configuration{
+			GitHub: config.GitHubConfiguration{Token: "token", Org: "loveholidays", Team: "platform", SecretKey: "secret"},
+			Slack: config.SlackConfiguration{
+				ChannelID:               "C123",
+				GithubEmailToSlackEmail: []config.GithubEmailToSlackEmail{{GithubEmail: "octocat", SlackEmail: "octocat@example.com"}},
+			},
+		}
+		standardEmoji = map[string]bool{"+1": true, "merged": true, "x": true, "warning": true, "arrows_counterclockwise": true}
+
+		githubMock.EXPECT().GetOrg(gomock.Any(), "loveholidays").Return(&gh.Organization{ID: gh.Int64(1)}, nil).AnyTimes()
+		githubMock.EXPECT().ListTeams(gomock.Any(), "loveholidays", gomock.Any()).Return([]*gh.Team{{ID: gh.Int64(2), Name: gh.String("platform")}}, nil).AnyTimes()
+		githubMock.EXPECT().ListTeamMembers(gomock.Any(), "loveholidays", int64(2), int64(1), gomock.Any()).Return([]*gh.User{{Login: gh.String("octocat")}}, nil).AnyTimes()
+		inspectorMock.EXPECT().Scopes(gomock.Any()).Return([]string{"chat:write", "reactions:write", "users:read.email"}, nil).AnyTimes()
+		inspectorMock.EXPECT().Emoji(gomock.Any()).DoAndReturn(func(context.Context) (map[string]bool, error) {
+			return standardEmoji, nil
+		}).AnyTimes()
+		slackMock.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(&sl.GetConversationHistoryResponse{}, nil).AnyTimes()
+		slackMock.EXPECT().GetUserByEmailContext(gomock.Any(), "octocat@example.com").Return(&sl.User{ID: "U123"}, nil).AnyTimes()
+	})
+
+	It("passes a working configuration", func() {
+		report := preflight.NewChecker(cfg, githubMock, slackMock, inspectorMock).Run(context.Background())
+
+		Expect(report.Failed()).To(BeFalse())
+		Expect(report.Results).To(HaveEach(HaveField("Status", preflight.StatusOK)))
+		Expect(report.Results).To(HaveLen(6))
+	})
+
+	It("fails on invalid settings", func() {
+		cfg.Schedule.Timezone = "Europe/Londn"
+		cfg.Schedule.Digest.Cron = "every morning"
+		cfg.Schedule.Reminders.Rules = []config.ReminderRule{{Name: "default", RemindAfterHours: 4, EscalateAfterHours: 2, Mention: "everyone"}}
+		report := preflight.NewChecker(cfg, githubMock, slackMock, inspectorMock).Run(context.Background())
+
+		var problems []string
+		for _, result := range report.Results {
+			if result.Status == preflight.StatusFailed {
+				problems = append(problems, result.Message)
+			}
+		}
+		Expect(problems).To(ConsistOf(
+			ContainSubstring(`schedule.timezone "Europe/Londn"`),
+			ContainSubstring(`schedule.digest.cron "every morning"`),
+			ContainSubstring(`reminder rule "default" mentions "everyone"`),
+			ContainSubstring(`reminder rule "default" escalates before it reminds`),
+		))
+	})
+
+	It("fails on a user mapping without both emails", func() {
+		cfg.Slack.GithubEmailToSlackEmail = []config.GithubEmailToSlackEmail{{GithubEmail: "octocat@example.com"}}
+
+		Expect(preflight.Validate(cfg)).To(ConsistOf(`slack.githubEmailToSlackEmail entry 1 needs both githubEmail and slackEmail`))
+	})
+
+	It("fails on an app without a private key", func() {
+		cfg.GitHub.Token = ""
+		cfg.GitHub.App = config.GitHubAppConfiguration{ID: 123, InstallationID: 456}
+
+		Expect(preflight.Validate(cfg)).To(ConsistOf("github.app needs either privateKey or privateKeyFile"))
+	})
+
+	It("fails without a webhook secret", func() {
+		cfg.GitHub.SecretKey = ""
+
+		Expect(preflight.Validate(cfg)).To(ConsistOf("github needs a secretKey or secretKeys to verify webhooks with, unless it only polls"))
+	})
+
+	It("accepts polling without a webhook secret", func() {
+		cfg.GitHub.SecretKey = ""
+		cfg.GitHub.Polling.Enabled = true
+
+		Expect(preflight.Validate(cfg)).To(BeEmpty())
+	})
+
+	It("accepts webhook secrets in secretKeys only", func() {
+		cfg.GitHub.SecretKey = ""
+		cfg.GitHub.SecretKeys = []string{"secret"}
+
+		Expect(preflight.Validate(cfg)).To(BeEmpty())
+	})
+
+	It("fails on vault references without a vault", func() {
+		cfg.Slack.Token = "vault://secret/git-slack-bot#slackToken"
+
+		Expect(preflight.Validate(cfg)).To(ConsistOf("slack.token refers to vault, which needs secrets.vault.address"))
+	})
+
+	It("fails on an unknown log format", func() {
+		cfg.Logging.Format = "xml"
+
+		Expect(preflight.Validate(cfg)).To(ConsistOf(`logging: invalid log format "xml", expected json or text`))
+	})
+
+	It("fails when the team is not in the organization", func() {
+		cfg.GitHub.Team = "plaftorm"
+
+		Expect(results()["github"]).To(Equal(preflight.Result{
+			Check:   "github",
+			Status:  preflight.StatusFailed,
+			Message: `could not find team "plaftorm" in organization "loveholidays": did not find team in organisation`,
+		}))
+	})
+
+	It("fails when the slack token is missing a scope", func() {
+		inspectorMock = mock_preflight.NewMockInspector(gomock.NewController(GinkgoT()))
+		inspectorMock.EXPECT().Scopes(gomock.Any()).Return([]string{"chat:write"}, nil)
+		inspectorMock.EXPECT().Emoji(gomock.Any()).Return(standardEmoji, nil)
+
+		Expect(results()["slack token"].Message).To(Equal("the token is missing the reactions:write scopes"))
+	})
+
+	It("fails when the channel is not accessible", func() {
+		slackMock = mock_slack.NewMockClient(gomock.NewController(GinkgoT()))
+		slackMock.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(nil, errors.New("not_in_channel"))
+		slackMock.EXPECT().GetUserByEmailContext(gomock.Any(), gomock.Any()).Return(&sl.User{}, nil)
+
+		Expect(results()["channel"].Status).To(Equal(preflight.StatusFailed))
+	})
+
+	It("fails on emoji the workspace does not have", func() {
+		cfg.Slack.EmojiConfiguration.Merge = "shipit"
+		cfg.Slack.EmojiConfiguration.Approve = "+1::skin-tone-2"
+
+		Expect(results()["emoji"].Message).To(Equal("the workspace has no :shipit: emoji"))
+	})
+
+	It("only warns if the emoji can't be listed", func() {
+		inspectorMock = mock_preflight.NewMockInspector(gomock.NewController(GinkgoT()))
+		inspectorMock.EXPECT().Scopes(gomock.Any()).Return([]string{"chat:write", "reactions:write"}, nil)
+		inspectorMock.EXPECT().Emoji(gomock.Any()).Return(nil, errors.New("missing_scope"))
+
+		Expect(results()["emoji"].Status).To(Equal(preflight.StatusWarning))
+	})
+
+	It("fails on slack emails that don't resolve", func() {
+		cfg.Slack.GithubEmailToSlackEmail = append(cfg.Slack.GithubEmailToSlackEmail, config.GithubEmailToSlackEmail{GithubEmail: "hubot", SlackEmail: "hubot@example.com"})
+		slackMock.EXPECT().GetUserByEmailContext(gomock.Any(), "hubot@example.com").Return(nil, errors.New("users_not_found"))
+
+		Expect(results()["slack users"].Message).To(Equal("hubot@example.com of github user hubot does not resolve to a slack user: users_not_found"))
+	})
+})
+
+var _ = Describe("Report", func() {
+	It("prints one line per check and a summary", func() {
+		report := preflight.Report{Results: []preflight.Result{
+			{Check: "configuration", Status: preflight.StatusOK, Message: "the configuration is valid"},
+			{Check: "emoji", Status: preflight.StatusFailed, Message: "the workspace has no :shipit: emoji"},
+		}}
+		var out bytes.Buffer
+
+		Expect(report.Write(&out)).To(Succeed())
+
+		Expect(report.Failed()).To(BeTrue())
+		Expect(out.String()).To(Equal("ok    configuration the configuration is valid\n" +
+			"FAIL  emoji         the workspace has no :shipit: emoji\n" +
+			"\n1 of 2 checks failed.\n"))
+	})
+})
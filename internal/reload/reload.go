@@ -0,0 +1,169 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package reload
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/metrics"
+	"git-slack-bot/internal/preflight"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	config_loader "github.com/loveholidays/go-config-loader"
+)
+
+const defaultInterval = 30 * time.Second
+
+// ApplyFunc swaps the settings of a new configuration into the running components.
+type ApplyFunc func(ctx context.Context, cfg *config.Configuration)
+
+// Watcher reloads the configuration file when its content changes or on SIGHUP. A new configuration is only applied
+// if it loads and passes the same checks as check-config does without calling github or slack, otherwise the previous
+// one is kept.
+type Watcher struct {
+	path     string
+	interval time.Duration
+	apply    ApplyFunc
+
+	mutex   sync.Mutex
+	hash    [sha256.Size]byte
+	current *config.Configuration
+}
+
+// NewWatcher creates a Watcher of the configuration file at path, which current was loaded from. A zero interval
+// defaults to 30 seconds.
+func NewWatcher(path string, interval time.Duration, current *config.Configuration, apply ApplyFunc) *Watcher {
+	if interval == 0 {
+		interval = defaultInterval
+	}
+	w := &Watcher{
+		path:     path,
+		interval: interval,
+		apply:    apply,
+		current:  current,
+	}
+	metrics.ConfigLastReloadSuccess.Set(1)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		w.hash = sha256.Sum256(data)
+	}
+	return w
+}
+
+// Run checks the file for changes every interval and reloads it on SIGHUP until ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.logReload(ctx, false)
+		case <-hangup:
+			slog.Info("Reloading configuration on SIGHUP")
+			w.logReload(ctx, true)
+		}
+	}
+}
+
+func (w *Watcher) logReload(ctx context.Context, force bool) {
+	err := w.reload(ctx, force)
+	if err != nil {
+		slog.Error("Rejected reloaded configuration, keeping the previous one", slog.String("path", w.path), slog.Any("error", err))
+	}
+}
+
+// Reload loads the configuration file and applies it if it is valid, whether it has changed or not. The previous
+// configuration is kept if it isn't.
+func (w *Watcher) Reload(ctx context.Context) error {
+	return w.reload(ctx, true)
+}
+
+func (w *Watcher) reload(ctx context.Context, force bool) (err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		metrics.ConfigReloads.WithLabelValues("rejected").Inc()
+		metrics.ConfigLastReloadSuccess.Set(0)
+		return err
+	}
+	hash := sha256.Sum256(data)
+	if hash == w.hash && !force {
+		return nil
+	}
+	defer func() {
+		if err != nil {
+			metrics.ConfigLastReloadSuccess.Set(0)
+		} else {
+			metrics.ConfigLastReloadSuccess.Set(1)
+		}
+	}()
+	// The hash is taken before validating, so a broken file is reported once rather than on every check.
+	w.hash = hash
+
+	cfg, err := config_loader.LoadConfiguration[config.Configuration](w.path)
+	if err != nil {
+		metrics.ConfigReloads.WithLabelValues("rejected").Inc()
+		return err
+	}
+	problems := preflight.Validate(*cfg)
+	if len(problems) > 0 {
+		metrics.ConfigReloads.WithLabelValues("rejected").Inc()
+		return errors.New(strings.Join(problems, "; "))
+	}
+
+	if w.current != nil && !reflect.DeepEqual(withoutReloadable(*w.current), withoutReloadable(*cfg)) {
+		slog.Warn("Configuration changes other than the ignore lists, emoji, user mappings and webhook secrets need a restart")
+	}
+	w.apply(ctx, cfg)
+	w.current = cfg
+	metrics.ConfigReloads.WithLabelValues("applied").Inc()
+	slog.Info("Reloaded configuration", slog.String("path", w.path))
+	return nil
+}
+
+func withoutReloadable(cfg config.Configuration) config.Configuration {
+	cfg.GitHub.IgnoredPRUsers = nil
+	cfg.GitHub.IgnoredRepos = nil
+	cfg.GitHub.IgnoredCommentUsers = nil
+	cfg.GitHub.IgnoredReviewUsers = nil
+	cfg.GitHub.SecretKey = ""
+	cfg.GitHub.SecretKeys = nil
+	cfg.Slack.GithubEmailToSlackEmail = nil
+	cfg.Slack.EmojiConfiguration = config.EmojiConfiguration{}
+	cfg.Reload = config.ReloadConfiguration{}
+	return cfg
+}
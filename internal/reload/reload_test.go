@@ -0,0 +1,142 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package reload_test
+
+import (
+	"context"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/metrics"
+	"git-slack-bot/internal/reload"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestReload(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Reload tests")
+}
+
+const validConfig = `github:
+  token: token
+  team: platform
+  org: loveholidays
+  secretKey: secret
+slack:
+  token: token
+  channelID: C123
+`
+
+var _ = Describe("Watcher", func() {
+	var (
+		path    string
+		applied []*config.Configuration
+		watcher *reload.Watcher
+	)
+
+	write := func(content string) {
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "config.yaml")
+		applied = nil
+		write(validConfig)
+		watcher = reload.NewWatcher(path, 0, nil, func(_ context.Context, cfg *config.Configuration) {
+			applied = append(applied, cfg)
+		})
+	})
+
+	It("applies a valid configuration", func() {
+		write(validConfig + "  githubEmailToSlackEmail:\n    - githubEmail: octocat@example.com\n      slackEmail: octocat@loveholidays.com\n")
+
+		Expect(watcher.Reload(context.Background())).To(Succeed())
+
+		Expect(applied).To(HaveLen(1))
+		Expect(applied[0].Slack.GithubEmailToSlackEmail).To(ConsistOf(config.GithubEmailToSlackEmail{GithubEmail: "octocat@example.com", SlackEmail: "octocat@loveholidays.com"}))
+	})
+
+	It("rejects a configuration which does not load", func() {
+		write("github: [")
+
+		Expect(watcher.Reload(context.Background())).ToNot(Succeed())
+
+		Expect(applied).To(BeEmpty())
+	})
+
+	It("rejects an invalid configuration", func() {
+		write(validConfig + "schedule:\n  timezone: Europe/Londn\n")
+
+		Expect(watcher.Reload(context.Background())).To(MatchError(ContainSubstring(`schedule.timezone "Europe/Londn"`)))
+
+		Expect(applied).To(BeEmpty())
+	})
+
+	It("applies a change once the file is fixed", func() {
+		write(validConfig + "schedule:\n  timezone: Europe/Londn\n")
+		Expect(watcher.Reload(context.Background())).ToNot(Succeed())
+
+		write(validConfig + "schedule:\n  timezone: Europe/London\n")
+		Expect(watcher.Reload(context.Background())).To(Succeed())
+
+		Expect(applied).To(HaveLen(1))
+		Expect(applied[0].Schedule.Timezone).To(Equal("Europe/London"))
+	})
+
+	It("reports a rejected configuration in a gauge until the file is fixed", func() {
+		Expect(testutil.ToFloat64(metrics.ConfigLastReloadSuccess)).To(Equal(1.0))
+
+		write(validConfig + "schedule:\n  timezone: Europe/Londn\n")
+		Expect(watcher.Reload(context.Background())).ToNot(Succeed())
+		Expect(testutil.ToFloat64(metrics.ConfigLastReloadSuccess)).To(Equal(0.0))
+
+		write(validConfig)
+		Expect(watcher.Reload(context.Background())).To(Succeed())
+		Expect(testutil.ToFloat64(metrics.ConfigLastReloadSuccess)).To(Equal(1.0))
+	})
+
+	It("checks the file for changes until the context is cancelled", func() {
+		reloaded := make(chan *config.Configuration, 1)
+		watcher = reload.NewWatcher(path, 10*time.Millisecond, nil, func(_ context.Context, cfg *config.Configuration) {
+			reloaded <- cfg
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			watcher.Run(ctx)
+		}()
+
+		Consistently(reloaded, 50*time.Millisecond).ShouldNot(Receive())
+		write(validConfig + "  shadowChannelID: C456\n")
+
+		var cfg *config.Configuration
+		Eventually(reloaded).Should(Receive(&cfg))
+		Expect(cfg.Slack.ShadowChannelID).To(Equal("C456"))
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+})
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: git-slack-bot/internal/reminder (interfaces: Snoozer)
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mocks/reminder.go . Snoozer
+//
+
+// Package mock_reminder is a generated GoMock package.
+package mock_reminder
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSnoozer is a mock of Snoozer interface.
+type MockSnoozer struct {
+	ctrl     *gomock.Controller
+	recorder *MockSnoozerMockRecorder
+	isgomock struct{}
+}
+
+// MockSnoozerMockRecorder is the mock recorder for MockSnoozer.
+type MockSnoozerMockRecorder struct {
+	mock *MockSnoozer
+}
+
+// NewMockSnoozer creates a new mock instance.
+func NewMockSnoozer(ctrl *gomock.Controller) *MockSnoozer {
+	mock := &MockSnoozer{ctrl: ctrl}
+	mock.recorder = &MockSnoozerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSnoozer) EXPECT() *MockSnoozerMockRecorder {
+	return m.recorder
+}
+
+// Snooze mocks base method.
+func (m *MockSnoozer) Snooze(ctx context.Context, pullRequestURL string) time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snooze", ctx, pullRequestURL)
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// Snooze indicates an expected call of Snooze.
+func (mr *MockSnoozerMockRecorder) Snooze(ctx, pullRequestURL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snooze", reflect.TypeOf((*MockSnoozer)(nil).Snooze), ctx, pullRequestURL)
+}
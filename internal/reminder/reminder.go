@@ -0,0 +1,269 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package reminder
+
+//go:generate mockgen -destination=./mocks/reminder.go . Snoozer
+
+import (
+	"context"
+	"fmt"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/github"
+	messageBuilder "git-slack-bot/internal/messagebuilder"
+	"git-slack-bot/internal/scheduler"
+	"git-slack-bot/internal/slack"
+	"git-slack-bot/internal/tracing"
+	"git-slack-bot/internal/user"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MentionReviewers string = "reviewers"
+	MentionTeam      string = "team"
+
+	defaultTeamMention string = "<!here>"
+
+	defaultSnoozeFor = 24 * time.Hour
+)
+
+// Snoozer pauses the reminders of a single pull request.
+type Snoozer interface {
+	Snooze(ctx context.Context, pullRequestURL string) time.Time
+}
+
+type level int
+
+const (
+	levelNone level = iota
+	levelReminded
+	levelEscalated
+)
+
+// Reminder pings the slack thread of the team's pull requests that have had no review for longer than the first
+// matching rule allows, and escalates to the whole team after the rule's second threshold. What has been sent is
+// only held in memory, so after a restart a pull request can be reminded about once more.
+type Reminder struct {
+	githubConnector github.Interactor
+	slackConnector  slack.Interactor
+	userService     user.Service
+	messageBuilder  messageBuilder.MessageBuilder
+	workingHours    scheduler.WorkingHours
+	rules           []config.ReminderRule
+	teamMention     string
+	snoozeFor       time.Duration
+	// running serializes runs, so that a reminder isn't sent twice. mutex only guards the maps, which are copied
+	// rather than held across the calls to GitHub and slack, so that snoozing doesn't wait for a run.
+	running sync.Mutex
+	mutex   sync.Mutex
+	sent    map[string]level
+	snoozed map[string]time.Time
+}
+
+func NewReminder(githubConnector github.Interactor, slackConnector slack.Interactor, userService user.Service, workingHours scheduler.WorkingHours, cfg config.ReminderConfiguration) *Reminder {
+	teamMention := cfg.TeamMention
+	if teamMention == "" {
+		teamMention = defaultTeamMention
+	}
+	snoozeFor := cfg.SnoozeFor
+	if snoozeFor == 0 {
+		snoozeFor = defaultSnoozeFor
+	}
+	return &Reminder{
+		githubConnector: githubConnector,
+		slackConnector:  slackConnector,
+		userService:     userService,
+		messageBuilder:  messageBuilder.MessageBuilder{},
+		workingHours:    workingHours,
+		rules:           cfg.Rules,
+		teamMention:     teamMention,
+		snoozeFor:       snoozeFor,
+		sent:            make(map[string]level),
+		snoozed:         make(map[string]time.Time),
+	}
+}
+
+// Snooze pauses reminders and escalations for the pull request and returns when they resume.
+func (r *Reminder) Snooze(ctx context.Context, pullRequestURL string) time.Time {
+	return r.SnoozeAt(ctx, pullRequestURL, time.Now())
+}
+
+func (r *Reminder) SnoozeAt(ctx context.Context, pullRequestURL string, now time.Time) time.Time {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	until := now.Add(r.snoozeFor)
+	r.snoozed[pullRequestURL] = until
+	slog.InfoContext(ctx, "Snoozed review reminders", slog.String("pullRequest", pullRequestURL), slog.Time("until", until))
+	return until
+}
+
+func (r *Reminder) Run(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "reminder.Run")
+	defer span.End()
+	r.RunAt(ctx, time.Now())
+}
+
+// RunAt sends the reminders that are due at now. Nothing is sent outside working hours.
+func (r *Reminder) RunAt(ctx context.Context, now time.Time) {
+	if !r.workingHours.Contains(now) {
+		return
+	}
+	teamMembers := r.userService.GetTeamMembers()
+	if len(teamMembers) == 0 {
+		return
+	}
+	pullRequests, err := r.githubConnector.SearchOpenPullRequests(ctx, github.SearchQuery{Authors: teamMembers, Unreviewed: true})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to search unreviewed pull requests", slog.Any("error", err))
+		return
+	}
+
+	r.running.Lock()
+	defer r.running.Unlock()
+	r.mutex.Lock()
+	sent := maps.Clone(r.sent)
+	snoozed := maps.Clone(r.snoozed)
+	r.mutex.Unlock()
+
+	waiting := make(map[string]bool)
+	reminded := make(map[string]level)
+	for _, pullRequest := range pullRequests {
+		waiting[pullRequest.URL] = true
+		until, ok := snoozed[pullRequest.URL]
+		if ok && now.Before(until) {
+			continue
+		}
+		due := r.remind(ctx, pullRequest, sent[pullRequest.URL], now)
+		if due > sent[pullRequest.URL] {
+			reminded[pullRequest.URL] = due
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	maps.Copy(r.sent, reminded)
+	// Anything no longer waiting has been reviewed, merged or closed.
+	for url := range r.sent {
+		if !waiting[url] {
+			delete(r.sent, url)
+		}
+	}
+	for url, until := range r.snoozed {
+		if !waiting[url] || !now.Before(until) {
+			delete(r.snoozed, url)
+		}
+	}
+}
+
+// remind sends the reminder that is due for the pull request, if it is more than the one already sent, and returns
+// the level sent.
+func (r *Reminder) remind(ctx context.Context, pullRequest *github.OpenPullRequest, sent level, now time.Time) level {
+	rule := r.ruleFor(pullRequest.Repo)
+	if rule == nil {
+		return levelNone
+	}
+
+	// Waiting counts from the later of the pull request's creation and its last review activity, which can't make
+	// it longer, so the activity is only looked up for pull requests that would be due otherwise.
+	if dueAfter(rule, r.workingHours.Between(pullRequest.CreatedAt, now)) <= sent {
+		return levelNone
+	}
+	waitingSince := pullRequest.CreatedAt
+	lastActivity, err := r.githubConnector.LastReviewActivity(ctx, pullRequest.Owner, pullRequest.Repo, pullRequest.Number)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get the review activity", slog.String("pullRequest", pullRequest.URL), slog.Any("error", err))
+		return levelNone
+	}
+	if lastActivity.After(waitingSince) {
+		waitingSince = lastActivity
+	}
+	waitingFor := r.workingHours.Between(waitingSince, now)
+	due := dueAfter(rule, waitingFor)
+	if due <= sent {
+		return levelNone
+	}
+
+	messageKey := fmt.Sprintf("<%s>", pullRequest.URL)
+	slackMessage, err := r.slackConnector.GetMessage(ctx, messageKey)
+	if err != nil {
+		slog.ErrorContext(ctx, "Could not find message", slog.Any("messageKey", messageKey), slog.Any("error", err))
+		return levelNone
+	}
+
+	if due == levelEscalated {
+		r.slackConnector.SendReply(ctx, slackMessage, r.messageBuilder.BuildEscalationMessage(r.teamMention, waitingFor))
+	} else {
+		r.slackConnector.SendReply(ctx, slackMessage, r.messageBuilder.BuildReminderMessage(r.mentions(ctx, rule, pullRequest), waitingFor))
+	}
+	slog.InfoContext(ctx, "Sent review reminder", slog.String("pullRequest", pullRequest.URL), slog.String("rule", rule.Name), slog.Int("level", int(due)))
+	return due
+}
+
+// dueAfter returns the level of reminder the rule calls for after waiting for waitingFor.
+func dueAfter(rule *config.ReminderRule, waitingFor time.Duration) level {
+	due := levelNone
+	if waitingFor >= hours(rule.RemindAfterHours) {
+		due = levelReminded
+	}
+	if rule.EscalateAfterHours > 0 && waitingFor >= hours(rule.EscalateAfterHours) {
+		due = levelEscalated
+	}
+	return due
+}
+
+// ruleFor returns the first rule that applies to repo. Rules without repos apply to all of them.
+func (r *Reminder) ruleFor(repo string) *config.ReminderRule {
+	for i := range r.rules {
+		if len(r.rules[i].Repos) == 0 || slices.Contains(r.rules[i].Repos, repo) {
+			return &r.rules[i]
+		}
+	}
+	return nil
+}
+
+// mentions returns who to mention in a first reminder. Pull requests without individually requested reviewers fall
+// back to mentioning the team.
+func (r *Reminder) mentions(ctx context.Context, rule *config.ReminderRule, pullRequest *github.OpenPullRequest) string {
+	if rule.Mention == MentionTeam {
+		return r.teamMention
+	}
+	details, err := r.githubConnector.GetPullRequest(ctx, pullRequest.Owner, pullRequest.Repo, pullRequest.Number)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get requested reviewers", slog.String("pullRequest", pullRequest.URL), slog.Any("error", err))
+		return r.teamMention
+	}
+	var reviewers []string
+	for _, reviewer := range details.RequestedReviewers {
+		reviewers = append(reviewers, r.userService.GetUserDescriptor(ctx, reviewer.GetLogin()))
+	}
+	if len(reviewers) == 0 {
+		return r.teamMention
+	}
+	return strings.Join(reviewers, " ")
+}
+
+func hours(h float64) time.Duration {
+	return time.Duration(h * float64(time.Hour))
+}
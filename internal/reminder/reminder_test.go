@@ -0,0 +1,244 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package reminder_test
+
+import (
+	"context"
+	"errors"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/github"
+	mock_github "git-slack-bot/internal/github/mocks"
+	"git-slack-bot/internal/reminder"
+	"git-slack-bot/internal/scheduler"
+	mock_slack "git-slack-bot/internal/slack/mocks"
+	mock_user "git-slack-bot/internal/user/mocks"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v56/github"
+	"github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestReminder(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Reminder tests")
+}
+
+var _ = Describe("RunAt", func() {
+	var (
+		mockCtrl     *gomock.Controller
+		githubMock   *mock_github.MockInteractor
+		slackMock    *mock_slack.MockInteractor
+		userMock     *mock_user.MockService
+		workingHours scheduler.WorkingHours
+		slackMessage *slack.Message
+		pullRequest  *github.OpenPullRequest
+		cfg          config.ReminderConfiguration
+		lastActivity time.Time
+	)
+
+	// 3rd of March 2025 is a Monday.
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, time.March, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		githubMock = mock_github.NewMockInteractor(mockCtrl)
+		slackMock = mock_slack.NewMockInteractor(mockCtrl)
+		userMock = mock_user.NewMockService(mockCtrl)
+		workingHours = scheduler.NewWorkingHours(time.UTC, 9, 17)
+		slackMessage = &slack.Message{}
+		pullRequest = &github.OpenPullRequest{
+			Number:    7,
+			URL:       "https://github.com/org/repo/pull/7",
+			Owner:     "org",
+			Repo:      "repo",
+			Author:    "alice",
+			CreatedAt: at(3, 9),
+		}
+		cfg = config.ReminderConfiguration{
+			TeamMention: "<!subteam^S123>",
+			Rules: []config.ReminderRule{
+				{Name: "default", RemindAfterHours: 4, EscalateAfterHours: 12, Mention: reminder.MentionReviewers},
+			},
+		}
+
+		lastActivity = time.Time{}
+
+		userMock.EXPECT().GetTeamMembers().Return([]string{"alice"}).AnyTimes()
+		githubMock.EXPECT().LastReviewActivity(gomock.Any(), "org", "repo", 7).DoAndReturn(func(context.Context, string, string, int) (time.Time, error) {
+			return lastActivity, nil
+		}).AnyTimes()
+	})
+
+	It("should mention the requested reviewers once a pull request waited long enough", func() {
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), github.SearchQuery{Authors: []string{"alice"}, Unreviewed: true}).Return([]*github.OpenPullRequest{pullRequest}, nil)
+		slackMock.EXPECT().GetMessage(gomock.Any(), "<https://github.com/org/repo/pull/7>").Return(slackMessage, nil)
+		githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 7).Return(&gh.PullRequest{
+			RequestedReviewers: []*gh.User{{Login: gh.String("bob")}, {Login: gh.String("carol")}},
+		}, nil)
+		userMock.EXPECT().GetUserDescriptor(gomock.Any(), "bob").Return("<@B>")
+		userMock.EXPECT().GetUserDescriptor(gomock.Any(), "carol").Return("carol")
+		slackMock.EXPECT().SendReply(gomock.Any(), slackMessage, "<@B> carol this PR has been waiting for a review for 5 working hours")
+
+		reminder.NewReminder(githubMock, slackMock, userMock, workingHours, cfg).RunAt(context.Background(), at(3, 14))
+	})
+
+	It("should mention the team if no reviewers were requested", func() {
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return([]*github.OpenPullRequest{pullRequest}, nil)
+		slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(slackMessage, nil)
+		githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 7).Return(&gh.PullRequest{}, nil)
+		slackMock.EXPECT().SendReply(gomock.Any(), slackMessage, "<!subteam^S123> this PR has been waiting for a review for 5 working hours")
+
+		reminder.NewReminder(githubMock, slackMock, userMock, workingHours, cfg).RunAt(context.Background(), at(3, 14))
+	})
+
+	It("should mention the team if the rule says so", func() {
+		cfg.Rules[0].Mention = reminder.MentionTeam
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return([]*github.OpenPullRequest{pullRequest}, nil)
+		slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(slackMessage, nil)
+		githubMock.EXPECT().GetPullRequest(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		slackMock.EXPECT().SendReply(gomock.Any(), slackMessage, "<!subteam^S123> this PR has been waiting for a review for 5 working hours")
+
+		reminder.NewReminder(githubMock, slackMock, userMock, workingHours, cfg).RunAt(context.Background(), at(3, 14))
+	})
+
+	It("should not remind before the threshold", func() {
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return([]*github.OpenPullRequest{pullRequest}, nil)
+		slackMock.EXPECT().SendReply(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		reminder.NewReminder(githubMock, slackMock, userMock, workingHours, cfg).RunAt(context.Background(), at(3, 12))
+	})
+
+	It("should remind only once and escalate after the second threshold", func() {
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return([]*github.OpenPullRequest{pullRequest}, nil).Times(3)
+		slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(slackMessage, nil).Times(2)
+		githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 7).Return(&gh.PullRequest{}, nil)
+		gomock.InOrder(
+			slackMock.EXPECT().SendReply(gomock.Any(), slackMessage, "<!subteam^S123> this PR has been waiting for a review for 5 working hours"),
+			slackMock.EXPECT().SendReply(gomock.Any(), slackMessage, ":rotating_light: <!subteam^S123> this PR still hasn't been reviewed after 12 working hours, could someone pick it up?"),
+		)
+
+		remind := reminder.NewReminder(githubMock, slackMock, userMock, workingHours, cfg)
+		remind.RunAt(context.Background(), at(3, 14))
+		remind.RunAt(context.Background(), at(3, 16))
+		remind.RunAt(context.Background(), at(4, 13))
+	})
+
+	It("should count the wait from the last review activity", func() {
+		lastActivity = at(3, 12)
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return([]*github.OpenPullRequest{pullRequest}, nil).Times(2)
+		slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(slackMessage, nil)
+		githubMock.EXPECT().GetPullRequest(gomock.Any(), "org", "repo", 7).Return(&gh.PullRequest{}, nil)
+		slackMock.EXPECT().SendReply(gomock.Any(), slackMessage, "<!subteam^S123> this PR has been waiting for a review for 4 working hours")
+
+		remind := reminder.NewReminder(githubMock, slackMock, userMock, workingHours, cfg)
+		remind.RunAt(context.Background(), at(3, 14))
+		remind.RunAt(context.Background(), at(3, 16))
+	})
+
+	It("should not remind if the review activity can't be looked up", func() {
+		pullRequest.Number = 8
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return([]*github.OpenPullRequest{pullRequest}, nil)
+		githubMock.EXPECT().LastReviewActivity(gomock.Any(), "org", "repo", 8).Return(time.Time{}, errors.New("rate limited"))
+		slackMock.EXPECT().SendReply(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		reminder.NewReminder(githubMock, slackMock, userMock, workingHours, cfg).RunAt(context.Background(), at(3, 14))
+	})
+
+	It("should not hold up snoozing while reminding", func() {
+		cfg.Rules[0].Mention = reminder.MentionTeam
+		remind := reminder.NewReminder(githubMock, slackMock, userMock, workingHours, cfg)
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return([]*github.OpenPullRequest{pullRequest}, nil)
+		slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(slackMessage, nil)
+		slackMock.EXPECT().SendReply(gomock.Any(), slackMessage, gomock.Any()).Do(func(context.Context, *slack.Message, string) {
+			remind.SnoozeAt(context.Background(), pullRequest.URL, at(3, 14))
+		})
+
+		remind.RunAt(context.Background(), at(3, 14))
+	})
+
+	It("should not remind outside working hours", func() {
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Times(0)
+
+		reminder.NewReminder(githubMock, slackMock, userMock, workingHours, cfg).RunAt(context.Background(), at(3, 20))
+	})
+
+	It("should use the first rule matching the repo", func() {
+		cfg.Rules = []config.ReminderRule{
+			{Name: "other", Repos: []string{"other"}, RemindAfterHours: 1},
+			{Name: "slow", Repos: []string{"repo"}, RemindAfterHours: 40},
+			{Name: "default", RemindAfterHours: 1},
+		}
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return([]*github.OpenPullRequest{pullRequest}, nil)
+		slackMock.EXPECT().SendReply(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		reminder.NewReminder(githubMock, slackMock, userMock, workingHours, cfg).RunAt(context.Background(), at(3, 14))
+	})
+
+	It("should not remind if no rule matches the repo", func() {
+		cfg.Rules = []config.ReminderRule{{Name: "other", Repos: []string{"other"}, RemindAfterHours: 1}}
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return([]*github.OpenPullRequest{pullRequest}, nil)
+		slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Times(0)
+
+		reminder.NewReminder(githubMock, slackMock, userMock, workingHours, cfg).RunAt(context.Background(), at(3, 14))
+	})
+
+	It("should remind again once a reviewed pull request is waiting again", func() {
+		cfg.Rules[0].Mention = reminder.MentionTeam
+		gomock.InOrder(
+			githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return([]*github.OpenPullRequest{pullRequest}, nil),
+			githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return(nil, nil),
+			githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return([]*github.OpenPullRequest{pullRequest}, nil),
+		)
+		slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(slackMessage, nil).Times(2)
+		slackMock.EXPECT().SendReply(gomock.Any(), slackMessage, gomock.Any()).Times(2)
+
+		remind := reminder.NewReminder(githubMock, slackMock, userMock, workingHours, cfg)
+		remind.RunAt(context.Background(), at(3, 14))
+		remind.RunAt(context.Background(), at(3, 15))
+		remind.RunAt(context.Background(), at(3, 16))
+	})
+
+	It("should not remind if searching fails", func() {
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return(nil, errors.New("rate limited"))
+		slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Times(0)
+
+		reminder.NewReminder(githubMock, slackMock, userMock, workingHours, cfg).RunAt(context.Background(), at(3, 14))
+	})
+
+	It("should not remind a snoozed pull request until the snooze ends", func() {
+		cfg.Rules[0].Mention = reminder.MentionTeam
+		cfg.SnoozeFor = 2 * time.Hour
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return([]*github.OpenPullRequest{pullRequest}, nil).Times(2)
+		slackMock.EXPECT().GetMessage(gomock.Any(), gomock.Any()).Return(slackMessage, nil)
+		slackMock.EXPECT().SendReply(gomock.Any(), slackMessage, gomock.Any())
+
+		remind := reminder.NewReminder(githubMock, slackMock, userMock, workingHours, cfg)
+		Expect(remind.SnoozeAt(context.Background(), pullRequest.URL, at(3, 13))).To(Equal(at(3, 15)))
+		remind.RunAt(context.Background(), at(3, 14))
+		remind.RunAt(context.Background(), at(3, 15))
+	})
+})
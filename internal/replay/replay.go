@@ -0,0 +1,85 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"git-slack-bot/internal/handler"
+	"io"
+	"log/slog"
+)
+
+// Event is a recorded webhook delivery, the value of the X-GitHub-Event header and the body.
+type Event struct {
+	Event   string          `json:"event"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Read reads a stream of json values, a single json file or json lines alike. A value is either an Event envelope
+// or a bare payload, which is given defaultEvent as its type.
+func Read(r io.Reader, defaultEvent string) ([]Event, error) {
+	var events []Event
+	decoder := json.NewDecoder(r)
+	for {
+		var value json.RawMessage
+		err := decoder.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("event %d: %w", len(events)+1, err)
+		}
+
+		event, err := parse(value, defaultEvent)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: %w", len(events)+1, err)
+		}
+		events = append(events, event)
+	}
+}
+
+func parse(value json.RawMessage, defaultEvent string) (Event, error) {
+	var envelope Event
+	err := json.Unmarshal(value, &envelope)
+	if err != nil {
+		return Event{}, err
+	}
+	if envelope.Event != "" && len(envelope.Payload) > 0 {
+		return envelope, nil
+	}
+	if defaultEvent == "" {
+		return Event{}, errors.New("no event type, wrap the payload in {\"event\": ..., \"payload\": ...} or pass one")
+	}
+	return Event{Event: defaultEvent, Payload: bytes.Clone(value)}, nil
+}
+
+// Run feeds the events to gitHandler in order, as if github had delivered them.
+func Run(ctx context.Context, events []Event, gitHandler handler.GitEventHandler) {
+	for i, event := range events {
+		slog.Info("Replaying event", slog.Int("number", i+1), slog.String("event", event.Event))
+		if !handler.Dispatch(ctx, gitHandler, event.Event, event.Payload) {
+			slog.Warn("Skipping unsupported event", slog.Int("number", i+1), slog.String("event", event.Event))
+		}
+	}
+}
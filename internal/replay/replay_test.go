@@ -0,0 +1,94 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package replay_test
+
+import (
+	"context"
+	mock_handler "git-slack-bot/internal/handler/mocks"
+	"git-slack-bot/internal/replay"
+	"os"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestReplay(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Replay tests")
+}
+
+var _ = Describe("Read", func() {
+	It("reads json lines of envelopes", func() {
+		events, err := replay.Read(strings.NewReader(
+			`{"event": "pull_request", "payload": {"action": "opened"}}`+"\n"+
+				`{"event": "pull_request_review", "payload": {"action": "submitted"}}`+"\n"), "")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Event).To(Equal("pull_request"))
+		Expect(string(events[0].Payload)).To(Equal(`{"action": "opened"}`))
+		Expect(events[1].Event).To(Equal("pull_request_review"))
+	})
+
+	It("reads a recorded payload file with the given event type", func() {
+		file, err := os.Open("../handler/example-requests/pr-opened.json")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(file.Close)
+
+		events, err := replay.Read(file, "pull_request")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Event).To(Equal("pull_request"))
+		Expect(string(events[0].Payload)).To(ContainSubstring(`"action": "opened"`))
+	})
+
+	It("fails on bare payloads without an event type", func() {
+		_, err := replay.Read(strings.NewReader(`{"action": "opened"}`), "")
+
+		Expect(err).To(MatchError(ContainSubstring("event 1")))
+	})
+
+	It("fails on invalid json", func() {
+		_, err := replay.Read(strings.NewReader(`{"event": "push", "payload": {}}`+"\n{"), "")
+
+		Expect(err).To(MatchError(ContainSubstring("event 2")))
+	})
+})
+
+var _ = Describe("Run", func() {
+	It("dispatches the events in order and skips unsupported ones", func() {
+		gitHandlerMock := mock_handler.NewMockGitEventHandler(gomock.NewController(GinkgoT()))
+		gomock.InOrder(
+			gitHandlerMock.EXPECT().HandlePullRequestEvent(gomock.Any(), []byte(`{"action":"opened"}`)),
+			gitHandlerMock.EXPECT().HandleIssueCommentEvent(gomock.Any(), []byte(`{"action":"created"}`)),
+		)
+
+		replay.Run(context.Background(), []replay.Event{
+			{Event: "pull_request", Payload: []byte(`{"action":"opened"}`)},
+			{Event: "star", Payload: []byte(`{"action":"created"}`)},
+			{Event: "issue_comment", Payload: []byte(`{"action":"created"}`)},
+		}, gitHandlerMock)
+	})
+})
@@ -0,0 +1,214 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package reviewqueue
+
+import (
+	"context"
+	"git-slack-bot/internal/github"
+	messageBuilder "git-slack-bot/internal/messagebuilder"
+	"git-slack-bot/internal/slack"
+	"git-slack-bot/internal/tracing"
+	"git-slack-bot/internal/user"
+	"log/slog"
+	"sync"
+	"time"
+
+	sl "github.com/slack-go/slack"
+)
+
+const (
+	defaultHour = 9
+	// slackUserTTL is how long the slack user of a subscriber, and so their timezone, is cached for.
+	slackUserTTL = 24 * time.Hour
+)
+
+// ReviewQueue sends each subscribed user a direct message listing the pull requests waiting on their review and
+// their own pull requests with feedback they haven't been sent yet: reviews with comments or requested changes, and
+// unresolved review threads. The message goes out once per weekday at the configured hour in the user's slack
+// timezone, so it is meant to be run a few times an hour. What has been sent is only held in memory, so after a
+// restart the feedback is sent once more.
+type ReviewQueue struct {
+	githubConnector github.Interactor
+	slackConnector  slack.Interactor
+	userService     user.Service
+	messageBuilder  messageBuilder.MessageBuilder
+	location        *time.Location
+	hour            int
+	mutex           sync.Mutex
+	sent            map[string]string
+	// feedbackSeen is, per subscriber, when the latest feedback they have been sent was given.
+	feedbackSeen map[string]time.Time
+	slackUsers   map[string]cachedSlackUser
+}
+
+type cachedSlackUser struct {
+	user      *sl.User
+	fetchedAt time.Time
+}
+
+// NewReviewQueue creates a ReviewQueue delivering at hour o'clock. Users without a slack timezone get it at that hour
+// in location. An hour of 0 defaults to 9.
+func NewReviewQueue(githubConnector github.Interactor, slackConnector slack.Interactor, userService user.Service, location *time.Location, hour int) *ReviewQueue {
+	if hour <= 0 || hour > 23 {
+		hour = defaultHour
+	}
+	return &ReviewQueue{
+		githubConnector: githubConnector,
+		slackConnector:  slackConnector,
+		userService:     userService,
+		messageBuilder:  messageBuilder.MessageBuilder{},
+		location:        location,
+		hour:            hour,
+		sent:            make(map[string]string),
+		feedbackSeen:    make(map[string]time.Time),
+		slackUsers:      make(map[string]cachedSlackUser),
+	}
+}
+
+func (q *ReviewQueue) Send(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "reviewqueue.Send")
+	defer span.End()
+	q.SendAt(ctx, time.Now())
+}
+
+// subscriber is a subscriber whose review queue is due.
+type subscriber struct {
+	githubLogin string
+	slackUserID string
+	today       string
+}
+
+// SendAt sends the review queue to every subscriber for whom now is within the delivery hour and who has not had it
+// yet today. The feedback on the pull requests of all of them is looked up at once.
+func (q *ReviewQueue) SendAt(ctx context.Context, now time.Time) {
+	// The subscribers are those of the current configuration, which a reload may have added the first of.
+	subscribers := q.userService.GetReviewQueueSubscribers()
+	if len(subscribers) == 0 {
+		return
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var due []subscriber
+	var authors []string
+	for _, githubLogin := range subscribers {
+		slackUser, err := q.slackUser(ctx, githubLogin, now)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get slack user for review queue", slog.String("user", githubLogin), slog.Any("error", err))
+			continue
+		}
+		local := now.In(q.locationOf(ctx, slackUser))
+		if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday || local.Hour() != q.hour {
+			continue
+		}
+		today := local.Format(time.DateOnly)
+		if q.sent[githubLogin] == today {
+			continue
+		}
+		due = append(due, subscriber{githubLogin: githubLogin, slackUserID: slackUser.ID, today: today})
+		authors = append(authors, githubLogin)
+	}
+	if len(due) == 0 {
+		return
+	}
+
+	feedback, err := q.githubConnector.SearchReviewFeedback(ctx, authors)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to search feedback on pull requests", slog.Any("error", err))
+		return
+	}
+	feedbackByAuthor := make(map[string][]*github.ReviewFeedback)
+	for _, pullRequestFeedback := range feedback {
+		author := pullRequestFeedback.PullRequest.Author
+		feedbackByAuthor[author] = append(feedbackByAuthor[author], pullRequestFeedback)
+	}
+	for _, subscriber := range due {
+		if q.send(ctx, subscriber, feedbackByAuthor[subscriber.githubLogin], now) {
+			q.sent[subscriber.githubLogin] = subscriber.today
+		}
+	}
+}
+
+func (q *ReviewQueue) send(ctx context.Context, subscriber subscriber, feedback []*github.ReviewFeedback, now time.Time) bool {
+	toReview, err := q.githubConnector.SearchOpenPullRequests(ctx, github.SearchQuery{ReviewRequested: subscriber.githubLogin, IgnoreReview: true})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to search pull requests waiting on review", slog.String("user", subscriber.githubLogin), slog.Any("error", err))
+		return false
+	}
+
+	var toReviewEntries, feedbackEntries []messageBuilder.DigestEntry
+	for _, pullRequest := range toReview {
+		toReviewEntries = append(toReviewEntries, messageBuilder.DigestEntry{
+			UserDescriptor: q.userService.GetUserDescriptor(ctx, pullRequest.Author),
+			PullRequest:    pullRequest,
+			Age:            now.Sub(pullRequest.CreatedAt),
+		})
+	}
+	seen := q.feedbackSeen[subscriber.githubLogin]
+	latest := seen
+	for _, pullRequestFeedback := range feedback {
+		if !pullRequestFeedback.LastFeedbackAt.After(seen) {
+			continue
+		}
+		feedbackEntries = append(feedbackEntries, messageBuilder.DigestEntry{
+			PullRequest: pullRequestFeedback.PullRequest,
+			Age:         now.Sub(pullRequestFeedback.PullRequest.CreatedAt),
+		})
+		if pullRequestFeedback.LastFeedbackAt.After(latest) {
+			latest = pullRequestFeedback.LastFeedbackAt
+		}
+	}
+
+	if len(toReviewEntries) == 0 && len(feedbackEntries) == 0 {
+		slog.DebugContext(ctx, "Review queue is empty", slog.String("user", subscriber.githubLogin))
+		return true
+	}
+	q.slackConnector.SendDirectMessage(ctx, subscriber.slackUserID, q.messageBuilder.BuildReviewQueueMessage(toReviewEntries, feedbackEntries))
+	q.feedbackSeen[subscriber.githubLogin] = latest
+	slog.InfoContext(ctx, "Sent review queue", slog.String("user", subscriber.githubLogin), slog.Int("toReview", len(toReviewEntries)), slog.Int("feedback", len(feedbackEntries)))
+	return true
+}
+
+// slackUser returns the slack user of the subscriber, looking it up again once a day.
+func (q *ReviewQueue) slackUser(ctx context.Context, githubLogin string, now time.Time) (*sl.User, error) {
+	cached, ok := q.slackUsers[githubLogin]
+	if ok && now.Sub(cached.fetchedAt) < slackUserTTL {
+		return cached.user, nil
+	}
+	slackUser, err := q.userService.GetSlackUser(ctx, githubLogin)
+	if err != nil {
+		return nil, err
+	}
+	q.slackUsers[githubLogin] = cachedSlackUser{user: slackUser, fetchedAt: now}
+	return slackUser, nil
+}
+
+// locationOf returns the slack user's timezone, falling back to the team's.
+func (q *ReviewQueue) locationOf(ctx context.Context, slackUser *sl.User) *time.Location {
+	if slackUser.TZ == "" {
+		return q.location
+	}
+	location, err := time.LoadLocation(slackUser.TZ)
+	if err != nil {
+		slog.WarnContext(ctx, "Unknown slack timezone", slog.String("user", slackUser.ID), slog.String("timezone", slackUser.TZ), slog.Any("error", err))
+		return q.location
+	}
+	return location
+}
@@ -0,0 +1,220 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package reviewqueue_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"git-slack-bot/internal/github"
+	mock_github "git-slack-bot/internal/github/mocks"
+	"git-slack-bot/internal/reviewqueue"
+	mock_slack "git-slack-bot/internal/slack/mocks"
+	mock_user "git-slack-bot/internal/user/mocks"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestReviewQueue(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Review queue tests")
+}
+
+var _ = Describe("SendAt", func() {
+	var (
+		mockCtrl    *gomock.Controller
+		githubMock  *mock_github.MockInteractor
+		slackMock   *mock_slack.MockInteractor
+		userMock    *mock_user.MockService
+		queue       *reviewqueue.ReviewQueue
+		subscribers []string
+	)
+
+	// 3rd of March 2025 is a Monday.
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, time.March, day, hour, 5, 0, 0, time.UTC)
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		githubMock = mock_github.NewMockInteractor(mockCtrl)
+		slackMock = mock_slack.NewMockInteractor(mockCtrl)
+		userMock = mock_user.NewMockService(mockCtrl)
+		queue = reviewqueue.NewReviewQueue(githubMock, slackMock, userMock, time.UTC, 9)
+
+		subscribers = []string{"bob"}
+		userMock.EXPECT().GetReviewQueueSubscribers().DoAndReturn(func() []string { return subscribers }).AnyTimes()
+	})
+
+	It("should send the review queue once at the delivery hour in the user's timezone", func() {
+		// 9 o'clock in New York is 14 o'clock UTC in March before daylight saving starts.
+		userMock.EXPECT().GetSlackUser(gomock.Any(), "bob").Return(&slack.User{ID: "B", TZ: "America/New_York"}, nil)
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), github.SearchQuery{ReviewRequested: "bob", IgnoreReview: true}).Return([]*github.OpenPullRequest{
+			{Title: "Add caching", URL: "https://github.com/org/repo/pull/1", Repo: "repo", Author: "alice", CreatedAt: at(3, 8)},
+		}, nil)
+		githubMock.EXPECT().SearchReviewFeedback(gomock.Any(), []string{"bob"}).Return([]*github.ReviewFeedback{
+			{
+				PullRequest:    &github.OpenPullRequest{Title: "Needs work", URL: "https://github.com/org/repo/pull/3", Repo: "repo", Author: "bob", CreatedAt: at(3, 8)},
+				LastFeedbackAt: at(3, 10),
+			},
+		}, nil)
+		userMock.EXPECT().GetUserDescriptor(gomock.Any(), "alice").Return("<@A>")
+		slackMock.EXPECT().SendDirectMessage(gomock.Any(), "B", "*Your review queue for today*\n\n"+
+			"*Waiting on your review (1)*\n"+
+			"• <https://github.com/org/repo/pull/1|Add caching> in `repo` by <@A>, opened today\n\n"+
+			"*New feedback on your PRs (1)*\n"+
+			"• <https://github.com/org/repo/pull/3|Needs work> in `repo`, opened today")
+
+		queue.SendAt(context.Background(), at(3, 9))
+		queue.SendAt(context.Background(), at(3, 14))
+		queue.SendAt(context.Background(), at(3, 14).Add(30*time.Minute))
+	})
+
+	It("should only send feedback that has not been sent before", func() {
+		userMock.EXPECT().GetSlackUser(gomock.Any(), "bob").Return(&slack.User{ID: "B"}, nil).Times(2)
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+		pullRequest := func(number int) *github.OpenPullRequest {
+			return &github.OpenPullRequest{Title: "Needs work", URL: fmt.Sprintf("https://github.com/org/repo/pull/%d", number), Repo: "repo", Author: "bob", CreatedAt: at(3, 8)}
+		}
+		gomock.InOrder(
+			githubMock.EXPECT().SearchReviewFeedback(gomock.Any(), []string{"bob"}).Return([]*github.ReviewFeedback{
+				{PullRequest: pullRequest(1), LastFeedbackAt: at(3, 8)},
+			}, nil),
+			githubMock.EXPECT().SearchReviewFeedback(gomock.Any(), []string{"bob"}).Return([]*github.ReviewFeedback{
+				{PullRequest: pullRequest(1), LastFeedbackAt: at(3, 8)},
+				{PullRequest: pullRequest(2), UnresolvedThreads: 1, LastFeedbackAt: at(4, 8)},
+			}, nil),
+		)
+		var messages []string
+		slackMock.EXPECT().SendDirectMessage(gomock.Any(), "B", gomock.Any()).Do(func(_ context.Context, _, message string) {
+			messages = append(messages, message)
+		}).Times(2)
+
+		queue.SendAt(context.Background(), at(3, 9))
+		queue.SendAt(context.Background(), at(4, 9))
+
+		Expect(messages[0]).To(ContainSubstring("pull/1|"))
+		Expect(messages[1]).To(And(ContainSubstring("pull/2|"), Not(ContainSubstring("pull/1|"))))
+	})
+
+	It("should look up the feedback for all due subscribers at once", func() {
+		subscribers = []string{"alice", "bob", "carol"}
+		userMock.EXPECT().GetSlackUser(gomock.Any(), "alice").Return(&slack.User{ID: "A"}, nil)
+		userMock.EXPECT().GetSlackUser(gomock.Any(), "bob").Return(&slack.User{ID: "B"}, nil)
+		// 9 o'clock UTC is 4 o'clock in New York, so carol isn't due yet.
+		userMock.EXPECT().GetSlackUser(gomock.Any(), "carol").Return(&slack.User{ID: "C", TZ: "America/New_York"}, nil)
+		githubMock.EXPECT().SearchReviewFeedback(gomock.Any(), []string{"alice", "bob"}).Return([]*github.ReviewFeedback{
+			{
+				PullRequest:    &github.OpenPullRequest{Title: "Needs work", URL: "https://github.com/org/repo/pull/3", Repo: "repo", Author: "bob", CreatedAt: at(3, 8)},
+				LastFeedbackAt: at(3, 8),
+			},
+		}, nil)
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), github.SearchQuery{ReviewRequested: "alice", IgnoreReview: true}).Return(nil, nil)
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), github.SearchQuery{ReviewRequested: "bob", IgnoreReview: true}).Return(nil, nil)
+		slackMock.EXPECT().SendDirectMessage(gomock.Any(), "B", "*Your review queue for today*\n\n"+
+			"*New feedback on your PRs (1)*\n"+
+			"• <https://github.com/org/repo/pull/3|Needs work> in `repo`, opened today")
+
+		queue.SendAt(context.Background(), at(3, 9))
+	})
+
+	It("should send to subscribers added by a reload", func() {
+		subscribers = nil
+		userMock.EXPECT().GetSlackUser(gomock.Any(), gomock.Any()).Times(0)
+		queue.SendAt(context.Background(), at(3, 9))
+
+		subscribers = []string{"bob"}
+		userMock.EXPECT().GetSlackUser(gomock.Any(), "bob").Return(&slack.User{ID: "B"}, nil)
+		githubMock.EXPECT().SearchReviewFeedback(gomock.Any(), []string{"bob"}).Return(nil, nil)
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return([]*github.OpenPullRequest{
+			{Title: "Add caching", URL: "https://github.com/org/repo/pull/1", Repo: "repo", Author: "alice", CreatedAt: at(3, 8)},
+		}, nil)
+		userMock.EXPECT().GetUserDescriptor(gomock.Any(), "alice").Return("<@A>")
+		slackMock.EXPECT().SendDirectMessage(gomock.Any(), "B", gomock.Any())
+		queue.SendAt(context.Background(), at(3, 9))
+	})
+
+	It("should fall back to the team timezone", func() {
+		userMock.EXPECT().GetSlackUser(gomock.Any(), "bob").Return(&slack.User{ID: "B"}, nil)
+		githubMock.EXPECT().SearchReviewFeedback(gomock.Any(), gomock.Any()).Return(nil, nil)
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return(nil, nil)
+		slackMock.EXPECT().SendDirectMessage(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		queue.SendAt(context.Background(), at(3, 9))
+	})
+
+	It("should not send on weekends", func() {
+		userMock.EXPECT().GetSlackUser(gomock.Any(), "bob").Return(&slack.User{ID: "B"}, nil)
+		githubMock.EXPECT().SearchReviewFeedback(gomock.Any(), gomock.Any()).Times(0)
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Times(0)
+
+		queue.SendAt(context.Background(), at(1, 9))
+	})
+
+	It("should try again on the next run if searching fails", func() {
+		userMock.EXPECT().GetSlackUser(gomock.Any(), "bob").Return(&slack.User{ID: "B"}, nil)
+		githubMock.EXPECT().SearchReviewFeedback(gomock.Any(), []string{"bob"}).Return(nil, nil).Times(2)
+		gomock.InOrder(
+			githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return(nil, errors.New("rate limited")),
+			githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), github.SearchQuery{ReviewRequested: "bob", IgnoreReview: true}).Return([]*github.OpenPullRequest{
+				{Title: "Add caching", URL: "https://github.com/org/repo/pull/1", Repo: "repo", Author: "alice", CreatedAt: at(3, 8)},
+			}, nil),
+		)
+		userMock.EXPECT().GetUserDescriptor(gomock.Any(), "alice").Return("alice")
+		slackMock.EXPECT().SendDirectMessage(gomock.Any(), "B", gomock.Any())
+
+		queue.SendAt(context.Background(), at(3, 9))
+		queue.SendAt(context.Background(), at(3, 9).Add(15*time.Minute))
+	})
+
+	It("should try again on the next run if looking up feedback fails", func() {
+		userMock.EXPECT().GetSlackUser(gomock.Any(), "bob").Return(&slack.User{ID: "B"}, nil)
+		gomock.InOrder(
+			githubMock.EXPECT().SearchReviewFeedback(gomock.Any(), gomock.Any()).Return(nil, errors.New("rate limited")),
+			githubMock.EXPECT().SearchReviewFeedback(gomock.Any(), gomock.Any()).Return(nil, nil),
+		)
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		queue.SendAt(context.Background(), at(3, 9))
+		queue.SendAt(context.Background(), at(3, 9).Add(15*time.Minute))
+	})
+
+	It("should look up the slack user again after a day", func() {
+		userMock.EXPECT().GetSlackUser(gomock.Any(), "bob").Return(&slack.User{ID: "B"}, nil).Times(2)
+
+		queue.SendAt(context.Background(), at(1, 9))
+		queue.SendAt(context.Background(), at(1, 12))
+		queue.SendAt(context.Background(), at(2, 10))
+	})
+
+	It("should skip users without a slack account", func() {
+		userMock.EXPECT().GetSlackUser(gomock.Any(), "bob").Return(nil, errors.New("not found"))
+		githubMock.EXPECT().SearchReviewFeedback(gomock.Any(), gomock.Any()).Times(0)
+		githubMock.EXPECT().SearchOpenPullRequests(gomock.Any(), gomock.Any()).Times(0)
+
+		queue.SendAt(context.Background(), at(3, 9))
+	})
+})
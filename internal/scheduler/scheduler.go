@@ -0,0 +1,87 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Scheduler runs jobs on cron schedules evaluated in the team's timezone.
+type Scheduler struct {
+	cron     *cron.Cron
+	location *time.Location
+	ctx      context.Context
+}
+
+func NewScheduler(location *time.Location) *Scheduler {
+	return &Scheduler{
+		cron:     cron.New(cron.WithLocation(location)),
+		location: location,
+		ctx:      context.Background(),
+	}
+}
+
+// AddWeekdayJob registers job to run on the standard 5 field cron spec, skipping runs that fall on a weekend.
+func (s *Scheduler) AddWeekdayJob(name, spec string, job func(ctx context.Context)) error {
+	return s.AddJob(name, spec, func(ctx context.Context) {
+		if !s.IsWorkday(time.Now()) {
+			slog.Debug("Skipping scheduled job on weekend", slog.String("job", name))
+			return
+		}
+		job(ctx)
+	})
+}
+
+// AddJob registers job to run on the standard 5 field cron spec every day of the week. The job is passed the context
+// the scheduler was started with, which is cancelled on shutdown.
+func (s *Scheduler) AddJob(name, spec string, job func(ctx context.Context)) error {
+	_, err := s.cron.AddFunc(spec, func() {
+		if s.ctx.Err() != nil {
+			return
+		}
+		slog.Info("Running scheduled job", slog.String("job", name))
+		job(s.ctx)
+	})
+	return err
+}
+
+// IsWorkday reports whether t falls on a weekday in the scheduler's timezone.
+func (s *Scheduler) IsWorkday(t time.Time) bool {
+	return isWeekday(t.In(s.location))
+}
+
+func (s *Scheduler) Location() *time.Location {
+	return s.location
+}
+
+// Start runs the jobs on their schedules until Stop is called. ctx is passed on to the jobs.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.ctx = ctx
+	s.cron.Start()
+}
+
+// Stop prevents new runs from starting and waits for running jobs to finish.
+func (s *Scheduler) Stop() {
+	<-s.cron.Stop().Done()
+}
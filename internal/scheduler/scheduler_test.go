@@ -0,0 +1,79 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package scheduler_test
+
+import (
+	"context"
+	"git-slack-bot/internal/scheduler"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestScheduler(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Scheduler tests")
+}
+
+var _ = Describe("Scheduler", func() {
+	var (
+		london    *time.Location
+		scheduled *scheduler.Scheduler
+	)
+
+	BeforeEach(func() {
+		var err error
+		london, err = time.LoadLocation("Europe/London")
+		Expect(err).ToNot(HaveOccurred())
+		scheduled = scheduler.NewScheduler(london)
+	})
+
+	Context("IsWorkday", func() {
+		It("should treat weekdays as workdays", func() {
+			Expect(scheduled.IsWorkday(time.Date(2025, time.March, 3, 9, 0, 0, 0, london))).To(BeTrue())
+		})
+
+		It("should not treat weekends as workdays", func() {
+			Expect(scheduled.IsWorkday(time.Date(2025, time.March, 1, 9, 0, 0, 0, london))).To(BeFalse())
+			Expect(scheduled.IsWorkday(time.Date(2025, time.March, 2, 9, 0, 0, 0, london))).To(BeFalse())
+		})
+
+		It("should evaluate the weekday in the scheduler timezone", func() {
+			sydney, err := time.LoadLocation("Australia/Sydney")
+			Expect(err).ToNot(HaveOccurred())
+
+			mondayMorningInSydney := time.Date(2025, time.March, 3, 8, 0, 0, 0, sydney)
+
+			Expect(scheduled.IsWorkday(mondayMorningInSydney)).To(BeFalse())
+		})
+	})
+
+	Context("AddWeekdayJob", func() {
+		It("should accept a standard cron spec", func() {
+			Expect(scheduled.AddWeekdayJob("digest", "0 9 * * *", func(context.Context) {})).To(Succeed())
+		})
+
+		It("should reject an invalid cron spec", func() {
+			Expect(scheduled.AddWeekdayJob("digest", "every morning", func(context.Context) {})).ToNot(Succeed())
+		})
+	})
+})
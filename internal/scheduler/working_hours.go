@@ -0,0 +1,96 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package scheduler
+
+import "time"
+
+const (
+	defaultWorkdayStart = 9
+	defaultWorkdayEnd   = 17
+)
+
+// WorkingHours describes the hours of the working week, Monday to Friday, in the team's timezone.
+type WorkingHours struct {
+	location *time.Location
+	start    int
+	end      int
+}
+
+// NewWorkingHours creates WorkingHours from start until end o'clock. If end is not after start the hours default to
+// 9 to 17.
+func NewWorkingHours(location *time.Location, start, end int) WorkingHours {
+	if end <= start {
+		start, end = defaultWorkdayStart, defaultWorkdayEnd
+	}
+	return WorkingHours{
+		location: location,
+		start:    start,
+		end:      end,
+	}
+}
+
+// Contains reports whether t falls within working hours.
+func (w WorkingHours) Contains(t time.Time) bool {
+	dayStart, dayEnd := w.day(t)
+	return !t.Before(dayStart) && t.Before(dayEnd) && isWeekday(t.In(w.location))
+}
+
+// Between returns how much working time passed from from until to.
+func (w WorkingHours) Between(from, to time.Time) time.Duration {
+	var total time.Duration
+	for day := from; day.Before(to); {
+		dayStart, dayEnd := w.day(day)
+		if isWeekday(dayStart) {
+			windowStart := latest(dayStart, from)
+			windowEnd := earliest(dayEnd, to)
+			if windowEnd.After(windowStart) {
+				total += windowEnd.Sub(windowStart)
+			}
+		}
+		local := day.In(w.location)
+		day = time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, w.location)
+	}
+	return total
+}
+
+func (w WorkingHours) day(t time.Time) (time.Time, time.Time) {
+	local := t.In(w.location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), w.start, 0, 0, 0, w.location)
+	end := time.Date(local.Year(), local.Month(), local.Day(), w.end, 0, 0, 0, w.location)
+	return start, end
+}
+
+func isWeekday(t time.Time) bool {
+	return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,91 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package scheduler_test
+
+import (
+	"git-slack-bot/internal/scheduler"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WorkingHours", func() {
+	var (
+		london       *time.Location
+		workingHours scheduler.WorkingHours
+	)
+
+	BeforeEach(func() {
+		var err error
+		london, err = time.LoadLocation("Europe/London")
+		Expect(err).ToNot(HaveOccurred())
+		workingHours = scheduler.NewWorkingHours(london, 9, 17)
+	})
+
+	// 3rd of March 2025 is a Monday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, time.March, day, hour, minute, 0, 0, london)
+	}
+
+	Context("Contains", func() {
+		It("should contain times during the working day", func() {
+			Expect(workingHours.Contains(at(3, 9, 0))).To(BeTrue())
+			Expect(workingHours.Contains(at(3, 16, 59))).To(BeTrue())
+		})
+
+		It("should not contain times outside the working day", func() {
+			Expect(workingHours.Contains(at(3, 8, 59))).To(BeFalse())
+			Expect(workingHours.Contains(at(3, 17, 0))).To(BeFalse())
+		})
+
+		It("should not contain weekends", func() {
+			Expect(workingHours.Contains(at(8, 12, 0))).To(BeFalse())
+		})
+	})
+
+	Context("Between", func() {
+		It("should count time within the same working day", func() {
+			Expect(workingHours.Between(at(3, 10, 0), at(3, 12, 30))).To(Equal(150 * time.Minute))
+		})
+
+		It("should only count working hours overnight", func() {
+			Expect(workingHours.Between(at(3, 16, 0), at(4, 10, 0))).To(Equal(2 * time.Hour))
+		})
+
+		It("should skip weekends", func() {
+			Expect(workingHours.Between(at(7, 16, 0), at(10, 10, 0))).To(Equal(2 * time.Hour))
+		})
+
+		It("should count whole working days", func() {
+			Expect(workingHours.Between(at(3, 0, 0), at(10, 0, 0))).To(Equal(40 * time.Hour))
+		})
+
+		It("should be zero outside working hours", func() {
+			Expect(workingHours.Between(at(8, 10, 0), at(9, 18, 0))).To(BeZero())
+		})
+	})
+
+	It("should default to nine to five", func() {
+		defaulted := scheduler.NewWorkingHours(london, 0, 0)
+
+		Expect(defaulted.Between(at(3, 0, 0), at(4, 0, 0))).To(Equal(8 * time.Hour))
+	})
+})
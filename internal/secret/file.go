@@ -0,0 +1,43 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package secret
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// FileProvider resolves file:// references to the content of the file, such as a docker or kubernetes secret.
+type FileProvider struct{}
+
+func NewFileProvider() *FileProvider {
+	return &FileProvider{}
+}
+
+// Resolve returns the content of the file without surrounding whitespace, as secret files often end with a newline.
+func (p *FileProvider) Resolve(_ context.Context, ref *url.URL) (string, error) {
+	data, err := os.ReadFile(ref.Path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
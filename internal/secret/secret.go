@@ -0,0 +1,215 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package secret
+
+//go:generate mockgen -destination=./mocks/secret.go . Provider
+
+import (
+	"context"
+	"fmt"
+	"git-slack-bot/internal/config"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	schemeFile  = "file"
+	schemeVault = "vault"
+
+	defaultInterval = 5 * time.Minute
+	// timeout bounds resolving a single secret.
+	timeout = 10 * time.Second
+)
+
+// Provider resolves references of its scheme to the secret they point at.
+type Provider interface {
+	Resolve(ctx context.Context, ref *url.URL) (string, error)
+}
+
+// Secret is a setting which may be a reference to a secret kept elsewhere. Referenced secrets are resolved again
+// every refresh interval of the Store, so that rotated secrets are picked up.
+type Secret struct {
+	ref      *url.URL
+	provider Provider
+	value    atomic.Pointer[string]
+}
+
+// Fixed returns a Secret of value itself.
+func Fixed(value string) *Secret {
+	s := &Secret{}
+	s.value.Store(&value)
+	return s
+}
+
+// Value returns the latest value of the secret.
+func (s *Secret) Value() string {
+	return *s.value.Load()
+}
+
+// refresh resolves the secret again and reports whether its value changed.
+func (s *Secret) refresh(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	value, err := s.provider.Resolve(ctx, s.ref)
+	if err != nil {
+		return false, fmt.Errorf("could not resolve %s: %w", redact(s.ref), err)
+	}
+	previous := s.value.Swap(&value)
+	return previous == nil || *previous != value, nil
+}
+
+// Scheme returns the scheme of value if it is a reference to a secret, and an empty string if it is the secret
+// itself.
+func Scheme(value string) string {
+	for _, scheme := range []string{schemeFile, schemeVault} {
+		if strings.HasPrefix(value, scheme+"://") {
+			return scheme
+		}
+	}
+	return ""
+}
+
+// Store resolves settings which may be references to secrets and keeps the secrets up to date.
+type Store struct {
+	providers map[string]Provider
+	interval  time.Duration
+
+	mutex   sync.Mutex
+	secrets map[string]*Secret
+}
+
+// NewStore creates a Store resolving file:// references, and vault:// references if cfg has a vault address. The
+// vault token may itself be a file:// reference, such as to the token file of a vault agent.
+func NewStore(ctx context.Context, cfg config.SecretsConfiguration) (*Store, error) {
+	store := NewStoreWithProviders(cfg.RefreshInterval, map[string]Provider{schemeFile: NewFileProvider()})
+	if cfg.Vault.Address != "" {
+		token, err := store.Resolve(ctx, cfg.Vault.Token)
+		if err != nil {
+			return nil, err
+		}
+		store.providers[schemeVault] = NewVaultProvider(http.DefaultClient, cfg.Vault.Address, cfg.Vault.Namespace, token)
+	}
+	return store, nil
+}
+
+// NewStoreWithProviders creates a Store resolving references with the provider of their scheme, which refreshes the
+// secrets every interval. A zero interval defaults to 5 minutes.
+func NewStoreWithProviders(interval time.Duration, providers map[string]Provider) *Store {
+	if interval == 0 {
+		interval = defaultInterval
+	}
+	return &Store{
+		providers: providers,
+		interval:  interval,
+		secrets:   map[string]*Secret{},
+	}
+}
+
+// Resolve returns the Secret of value, resolving it if it is a reference. Resolving the same reference again returns
+// the same Secret.
+func (s *Store) Resolve(ctx context.Context, value string) (*Secret, error) {
+	scheme := Scheme(value)
+	if scheme == "" {
+		return Fixed(value), nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if secret, ok := s.secrets[value]; ok {
+		return secret, nil
+	}
+	provider, ok := s.providers[scheme]
+	if !ok {
+		return nil, fmt.Errorf("no provider of %s:// secrets is configured", scheme)
+	}
+	ref, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret reference: %w", err)
+	}
+	secret := &Secret{ref: ref, provider: provider}
+	_, err = secret.refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.secrets[value] = secret
+	return secret, nil
+}
+
+// ResolveAll resolves each of values.
+func (s *Store) ResolveAll(ctx context.Context, values ...string) ([]*Secret, error) {
+	secrets := make([]*Secret, 0, len(values))
+	for _, value := range values {
+		secret, err := s.Resolve(ctx, value)
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets, nil
+}
+
+// Run refreshes the resolved secrets every interval until ctx is cancelled. A secret which fails to resolve keeps
+// its previous value.
+func (s *Store) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Refresh(ctx)
+		}
+	}
+}
+
+// Refresh resolves all secrets again and reports whether any of them changed.
+func (s *Store) Refresh(ctx context.Context) bool {
+	s.mutex.Lock()
+	secrets := make([]*Secret, 0, len(s.secrets))
+	for _, secret := range s.secrets {
+		secrets = append(secrets, secret)
+	}
+	s.mutex.Unlock()
+
+	changed := false
+	for _, secret := range secrets {
+		secretChanged, err := secret.refresh(ctx)
+		if err != nil {
+			slog.Error("Failed to refresh secret, keeping the previous value", slog.Any("error", err))
+			continue
+		}
+		if secretChanged {
+			slog.Info("Secret changed", slog.String("ref", redact(secret.ref)))
+			changed = true
+		}
+	}
+	return changed
+}
+
+// redact returns ref without anything that could be sensitive, for logs.
+func redact(ref *url.URL) string {
+	return ref.Scheme + "://" + ref.Host + ref.Path
+}
@@ -0,0 +1,125 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package secret_test
+
+import (
+	"context"
+	"errors"
+	"git-slack-bot/internal/secret"
+	mock_secret "git-slack-bot/internal/secret/mocks"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSecret(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Secret tests")
+}
+
+var _ = Describe("Store", func() {
+	var (
+		path  string
+		store *secret.Store
+	)
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(path, []byte("xoxb-1\n"), 0o600)).To(Succeed())
+		store = secret.NewStoreWithProviders(0, map[string]secret.Provider{"file": secret.NewFileProvider()})
+	})
+
+	It("returns values which are not references as they are", func() {
+		token, err := store.Resolve(context.Background(), "xoxb-literal")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token.Value()).To(Equal("xoxb-literal"))
+	})
+
+	It("resolves file references to the content of the file", func() {
+		token, err := store.Resolve(context.Background(), "file://"+path)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token.Value()).To(Equal("xoxb-1"))
+	})
+
+	It("returns the same secret for the same reference", func() {
+		first, err := store.Resolve(context.Background(), "file://"+path)
+		Expect(err).ToNot(HaveOccurred())
+		second, err := store.Resolve(context.Background(), "file://"+path)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(second).To(BeIdenticalTo(first))
+	})
+
+	It("fails on a missing file", func() {
+		_, err := store.Resolve(context.Background(), "file:///run/secrets/missing")
+
+		Expect(err).To(MatchError(ContainSubstring("could not resolve file:///run/secrets/missing")))
+	})
+
+	It("fails on a scheme without a provider", func() {
+		_, err := store.Resolve(context.Background(), "vault://secret/git-slack-bot#token")
+
+		Expect(err).To(MatchError("no provider of vault:// secrets is configured"))
+	})
+
+	It("picks up rotated secrets on refresh", func() {
+		token, err := store.Resolve(context.Background(), "file://"+path)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(store.Refresh(context.Background())).To(BeFalse())
+		Expect(os.WriteFile(path, []byte("xoxb-2\n"), 0o600)).To(Succeed())
+		Expect(store.Refresh(context.Background())).To(BeTrue())
+
+		Expect(token.Value()).To(Equal("xoxb-2"))
+	})
+
+	It("keeps the previous value if a refresh fails", func() {
+		provider := mock_secret.NewMockProvider(gomock.NewController(GinkgoT()))
+		store = secret.NewStoreWithProviders(time.Millisecond, map[string]secret.Provider{"vault": provider})
+		gomock.InOrder(
+			provider.EXPECT().Resolve(gomock.Any(), gomock.Any()).Return("s3cret", nil),
+			provider.EXPECT().Resolve(gomock.Any(), gomock.Any()).Return("", errors.New("vault is sealed")).MinTimes(1),
+		)
+		token, err := store.Resolve(context.Background(), "vault://secret/git-slack-bot#token")
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		store.Run(ctx)
+
+		Expect(token.Value()).To(Equal("s3cret"))
+	})
+})
+
+var _ = Describe("Scheme", func() {
+	It("returns the scheme of references", func() {
+		Expect(secret.Scheme("file:///run/secrets/token")).To(Equal("file"))
+		Expect(secret.Scheme("vault://secret/git-slack-bot#token")).To(Equal("vault"))
+		Expect(secret.Scheme("xoxb-literal")).To(BeEmpty())
+	})
+})
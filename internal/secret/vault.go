@@ -0,0 +1,94 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// VaultProvider resolves vault://mount/path#key references to the key of the secret at path in the KV version 2
+// secrets engine mounted at mount.
+type VaultProvider struct {
+	httpClient *http.Client
+	address    string
+	namespace  string
+	token      *Secret
+}
+
+// NewVaultProvider creates a VaultProvider calling the vault at address, in namespace if it isn't empty.
+func NewVaultProvider(httpClient *http.Client, address, namespace string, token *Secret) *VaultProvider {
+	return &VaultProvider{
+		httpClient: httpClient,
+		address:    strings.TrimSuffix(address, "/"),
+		namespace:  namespace,
+		token:      token,
+	}
+}
+
+func (p *VaultProvider) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	mount, path, key := ref.Host, strings.Trim(ref.Path, "/"), ref.Fragment
+	if mount == "" || path == "" || key == "" {
+		return "", errors.New("vault references need a mount, path and key, as in vault://secret/git-slack-bot#token")
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/%s/data/%s", p.address, mount, path), nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("X-Vault-Token", p.token.Value())
+	if p.namespace != "" {
+		request.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+	response, err := p.httpClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		err := response.Body.Close()
+		if err != nil {
+			slog.Debug("Failed to close response body", slog.Any("error", err))
+		}
+	}()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s", response.Status)
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&secret)
+	if err != nil {
+		return "", err
+	}
+	value, ok := secret.Data.Data[key].(string)
+	if !ok {
+		return "", fmt.Errorf("the secret has no string %q", key)
+	}
+	return value, nil
+}
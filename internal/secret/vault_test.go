@@ -0,0 +1,99 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package secret_test
+
+import (
+	"context"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/secret"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VaultProvider", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Vault-Token") != "vault-token" {
+				http.Error(w, `{"errors": ["permission denied"]}`, http.StatusForbidden)
+				return
+			}
+			if r.URL.Path != "/v1/secret/data/git-slack-bot" {
+				http.Error(w, `{"errors": []}`, http.StatusNotFound)
+				return
+			}
+			Expect(w.Write([]byte(`{"data": {"data": {"githubToken": "ghp_1", "slackToken": "xoxb-1"}, "metadata": {"version": 3}}}`))).Error().ToNot(HaveOccurred())
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	resolve := func(token, ref string) (string, error) {
+		store := secret.NewStoreWithProviders(0, map[string]secret.Provider{
+			"vault": secret.NewVaultProvider(server.Client(), server.URL+"/", "", secret.Fixed(token)),
+		})
+		resolved, err := store.Resolve(context.Background(), ref)
+		if err != nil {
+			return "", err
+		}
+		return resolved.Value(), nil
+	}
+
+	It("resolves the key of a kv secret", func() {
+		Expect(resolve("vault-token", "vault://secret/git-slack-bot#slackToken")).To(Equal("xoxb-1"))
+	})
+
+	It("fails on a missing key", func() {
+		_, err := resolve("vault-token", "vault://secret/git-slack-bot#secretKey")
+
+		Expect(err).To(MatchError(ContainSubstring(`the secret has no string "secretKey"`)))
+	})
+
+	It("fails on a reference without a key", func() {
+		_, err := resolve("vault-token", "vault://secret/git-slack-bot")
+
+		Expect(err).To(MatchError(ContainSubstring("vault references need a mount, path and key")))
+	})
+
+	It("fails when vault denies access", func() {
+		_, err := resolve("wrong-token", "vault://secret/git-slack-bot#slackToken")
+
+		Expect(err).To(MatchError(ContainSubstring("vault returned 403 Forbidden")))
+	})
+
+	It("reads the vault token from a file", func() {
+		tokenFile := filepath.Join(GinkgoT().TempDir(), "vault-token")
+		Expect(os.WriteFile(tokenFile, []byte("vault-token\n"), 0o600)).To(Succeed())
+		store, err := secret.NewStore(context.Background(), config.SecretsConfiguration{
+			Vault: config.VaultConfiguration{Address: server.URL, Token: "file://" + tokenFile},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		token, err := store.Resolve(context.Background(), "vault://secret/git-slack-bot#githubToken")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token.Value()).To(Equal("ghp_1"))
+	})
+})
@@ -0,0 +1,194 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"git-slack-bot/internal/config"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAddress           = ":8080"
+	defaultReadHeaderTimeout = 3 * time.Second
+	defaultReadTimeout       = 30 * time.Second
+	defaultWriteTimeout      = 30 * time.Second
+	defaultIdleTimeout       = 2 * time.Minute
+	// defaultMaxBodyBytes is the largest payload GitHub sends to webhooks.
+	defaultMaxBodyBytes = 25 << 20
+	// defaultDrainTimeout leaves some of the default 30 seconds Kubernetes waits after SIGTERM to exit cleanly.
+	defaultDrainTimeout = 25 * time.Second
+)
+
+// Server serves http, or https if a certificate is configured, until its context is cancelled and then gives
+// in-flight requests the drain timeout to finish.
+type Server struct {
+	httpServer   *http.Server
+	certificate  *certificateReloader
+	drainTimeout time.Duration
+}
+
+// NewServer creates a Server for handler. Request bodies larger than the configured maximum are rejected. It fails
+// if the configured certificate can't be loaded.
+func NewServer(cfg config.ServerConfiguration, handler http.Handler) (*Server, error) {
+	maxBodyBytes := withDefault(cfg.MaxBodyBytes, defaultMaxBodyBytes)
+	s := &Server{
+		httpServer: &http.Server{
+			Addr:              withDefault(cfg.Address, defaultAddress),
+			Handler:           http.MaxBytesHandler(handler, maxBodyBytes),
+			ReadHeaderTimeout: withDefault(cfg.ReadHeaderTimeout, defaultReadHeaderTimeout),
+			ReadTimeout:       withDefault(cfg.ReadTimeout, defaultReadTimeout),
+			WriteTimeout:      withDefault(cfg.WriteTimeout, defaultWriteTimeout),
+			IdleTimeout:       withDefault(cfg.IdleTimeout, defaultIdleTimeout),
+		},
+		drainTimeout: withDefault(cfg.DrainTimeout, defaultDrainTimeout),
+	}
+
+	if cfg.TLS.CertFile != "" || cfg.TLS.KeyFile != "" {
+		certificate, err := newCertificateReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		s.certificate = certificate
+		s.httpServer.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certificate.GetCertificate,
+		}
+	}
+	return s, nil
+}
+
+// Run serves until ctx is cancelled, then stops accepting connections and waits up to the drain timeout for
+// in-flight requests to finish.
+func (s *Server) Run(ctx context.Context) error {
+	served := make(chan error, 1)
+	go func() {
+		slog.Info("Starting server", slog.String("address", s.httpServer.Addr), slog.Bool("tls", s.certificate != nil))
+		if s.certificate != nil {
+			served <- s.httpServer.ListenAndServeTLS("", "")
+		} else {
+			served <- s.httpServer.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Draining server", slog.Duration("timeout", s.drainTimeout))
+	drainCtx, cancel := context.WithTimeout(context.Background(), s.drainTimeout)
+	defer cancel()
+	err := s.httpServer.Shutdown(drainCtx)
+	if err != nil {
+		return err
+	}
+	err = <-served
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// certificateReloader loads the certificate again when its files have changed since it was last loaded. Failing to
+// reload keeps the previous certificate, as the files may be in the middle of being replaced.
+type certificateReloader struct {
+	certFile    string
+	keyFile     string
+	mutex       sync.Mutex
+	certificate *tls.Certificate
+	modTime     time.Time
+}
+
+func newCertificateReloader(certFile, keyFile string) (*certificateReloader, error) {
+	r := &certificateReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	err = r.load(modTime)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	modTime, err := r.latestModTime()
+	if err != nil {
+		slog.Error("Failed to check tls certificate for changes", slog.Any("error", err))
+		return r.certificate, nil
+	}
+	if modTime.Equal(r.modTime) {
+		return r.certificate, nil
+	}
+	err = r.load(modTime)
+	if err != nil {
+		slog.Error("Failed to reload tls certificate", slog.String("certFile", r.certFile), slog.Any("error", err))
+		return r.certificate, nil
+	}
+	slog.Info("Reloaded tls certificate", slog.String("certFile", r.certFile))
+	return r.certificate, nil
+}
+
+func (r *certificateReloader) load(modTime time.Time) error {
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.certificate = &certificate
+	r.modTime = modTime
+	return nil
+}
+
+func (r *certificateReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func withDefault[T comparable](value, fallback T) T {
+	var zero T
+	if value == zero {
+		return fallback
+	}
+	return value
+}
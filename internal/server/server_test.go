@@ -0,0 +1,207 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/server"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestServer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Server tests")
+}
+
+var _ = Describe("Server", func() {
+	var (
+		address string
+		ctx     context.Context
+		cancel  context.CancelFunc
+		stopped chan error
+	)
+
+	run := func(cfg config.ServerConfiguration, handler http.Handler) {
+		cfg.Address = address
+		httpServer, err := server.NewServer(cfg, handler)
+		Expect(err).ToNot(HaveOccurred())
+		// The spec's context and channel are captured, as the next spec's BeforeEach replaces them.
+		runCtx, runCancel, runStopped := ctx, cancel, stopped
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			runStopped <- httpServer.Run(runCtx)
+		}()
+		DeferCleanup(func() {
+			runCancel()
+			Eventually(done).Should(BeClosed())
+		})
+		Eventually(func() error {
+			connection, err := net.Dial("tcp", address)
+			if err == nil {
+				err = connection.Close()
+			}
+			return err
+		}).Should(Succeed())
+	}
+
+	BeforeEach(func() {
+		address = freeAddress()
+		ctx, cancel = context.WithCancel(context.Background())
+		stopped = make(chan error, 1)
+		DeferCleanup(cancel)
+	})
+
+	It("lets in-flight requests finish when stopped", func() {
+		started := make(chan struct{})
+		run(config.ServerConfiguration{}, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			close(started)
+			time.Sleep(100 * time.Millisecond)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+
+		responses := make(chan int, 1)
+		go func() {
+			defer GinkgoRecover()
+			response, err := http.Get("http://" + address)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Body.Close()).To(Succeed())
+			responses <- response.StatusCode
+		}()
+		<-started
+		cancel()
+
+		Eventually(responses).Should(Receive(Equal(http.StatusAccepted)))
+		Eventually(stopped).Should(Receive(BeNil()))
+	})
+
+	It("gives up draining after the drain timeout", func() {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		DeferCleanup(func() { close(release) })
+		run(config.ServerConfiguration{DrainTimeout: 10 * time.Millisecond}, http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+			close(started)
+			<-release
+		}))
+
+		go func() {
+			defer GinkgoRecover()
+			response, err := http.Get("http://" + address)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Body.Close()).To(Succeed())
+		}()
+		<-started
+		cancel()
+
+		Eventually(stopped).Should(Receive(MatchError(context.DeadlineExceeded)))
+	})
+
+	It("rejects bodies over the maximum size", func() {
+		run(config.ServerConfiguration{MaxBodyBytes: 4}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+			}
+		}))
+
+		response, err := http.Post("http://"+address, "text/plain", bytes.NewReader([]byte("too long")))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body.Close()).To(Succeed())
+		Expect(response.StatusCode).To(Equal(http.StatusRequestEntityTooLarge))
+	})
+
+	It("reloads the certificate when its files change", func() {
+		directory := GinkgoT().TempDir()
+		certFile := filepath.Join(directory, "tls.crt")
+		keyFile := filepath.Join(directory, "tls.key")
+		writeCertificate(certFile, keyFile, "first", time.Now().Add(-time.Hour))
+		run(config.ServerConfiguration{TLS: config.TLSConfiguration{CertFile: certFile, KeyFile: keyFile}}, http.NotFoundHandler())
+
+		Expect(servedCertificate(address)).To(Equal("first"))
+
+		writeCertificate(certFile, keyFile, "second", time.Now())
+		Expect(servedCertificate(address)).To(Equal("second"))
+	})
+
+	It("fails without the certificate files", func() {
+		_, err := server.NewServer(config.ServerConfiguration{TLS: config.TLSConfiguration{CertFile: "missing.crt", KeyFile: "missing.key"}}, http.NotFoundHandler())
+
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+func freeAddress() string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+	address := listener.Addr().String()
+	Expect(listener.Close()).To(Succeed())
+	return address
+}
+
+func writeCertificate(certFile, keyFile, commonName string, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+	privateKey, err := x509.MarshalECPrivateKey(key)
+	Expect(err).ToNot(HaveOccurred())
+
+	Expect(os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}), 0o600)).To(Succeed())
+	Expect(os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privateKey}), 0o600)).To(Succeed())
+	Expect(os.Chtimes(certFile, modTime, modTime)).To(Succeed())
+	Expect(os.Chtimes(keyFile, modTime, modTime)).To(Succeed())
+}
+
+func servedCertificate(address string) string {
+	connection, err := tls.Dial("tcp", address, &tls.Config{InsecureSkipVerify: true}) //nolint:gosec // self-signed test certificate
+	Expect(err).ToNot(HaveOccurred())
+	defer func() {
+		Expect(connection.Close()).To(Succeed())
+	}()
+	certificates := connection.ConnectionState().PeerCertificates
+	Expect(certificates).ToNot(BeEmpty(), fmt.Sprintf("no certificate from %s", address))
+	return certificates[0].Subject.CommonName
+}
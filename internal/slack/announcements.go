@@ -0,0 +1,67 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package slack
+
+import (
+	"sync"
+	"time"
+)
+
+const defaultAnnouncementWindow = 10 * time.Minute
+
+// announcements remembers when pull requests were announced. Announcements that failed are released again, so that
+// they don't hold up the next attempt.
+type announcements struct {
+	window    time.Duration
+	mutex     sync.Mutex
+	announced map[string]time.Time
+}
+
+// newAnnouncements creates announcements with window, which defaults to 10 minutes if zero.
+func newAnnouncements(window time.Duration) *announcements {
+	if window == 0 {
+		window = defaultAnnouncementWindow
+	}
+	return &announcements{window: window, announced: make(map[string]time.Time)}
+}
+
+// claim reports whether the pull request of url may be announced at now, which it may not if it was announced
+// within the window, and remembers the announcement if so.
+func (a *announcements) claim(url string, now time.Time) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for announcedURL, announcedAt := range a.announced {
+		if now.Sub(announcedAt) >= a.window {
+			delete(a.announced, announcedURL)
+		}
+	}
+	if _, ok := a.announced[url]; ok {
+		return false
+	}
+	a.announced[url] = now
+	return true
+}
+
+// release forgets the announcement of the pull request of url, after posting it failed.
+func (a *announcements) release(url string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	delete(a.announced, url)
+}
@@ -0,0 +1,232 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"git-slack-bot/internal/logging"
+	"io"
+	"log/slog"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// dryRunHistory bounds how many of the messages posted during a dry run are remembered.
+const dryRunHistory = 1000
+
+// DryRunClient is a Client that reports the slack api calls that would change the workspace instead of making them.
+// Reads go to the wrapped client, if there is one. The latest messages posted during the dry run are remembered, so
+// that the replies and reactions that follow an announcement can find it.
+type DryRunClient struct {
+	reads Client
+	out   io.Writer
+
+	mutex     sync.Mutex
+	messages  []slack.Message
+	timestamp int64
+}
+
+// NewDryRunClient prints the calls to out, or logs them if out is nil. reads may be nil, in which case user lookups
+// fail and the history only holds the messages posted during the dry run.
+func NewDryRunClient(reads Client, out io.Writer) *DryRunClient {
+	return &DryRunClient{
+		reads:     reads,
+		out:       out,
+		timestamp: time.Now().Unix(),
+	}
+}
+
+func (c *DryRunClient) PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	_, values, err := slack.UnsafeApplyMsgOptions("", channelID, "", options...)
+	if err != nil {
+		return "", "", err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.timestamp++
+	timestamp := fmt.Sprintf("%d.000000", c.timestamp)
+	c.messages = append(c.messages, slack.Message{Msg: slack.Msg{
+		Channel:         values.Get("channel"),
+		Text:            values.Get("text"),
+		Timestamp:       timestamp,
+		ThreadTimestamp: values.Get("thread_ts"),
+	}})
+	if len(c.messages) > dryRunHistory {
+		c.messages = slices.Delete(c.messages, 0, len(c.messages)-dryRunHistory)
+	}
+	c.report("chat.postMessage", values)
+	return values.Get("channel"), timestamp, nil
+}
+
+func (c *DryRunClient) PostEphemeralContext(ctx context.Context, channelID, userID string, options ...slack.MsgOption) (string, error) {
+	_, values, err := slack.UnsafeApplyMsgOptions("", channelID, "", options...)
+	if err != nil {
+		return "", err
+	}
+	values.Set("user", userID)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.report("chat.postEphemeral", values)
+	return "", nil
+}
+
+func (c *DryRunClient) AddReactionContext(ctx context.Context, name string, item slack.ItemRef) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.report("reactions.add", url.Values{"name": {name}, "channel": {item.Channel}, "timestamp": {item.Timestamp}})
+	return nil
+}
+
+func (c *DryRunClient) RemoveReactionContext(ctx context.Context, name string, item slack.ItemRef) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.report("reactions.remove", url.Values{"name": {name}, "channel": {item.Channel}, "timestamp": {item.Timestamp}})
+	return nil
+}
+
+func (c *DryRunClient) DeleteMessageContext(ctx context.Context, channel, messageTimestamp string) (string, string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.messages = slices.DeleteFunc(c.messages, func(message slack.Message) bool {
+		return message.Channel == channel && message.Timestamp == messageTimestamp
+	})
+	c.report("chat.delete", url.Values{"channel": {channel}, "ts": {messageTimestamp}})
+	return channel, messageTimestamp, nil
+}
+
+// GetConversationHistoryContext returns the matching messages posted during the dry run, newest first, followed by the
+// history of the wrapped client.
+func (c *DryRunClient) GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+	c.mutex.Lock()
+	var messages []slack.Message
+	for i := len(c.messages) - 1; i >= 0; i-- {
+		message := c.messages[i]
+		if message.Channel != params.ChannelID || message.ThreadTimestamp != "" {
+			continue
+		}
+		if params.Latest != "" && params.Latest == params.Oldest && message.Timestamp != params.Latest {
+			continue
+		}
+		messages = append(messages, message)
+	}
+	c.mutex.Unlock()
+
+	if c.reads == nil {
+		return &slack.GetConversationHistoryResponse{Messages: messages}, nil
+	}
+	history, err := c.reads.GetConversationHistoryContext(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	history.Messages = append(messages, history.Messages...)
+	return history, nil
+}
+
+// GetConversationRepliesContext returns the replies posted to the thread during the dry run, followed by the replies
+// of the wrapped client unless the thread is of a message posted during the dry run, which slack doesn't know.
+func (c *DryRunClient) GetConversationRepliesContext(ctx context.Context, params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error) {
+	c.mutex.Lock()
+	var replies []slack.Message
+	dryRunThread := false
+	for _, message := range c.messages {
+		if message.Channel != params.ChannelID {
+			continue
+		}
+		if message.Timestamp == params.Timestamp {
+			dryRunThread = true
+		}
+		if message.ThreadTimestamp == params.Timestamp {
+			replies = append(replies, message)
+		}
+	}
+	c.mutex.Unlock()
+
+	if c.reads == nil || dryRunThread {
+		return replies, false, "", nil
+	}
+	readReplies, hasMore, nextCursor, err := c.reads.GetConversationRepliesContext(ctx, params)
+	if err != nil {
+		return nil, false, "", err
+	}
+	return append(replies, readReplies...), hasMore, nextCursor, nil
+}
+
+func (c *DryRunClient) GetUserByEmailContext(ctx context.Context, email string) (*slack.User, error) {
+	if c.reads == nil {
+		return nil, errors.New("users can not be looked up in a dry run without a slack token")
+	}
+	return c.reads.GetUserByEmailContext(ctx, email)
+}
+
+func (c *DryRunClient) GetUserInfoContext(ctx context.Context, user string) (*slack.User, error) {
+	if c.reads == nil {
+		return nil, errors.New("users can not be looked up in a dry run without a slack token")
+	}
+	return c.reads.GetUserInfoContext(ctx, user)
+}
+
+func (c *DryRunClient) AuthTestContext(ctx context.Context) (*slack.AuthTestResponse, error) {
+	if c.reads == nil {
+		return &slack.AuthTestResponse{}, nil
+	}
+	return c.reads.AuthTestContext(ctx)
+}
+
+// report prints or logs the call as the method followed by its parameters in a stable order. Must be called with
+// the mutex held, so the lines of concurrent calls don't interleave.
+func (c *DryRunClient) report(method string, values url.Values) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if key != "token" && values.Get(key) != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	if c.out == nil {
+		var parameters []any
+		for _, key := range keys {
+			parameters = append(parameters, slog.String(key, values.Get(key)))
+		}
+		slog.Info("Dry run slack call", slog.String("method", method), slog.Group(logging.DryRunKey, parameters...))
+		return
+	}
+
+	var line strings.Builder
+	line.WriteString(method)
+	for _, key := range keys {
+		fmt.Fprintf(&line, " %s=%q", key, values.Get(key))
+	}
+	line.WriteString("\n")
+	_, err := io.WriteString(c.out, line.String())
+	if err != nil {
+		slog.Error("Failed to print dry run call", slog.String("method", method), slog.Any("error", err))
+	}
+}
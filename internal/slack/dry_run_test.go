@@ -0,0 +1,117 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package slack_test
+
+import (
+	"bytes"
+	"context"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/logging"
+	"git-slack-bot/internal/slack"
+	mock_slack "git-slack-bot/internal/slack/mocks"
+	"log/slog"
+
+	sl "github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DryRunClient", func() {
+	var (
+		mockClient *mock_slack.MockClient
+		out        *bytes.Buffer
+		connector  *slack.Connector
+	)
+
+	BeforeEach(func() {
+		mockClient = mock_slack.NewMockClient(gomock.NewController(GinkgoT()))
+		out = &bytes.Buffer{}
+		connector = slack.NewSlackConnector(config.SlackConfiguration{ChannelID: "C123"}, slack.NewDryRunClient(mockClient, out))
+	})
+
+	It("prints posted messages instead of sending them", func() {
+		connector.SendMessage(context.Background(), "@alice opened a PR")
+
+		Expect(out.String()).To(Equal("chat.postMessage channel=\"C123\" text=\"@alice opened a PR\"\n"))
+	})
+
+	It("finds the messages it posted together with the real history", func() {
+		mockClient.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(&sl.GetConversationHistoryResponse{
+			Messages: []sl.Message{{Msg: sl.Msg{Text: "an older post", Timestamp: "1.000000"}}},
+		}, nil).Times(2)
+		connector.SendMessage(context.Background(), "https://github.com/org/repo/pull/1")
+
+		message, err := connector.GetMessage(context.Background(), "https://github.com/org/repo/pull/1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(message.Timestamp).ToNot(BeEmpty())
+
+		older, err := connector.GetMessage(context.Background(), "an older post")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(older.Timestamp).To(Equal("1.000000"))
+	})
+
+	It("prints replies, once, and reactions on the messages it posted", func() {
+		mockClient.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(&sl.GetConversationHistoryResponse{}, nil)
+		connector.SendMessage(context.Background(), "https://github.com/org/repo/pull/1")
+		message, err := connector.GetMessage(context.Background(), "https://github.com/org/repo/pull/1")
+		Expect(err).ToNot(HaveOccurred())
+		out.Reset()
+
+		connector.SendReply(context.Background(), message, "approved")
+		connector.SendReply(context.Background(), message, "approved")
+		connector.AddReactionToMessage(context.Background(), "+1", message)
+
+		Expect(out.String()).To(Equal(
+			"chat.postMessage channel=\"C123\" text=\"approved\" thread_ts=\"" + message.Timestamp + "\"\n" +
+				"reactions.add channel=\"C123\" name=\"+1\" timestamp=\"" + message.Timestamp + "\"\n"))
+	})
+
+	It("reads users from the real client", func() {
+		mockClient.EXPECT().GetUserByEmailContext(gomock.Any(), "alice@example.com").Return(&sl.User{ID: "U1"}, nil)
+
+		userID, err := connector.GetUserIDByEmail(context.Background(), "alice@example.com")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(userID).To(Equal("U1"))
+	})
+
+	It("fails user lookups without a real client", func() {
+		connector = slack.NewSlackConnector(config.SlackConfiguration{ChannelID: "C123"}, slack.NewDryRunClient(nil, out))
+
+		_, err := connector.GetUserIDByEmail(context.Background(), "alice@example.com")
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("logs the text of posted messages under the default logging", func() {
+		logs := &bytes.Buffer{}
+		logger, err := logging.NewLogger(logs, config.LoggingConfiguration{})
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(slog.SetDefault, slog.Default())
+		slog.SetDefault(logger)
+		connector = slack.NewSlackConnector(config.SlackConfiguration{ChannelID: "C123"}, slack.NewDryRunClient(nil, nil))
+
+		connector.SendMessage(context.Background(), "@alice opened a PR, token xoxb-123-abc")
+
+		Expect(logs.String()).To(ContainSubstring(`"dryRun":{"channel":"C123","text":"@alice opened a PR, token [REDACTED]"}`))
+	})
+})
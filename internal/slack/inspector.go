@@ -0,0 +1,130 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// Inspector makes the calls needed to check the configuration that the slack client does not expose.
+type Inspector struct {
+	httpClient *http.Client
+	apiURL     string
+	token      func() string
+}
+
+// NewInspector creates an Inspector calling the slack api with the latest value of token.
+func NewInspector(token func() string) *Inspector {
+	return NewInspectorWithURL(http.DefaultClient, slack.APIURL, token)
+}
+
+// NewInspectorWithURL creates an Inspector calling the slack api at apiURL, which ends with a slash.
+func NewInspectorWithURL(httpClient *http.Client, apiURL string, token func() string) *Inspector {
+	return &Inspector{
+		httpClient: httpClient,
+		apiURL:     apiURL,
+		token:      token,
+	}
+}
+
+// Scopes returns the OAuth scopes granted to the token.
+func (i *Inspector) Scopes(ctx context.Context) ([]string, error) {
+	response, err := i.call(ctx, "auth.test", nil, &slack.SlackResponse{})
+	if err != nil {
+		return nil, err
+	}
+	var scopes []string
+	for _, scope := range strings.Split(response.Header.Get("X-OAuth-Scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+type emojiListResponse struct {
+	slack.SlackResponse
+	Emoji      map[string]string `json:"emoji"`
+	Categories []struct {
+		EmojiNames []string `json:"emoji_names"`
+	} `json:"categories"`
+}
+
+// Emoji returns the names of the emoji that can be used in the workspace, standard and custom ones alike.
+func (i *Inspector) Emoji(ctx context.Context) (map[string]bool, error) {
+	emojiList := &emojiListResponse{}
+	_, err := i.call(ctx, "emoji.list", url.Values{"include_categories": {"true"}}, emojiList)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	for name := range emojiList.Emoji {
+		names[name] = true
+	}
+	for _, category := range emojiList.Categories {
+		for _, name := range category.EmojiNames {
+			names[name] = true
+		}
+	}
+	return names, nil
+}
+
+type slackResponse interface {
+	Err() error
+}
+
+func (i *Inspector) call(ctx context.Context, method string, values url.Values, result slackResponse) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, i.apiURL+method, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", "Bearer "+i.token())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := i.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := response.Body.Close()
+		if err != nil {
+			slog.Debug("Failed to close response body", slog.Any("error", err))
+		}
+	}()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", method, response.Status)
+	}
+	err = json.NewDecoder(response.Body).Decode(result)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	if result.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", method, result.Err())
+	}
+	return response, nil
+}
@@ -0,0 +1,74 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package slack_test
+
+import (
+	"context"
+	"git-slack-bot/internal/slack"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Inspector", func() {
+	var (
+		server    *httptest.Server
+		inspector *slack.Inspector
+	)
+
+	BeforeEach(func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/auth.test", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer xoxb-token" {
+				Expect(w.Write([]byte(`{"ok": false, "error": "invalid_auth"}`))).Error().ToNot(HaveOccurred())
+				return
+			}
+			w.Header().Set("X-OAuth-Scopes", "chat:write, reactions:write,users:read")
+			Expect(w.Write([]byte(`{"ok": true}`))).Error().ToNot(HaveOccurred())
+		})
+		mux.HandleFunc("/api/emoji.list", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.ParseForm()).To(Succeed())
+			Expect(r.PostForm.Get("include_categories")).To(Equal("true"))
+			Expect(w.Write([]byte(`{"ok": true, "emoji": {"shipit": "https://emoji.slack-edge.com/shipit.png", "squirrel": "alias:shipit"},
+				"categories": [{"name": "smileys_and_people", "emoji_names": ["+1", "x"]}]}`))).Error().ToNot(HaveOccurred())
+		})
+		server = httptest.NewServer(mux)
+		DeferCleanup(server.Close)
+		inspector = slack.NewInspectorWithURL(server.Client(), server.URL+"/api/", func() string { return "xoxb-token" })
+	})
+
+	It("lists the scopes of the token", func() {
+		Expect(inspector.Scopes(context.Background())).To(Equal([]string{"chat:write", "reactions:write", "users:read"}))
+	})
+
+	It("fails with an invalid token", func() {
+		inspector = slack.NewInspectorWithURL(server.Client(), server.URL+"/api/", func() string { return "xoxb-revoked" })
+
+		_, err := inspector.Scopes(context.Background())
+
+		Expect(err).To(MatchError("auth.test: invalid_auth"))
+	})
+
+	It("lists custom and standard emoji", func() {
+		Expect(inspector.Emoji(context.Background())).To(Equal(map[string]bool{"shipit": true, "squirrel": true, "+1": true, "x": true}))
+	})
+})
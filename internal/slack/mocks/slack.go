@@ -1,15 +1,17 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: git-slack-bot/internal/slack (interfaces: Client,Interactor)
+// Source: git-slack-bot/internal/slack (interfaces: Client,Interactor,DeadLetters)
 //
 // Generated by this command:
 //
-//	mockgen -destination=./mocks/slack.go . Client,Interactor
+//	mockgen -destination=./mocks/slack.go . Client,Interactor,DeadLetters
 //
 
 // Package mock_slack is a generated GoMock package.
 package mock_slack
 
 import (
+	context "context"
+	deadletter "git-slack-bot/internal/deadletter"
 	reflect "reflect"
 
 	slack "github.com/slack-go/slack"
@@ -20,6 +22,7 @@ import (
 type MockClient struct {
 	ctrl     *gomock.Controller
 	recorder *MockClientMockRecorder
+	isgomock struct{}
 }
 
 // MockClientMockRecorder is the mock recorder for MockClient.
@@ -39,89 +42,173 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
-// AddReaction mocks base method.
-func (m *MockClient) AddReaction(arg0 string, arg1 slack.ItemRef) error {
+// AddReactionContext mocks base method.
+func (m *MockClient) AddReactionContext(ctx context.Context, name string, item slack.ItemRef) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddReaction", arg0, arg1)
+	ret := m.ctrl.Call(m, "AddReactionContext", ctx, name, item)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// AddReaction indicates an expected call of AddReaction.
-func (mr *MockClientMockRecorder) AddReaction(arg0, arg1 any) *gomock.Call {
+// AddReactionContext indicates an expected call of AddReactionContext.
+func (mr *MockClientMockRecorder) AddReactionContext(ctx, name, item any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReaction", reflect.TypeOf((*MockClient)(nil).AddReaction), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReactionContext", reflect.TypeOf((*MockClient)(nil).AddReactionContext), ctx, name, item)
 }
 
-// GetConversationHistory mocks base method.
-func (m *MockClient) GetConversationHistory(arg0 *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+// AuthTestContext mocks base method.
+func (m *MockClient) AuthTestContext(ctx context.Context) (*slack.AuthTestResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetConversationHistory", arg0)
+	ret := m.ctrl.Call(m, "AuthTestContext", ctx)
+	ret0, _ := ret[0].(*slack.AuthTestResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthTestContext indicates an expected call of AuthTestContext.
+func (mr *MockClientMockRecorder) AuthTestContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthTestContext", reflect.TypeOf((*MockClient)(nil).AuthTestContext), ctx)
+}
+
+// DeleteMessageContext mocks base method.
+func (m *MockClient) DeleteMessageContext(ctx context.Context, channel, messageTimestamp string) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMessageContext", ctx, channel, messageTimestamp)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// DeleteMessageContext indicates an expected call of DeleteMessageContext.
+func (mr *MockClientMockRecorder) DeleteMessageContext(ctx, channel, messageTimestamp any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessageContext", reflect.TypeOf((*MockClient)(nil).DeleteMessageContext), ctx, channel, messageTimestamp)
+}
+
+// GetConversationHistoryContext mocks base method.
+func (m *MockClient) GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConversationHistoryContext", ctx, params)
 	ret0, _ := ret[0].(*slack.GetConversationHistoryResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetConversationHistory indicates an expected call of GetConversationHistory.
-func (mr *MockClientMockRecorder) GetConversationHistory(arg0 any) *gomock.Call {
+// GetConversationHistoryContext indicates an expected call of GetConversationHistoryContext.
+func (mr *MockClientMockRecorder) GetConversationHistoryContext(ctx, params any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConversationHistory", reflect.TypeOf((*MockClient)(nil).GetConversationHistory), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConversationHistoryContext", reflect.TypeOf((*MockClient)(nil).GetConversationHistoryContext), ctx, params)
 }
 
-// GetUserByEmail mocks base method.
-func (m *MockClient) GetUserByEmail(arg0 string) (*slack.User, error) {
+// GetConversationRepliesContext mocks base method.
+func (m *MockClient) GetConversationRepliesContext(ctx context.Context, params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConversationRepliesContext", ctx, params)
+	ret0, _ := ret[0].([]slack.Message)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(string)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// GetConversationRepliesContext indicates an expected call of GetConversationRepliesContext.
+func (mr *MockClientMockRecorder) GetConversationRepliesContext(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConversationRepliesContext", reflect.TypeOf((*MockClient)(nil).GetConversationRepliesContext), ctx, params)
+}
+
+// GetUserByEmailContext mocks base method.
+func (m *MockClient) GetUserByEmailContext(ctx context.Context, email string) (*slack.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByEmail", arg0)
+	ret := m.ctrl.Call(m, "GetUserByEmailContext", ctx, email)
 	ret0, _ := ret[0].(*slack.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByEmail indicates an expected call of GetUserByEmail.
-func (mr *MockClientMockRecorder) GetUserByEmail(arg0 any) *gomock.Call {
+// GetUserByEmailContext indicates an expected call of GetUserByEmailContext.
+func (mr *MockClientMockRecorder) GetUserByEmailContext(ctx, email any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockClient)(nil).GetUserByEmail), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmailContext", reflect.TypeOf((*MockClient)(nil).GetUserByEmailContext), ctx, email)
 }
 
-// PostMessage mocks base method.
-func (m *MockClient) PostMessage(arg0 string, arg1 ...slack.MsgOption) (string, string, error) {
+// GetUserInfoContext mocks base method.
+func (m *MockClient) GetUserInfoContext(ctx context.Context, user string) (*slack.User, error) {
 	m.ctrl.T.Helper()
-	varargs := []any{arg0}
-	for _, a := range arg1 {
+	ret := m.ctrl.Call(m, "GetUserInfoContext", ctx, user)
+	ret0, _ := ret[0].(*slack.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserInfoContext indicates an expected call of GetUserInfoContext.
+func (mr *MockClientMockRecorder) GetUserInfoContext(ctx, user any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserInfoContext", reflect.TypeOf((*MockClient)(nil).GetUserInfoContext), ctx, user)
+}
+
+// PostEphemeralContext mocks base method.
+func (m *MockClient) PostEphemeralContext(ctx context.Context, channelID, userID string, options ...slack.MsgOption) (string, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, channelID, userID}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PostEphemeralContext", varargs...)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostEphemeralContext indicates an expected call of PostEphemeralContext.
+func (mr *MockClientMockRecorder) PostEphemeralContext(ctx, channelID, userID any, options ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, channelID, userID}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostEphemeralContext", reflect.TypeOf((*MockClient)(nil).PostEphemeralContext), varargs...)
+}
+
+// PostMessageContext mocks base method.
+func (m *MockClient) PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, channelID}
+	for _, a := range options {
 		varargs = append(varargs, a)
 	}
-	ret := m.ctrl.Call(m, "PostMessage", varargs...)
+	ret := m.ctrl.Call(m, "PostMessageContext", varargs...)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(string)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
 
-// PostMessage indicates an expected call of PostMessage.
-func (mr *MockClientMockRecorder) PostMessage(arg0 any, arg1 ...any) *gomock.Call {
+// PostMessageContext indicates an expected call of PostMessageContext.
+func (mr *MockClientMockRecorder) PostMessageContext(ctx, channelID any, options ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]any{arg0}, arg1...)
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostMessage", reflect.TypeOf((*MockClient)(nil).PostMessage), varargs...)
+	varargs := append([]any{ctx, channelID}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostMessageContext", reflect.TypeOf((*MockClient)(nil).PostMessageContext), varargs...)
 }
 
-// RemoveReaction mocks base method.
-func (m *MockClient) RemoveReaction(arg0 string, arg1 slack.ItemRef) error {
+// RemoveReactionContext mocks base method.
+func (m *MockClient) RemoveReactionContext(ctx context.Context, name string, item slack.ItemRef) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveReaction", arg0, arg1)
+	ret := m.ctrl.Call(m, "RemoveReactionContext", ctx, name, item)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// RemoveReaction indicates an expected call of RemoveReaction.
-func (mr *MockClientMockRecorder) RemoveReaction(arg0, arg1 any) *gomock.Call {
+// RemoveReactionContext indicates an expected call of RemoveReactionContext.
+func (mr *MockClientMockRecorder) RemoveReactionContext(ctx, name, item any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveReaction", reflect.TypeOf((*MockClient)(nil).RemoveReaction), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveReactionContext", reflect.TypeOf((*MockClient)(nil).RemoveReactionContext), ctx, name, item)
 }
 
 // MockInteractor is a mock of Interactor interface.
 type MockInteractor struct {
 	ctrl     *gomock.Controller
 	recorder *MockInteractorMockRecorder
+	isgomock struct{}
 }
 
 // MockInteractorMockRecorder is the mock recorder for MockInteractor.
@@ -142,79 +229,222 @@ func (m *MockInteractor) EXPECT() *MockInteractorMockRecorder {
 }
 
 // AddReactionToMessage mocks base method.
-func (m *MockInteractor) AddReactionToMessage(arg0 string, arg1 *slack.Message) {
+func (m *MockInteractor) AddReactionToMessage(ctx context.Context, reaction string, message *slack.Message) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "AddReactionToMessage", arg0, arg1)
+	m.ctrl.Call(m, "AddReactionToMessage", ctx, reaction, message)
 }
 
 // AddReactionToMessage indicates an expected call of AddReactionToMessage.
-func (mr *MockInteractorMockRecorder) AddReactionToMessage(arg0, arg1 any) *gomock.Call {
+func (mr *MockInteractorMockRecorder) AddReactionToMessage(ctx, reaction, message any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReactionToMessage", reflect.TypeOf((*MockInteractor)(nil).AddReactionToMessage), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReactionToMessage", reflect.TypeOf((*MockInteractor)(nil).AddReactionToMessage), ctx, reaction, message)
+}
+
+// Announce mocks base method.
+func (m *MockInteractor) Announce(ctx context.Context, pullRequestURL, message string, blocks []slack.Block) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Announce", ctx, pullRequestURL, message, blocks)
+}
+
+// Announce indicates an expected call of Announce.
+func (mr *MockInteractorMockRecorder) Announce(ctx, pullRequestURL, message, blocks any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Announce", reflect.TypeOf((*MockInteractor)(nil).Announce), ctx, pullRequestURL, message, blocks)
+}
+
+// DeleteMessage mocks base method.
+func (m *MockInteractor) DeleteMessage(ctx context.Context, timestamp string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteMessage", ctx, timestamp)
+}
+
+// DeleteMessage indicates an expected call of DeleteMessage.
+func (mr *MockInteractorMockRecorder) DeleteMessage(ctx, timestamp any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockInteractor)(nil).DeleteMessage), ctx, timestamp)
 }
 
 // GetMessage mocks base method.
-func (m *MockInteractor) GetMessage(arg0 string) (*slack.Message, error) {
+func (m *MockInteractor) GetMessage(ctx context.Context, messageKey string) (*slack.Message, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetMessage", arg0)
+	ret := m.ctrl.Call(m, "GetMessage", ctx, messageKey)
 	ret0, _ := ret[0].(*slack.Message)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetMessage indicates an expected call of GetMessage.
-func (mr *MockInteractorMockRecorder) GetMessage(arg0 any) *gomock.Call {
+func (mr *MockInteractorMockRecorder) GetMessage(ctx, messageKey any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessage", reflect.TypeOf((*MockInteractor)(nil).GetMessage), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessage", reflect.TypeOf((*MockInteractor)(nil).GetMessage), ctx, messageKey)
+}
+
+// GetMessageByTimestamp mocks base method.
+func (m *MockInteractor) GetMessageByTimestamp(ctx context.Context, timestamp string) (*slack.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessageByTimestamp", ctx, timestamp)
+	ret0, _ := ret[0].(*slack.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessageByTimestamp indicates an expected call of GetMessageByTimestamp.
+func (mr *MockInteractorMockRecorder) GetMessageByTimestamp(ctx, timestamp any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessageByTimestamp", reflect.TypeOf((*MockInteractor)(nil).GetMessageByTimestamp), ctx, timestamp)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockInteractor) GetUserByEmail(ctx context.Context, email string) (*slack.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", ctx, email)
+	ret0, _ := ret[0].(*slack.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockInteractorMockRecorder) GetUserByEmail(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockInteractor)(nil).GetUserByEmail), ctx, email)
+}
+
+// GetUserByID mocks base method.
+func (m *MockInteractor) GetUserByID(ctx context.Context, userID string) (*slack.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, userID)
+	ret0, _ := ret[0].(*slack.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockInteractorMockRecorder) GetUserByID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockInteractor)(nil).GetUserByID), ctx, userID)
 }
 
 // GetUserIDByEmail mocks base method.
-func (m *MockInteractor) GetUserIDByEmail(arg0 string) (string, error) {
+func (m *MockInteractor) GetUserIDByEmail(ctx context.Context, email string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserIDByEmail", arg0)
+	ret := m.ctrl.Call(m, "GetUserIDByEmail", ctx, email)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserIDByEmail indicates an expected call of GetUserIDByEmail.
-func (mr *MockInteractorMockRecorder) GetUserIDByEmail(arg0 any) *gomock.Call {
+func (mr *MockInteractorMockRecorder) GetUserIDByEmail(ctx, email any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIDByEmail", reflect.TypeOf((*MockInteractor)(nil).GetUserIDByEmail), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIDByEmail", reflect.TypeOf((*MockInteractor)(nil).GetUserIDByEmail), ctx, email)
 }
 
 // RemoveReactionFromMessage mocks base method.
-func (m *MockInteractor) RemoveReactionFromMessage(arg0 string, arg1 *slack.Message) {
+func (m *MockInteractor) RemoveReactionFromMessage(ctx context.Context, reaction string, message *slack.Message) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "RemoveReactionFromMessage", arg0, arg1)
+	m.ctrl.Call(m, "RemoveReactionFromMessage", ctx, reaction, message)
 }
 
 // RemoveReactionFromMessage indicates an expected call of RemoveReactionFromMessage.
-func (mr *MockInteractorMockRecorder) RemoveReactionFromMessage(arg0, arg1 any) *gomock.Call {
+func (mr *MockInteractorMockRecorder) RemoveReactionFromMessage(ctx, reaction, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveReactionFromMessage", reflect.TypeOf((*MockInteractor)(nil).RemoveReactionFromMessage), ctx, reaction, message)
+}
+
+// SendDirectMessage mocks base method.
+func (m *MockInteractor) SendDirectMessage(ctx context.Context, userID, message string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SendDirectMessage", ctx, userID, message)
+}
+
+// SendDirectMessage indicates an expected call of SendDirectMessage.
+func (mr *MockInteractorMockRecorder) SendDirectMessage(ctx, userID, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDirectMessage", reflect.TypeOf((*MockInteractor)(nil).SendDirectMessage), ctx, userID, message)
+}
+
+// SendEphemeral mocks base method.
+func (m *MockInteractor) SendEphemeral(ctx context.Context, userID, message string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SendEphemeral", ctx, userID, message)
+}
+
+// SendEphemeral indicates an expected call of SendEphemeral.
+func (mr *MockInteractorMockRecorder) SendEphemeral(ctx, userID, message any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveReactionFromMessage", reflect.TypeOf((*MockInteractor)(nil).RemoveReactionFromMessage), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEphemeral", reflect.TypeOf((*MockInteractor)(nil).SendEphemeral), ctx, userID, message)
 }
 
 // SendMessage mocks base method.
-func (m *MockInteractor) SendMessage(arg0 string) {
+func (m *MockInteractor) SendMessage(ctx context.Context, message string) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SendMessage", arg0)
+	m.ctrl.Call(m, "SendMessage", ctx, message)
 }
 
 // SendMessage indicates an expected call of SendMessage.
-func (mr *MockInteractorMockRecorder) SendMessage(arg0 any) *gomock.Call {
+func (mr *MockInteractorMockRecorder) SendMessage(ctx, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockInteractor)(nil).SendMessage), ctx, message)
+}
+
+// SendMessageWithBlocks mocks base method.
+func (m *MockInteractor) SendMessageWithBlocks(ctx context.Context, message string, blocks []slack.Block) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SendMessageWithBlocks", ctx, message, blocks)
+}
+
+// SendMessageWithBlocks indicates an expected call of SendMessageWithBlocks.
+func (mr *MockInteractorMockRecorder) SendMessageWithBlocks(ctx, message, blocks any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockInteractor)(nil).SendMessage), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessageWithBlocks", reflect.TypeOf((*MockInteractor)(nil).SendMessageWithBlocks), ctx, message, blocks)
 }
 
 // SendReply mocks base method.
-func (m *MockInteractor) SendReply(arg0 *slack.Message, arg1 string) {
+func (m *MockInteractor) SendReply(ctx context.Context, slackMessage *slack.Message, message string) string {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SendReply", arg0, arg1)
+	ret := m.ctrl.Call(m, "SendReply", ctx, slackMessage, message)
+	ret0, _ := ret[0].(string)
+	return ret0
 }
 
 // SendReply indicates an expected call of SendReply.
-func (mr *MockInteractorMockRecorder) SendReply(arg0, arg1 any) *gomock.Call {
+func (mr *MockInteractorMockRecorder) SendReply(ctx, slackMessage, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendReply", reflect.TypeOf((*MockInteractor)(nil).SendReply), ctx, slackMessage, message)
+}
+
+// MockDeadLetters is a mock of DeadLetters interface.
+type MockDeadLetters struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeadLettersMockRecorder
+	isgomock struct{}
+}
+
+// MockDeadLettersMockRecorder is the mock recorder for MockDeadLetters.
+type MockDeadLettersMockRecorder struct {
+	mock *MockDeadLetters
+}
+
+// NewMockDeadLetters creates a new mock instance.
+func NewMockDeadLetters(ctrl *gomock.Controller) *MockDeadLetters {
+	mock := &MockDeadLetters{ctrl: ctrl}
+	mock.recorder = &MockDeadLettersMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeadLetters) EXPECT() *MockDeadLettersMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockDeadLetters) Add(entry deadletter.Entry) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Add", entry)
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockDeadLettersMockRecorder) Add(entry any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendReply", reflect.TypeOf((*MockInteractor)(nil).SendReply), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockDeadLetters)(nil).Add), entry)
 }
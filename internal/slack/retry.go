@@ -0,0 +1,329 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package slack
+
+import (
+	"context"
+	"errors"
+	"git-slack-bot/internal/metrics"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	defaultMaxAttempts = 5
+	// defaultTimeout bounds each attempt of a slack api call when the configuration sets no timeout.
+	defaultTimeout = 10 * time.Second
+	baseDelay      = 500 * time.Millisecond
+	maxDelay       = 30 * time.Second
+)
+
+// Limit is how often a slack api method may be called, as a rate with bursts of up to Burst calls.
+type Limit struct {
+	PerMinute int
+	Burst     int
+}
+
+// Limits of Slack's rate limit tiers, see https://api.slack.com/apis/rate-limits.
+var (
+	tier2 = Limit{PerMinute: 20, Burst: 5}
+	tier3 = Limit{PerMinute: 50, Burst: 10}
+	tier4 = Limit{PerMinute: 100, Burst: 20}
+	// postMessageLimit is Slack's special limit of about one message per second to a channel.
+	postMessageLimit = Limit{PerMinute: 60, Burst: 5}
+)
+
+// MethodLimits are the limits of the slack api methods the bot calls.
+var MethodLimits = map[string]Limit{
+	"conversations.history": tier3,
+	"conversations.replies": tier3,
+	"chat.postMessage":      postMessageLimit,
+	"chat.postEphemeral":    tier4,
+	"chat.delete":           tier3,
+	"reactions.add":         tier3,
+	"reactions.remove":      tier2,
+	"users.lookupByEmail":   tier3,
+	"users.info":            tier4,
+	"auth.test":             tier4,
+}
+
+// RetryClient is a Client that keeps each slack api method under its rate limit and retries calls that were rate
+// limited anyway, after the Retry-After slack asked for, or failed with a server error, after a jittered backoff.
+// Other errors are returned as they are. Each attempt is bounded by the timeout, the waits in between are not.
+type RetryClient struct {
+	client      Client
+	maxAttempts int
+	timeout     time.Duration
+	buckets     map[string]*tokenBucket
+}
+
+// NewRetryClient wraps client, making up to maxAttempts attempts of each call, each taking at most timeout. A zero
+// maxAttempts defaults to 5 and a zero timeout to 10s.
+func NewRetryClient(client Client, maxAttempts int, timeout time.Duration) *RetryClient {
+	return NewRetryClientWithLimits(client, maxAttempts, timeout, MethodLimits)
+}
+
+// NewRetryClientWithLimits creates a RetryClient keeping the methods to limits. Methods without a limit aren't
+// limited.
+func NewRetryClientWithLimits(client Client, maxAttempts int, timeout time.Duration, limits map[string]Limit) *RetryClient {
+	if maxAttempts == 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	buckets := map[string]*tokenBucket{}
+	for method, limit := range limits {
+		buckets[method] = newTokenBucket(limit)
+	}
+	return &RetryClient{
+		client:      client,
+		maxAttempts: maxAttempts,
+		timeout:     timeout,
+		buckets:     buckets,
+	}
+}
+
+// do calls call until it succeeds, fails with an error that is not worth retrying, runs out of attempts or ctx is
+// done.
+func (c *RetryClient) do(ctx context.Context, method string, call func(ctx context.Context) error) error {
+	bucket := c.buckets[method]
+	for attempt := 1; ; attempt++ {
+		if bucket != nil {
+			err := bucket.wait(ctx)
+			if err != nil {
+				return err
+			}
+		}
+		attemptCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		err := call(attemptCtx)
+		cancel()
+		if err == nil || attempt == c.maxAttempts {
+			return err
+		}
+
+		var delay time.Duration
+		paused := false
+		var rateLimitedError *slack.RateLimitedError
+		var statusCodeError slack.StatusCodeError
+		switch {
+		case errors.As(err, &rateLimitedError):
+			delay = rateLimitedError.RetryAfter
+			if bucket != nil {
+				// Pausing the bucket holds back the other calls of the method too, and this one in its wait.
+				bucket.pause(delay)
+				paused = true
+			}
+			metrics.SlackAPIRetries.WithLabelValues(method, metrics.RetryRateLimited).Inc()
+		case errors.As(err, &statusCodeError) && statusCodeError.Code >= http.StatusInternalServerError:
+			delay = backoff(attempt)
+			metrics.SlackAPIRetries.WithLabelValues(method, metrics.RetryServerError).Inc()
+		default:
+			return err
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			// Waiting would outlast the caller, so fail now rather than when the deadline passes.
+			return err
+		}
+		slog.Warn("Retrying slack api call", slog.String("method", method), slog.Int("attempt", attempt), slog.Duration("delay", delay), slog.Any("error", err))
+		if !paused {
+			err = sleep(ctx, delay)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// backoff returns the delay before retry attempt, doubling with every attempt, with full jitter so that calls failed
+// together don't retry together.
+func backoff(attempt int) time.Duration {
+	delay := min(baseDelay<<(attempt-1), maxDelay)
+	return time.Duration(rand.Int64N(int64(delay)) + 1) //nolint:gosec // Jitter doesn't need a secure random number.
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// tokenBucket holds up to the burst of a limit in tokens, which are refilled at its rate. Each call takes a token.
+type tokenBucket struct {
+	mutex       sync.Mutex
+	perSecond   float64
+	capacity    float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+func newTokenBucket(limit Limit) *tokenBucket {
+	capacity := float64(max(limit.Burst, 1))
+	return &tokenBucket{
+		perSecond: float64(limit.PerMinute) / 60,
+		capacity:  capacity,
+		tokens:    capacity,
+		last:      time.Now(),
+	}
+}
+
+// wait takes a token, waiting until there is one or ctx is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		delay := b.take(time.Now())
+		if delay == 0 {
+			return nil
+		}
+		err := sleep(ctx, delay)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// take takes a token if there is one, and otherwise returns how long until there will be.
+func (b *tokenBucket) take(now time.Time) time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.tokens = min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.perSecond)
+	b.last = now
+	if now.Before(b.pausedUntil) {
+		return b.pausedUntil.Sub(now)
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.perSecond * float64(time.Second))
+}
+
+// pause holds back all calls for delay, as slack asked for after rate limiting one.
+func (b *tokenBucket) pause(delay time.Duration) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.pausedUntil = time.Now().Add(delay)
+	b.tokens = 0
+}
+
+func (c *RetryClient) GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+	var history *slack.GetConversationHistoryResponse
+	err := c.do(ctx, "conversations.history", func(ctx context.Context) error {
+		var err error
+		history, err = c.client.GetConversationHistoryContext(ctx, params)
+		return err
+	})
+	return history, err
+}
+
+func (c *RetryClient) GetConversationRepliesContext(ctx context.Context, params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error) {
+	var replies []slack.Message
+	var hasMore bool
+	var nextCursor string
+	err := c.do(ctx, "conversations.replies", func(ctx context.Context) error {
+		var err error
+		replies, hasMore, nextCursor, err = c.client.GetConversationRepliesContext(ctx, params)
+		return err
+	})
+	return replies, hasMore, nextCursor, err
+}
+
+func (c *RetryClient) PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	var channel, timestamp string
+	err := c.do(ctx, "chat.postMessage", func(ctx context.Context) error {
+		var err error
+		channel, timestamp, err = c.client.PostMessageContext(ctx, channelID, options...)
+		return err
+	})
+	return channel, timestamp, err
+}
+
+func (c *RetryClient) PostEphemeralContext(ctx context.Context, channelID, userID string, options ...slack.MsgOption) (string, error) {
+	var timestamp string
+	err := c.do(ctx, "chat.postEphemeral", func(ctx context.Context) error {
+		var err error
+		timestamp, err = c.client.PostEphemeralContext(ctx, channelID, userID, options...)
+		return err
+	})
+	return timestamp, err
+}
+
+func (c *RetryClient) AddReactionContext(ctx context.Context, name string, item slack.ItemRef) error {
+	return c.do(ctx, "reactions.add", func(ctx context.Context) error {
+		return c.client.AddReactionContext(ctx, name, item)
+	})
+}
+
+func (c *RetryClient) RemoveReactionContext(ctx context.Context, name string, item slack.ItemRef) error {
+	return c.do(ctx, "reactions.remove", func(ctx context.Context) error {
+		return c.client.RemoveReactionContext(ctx, name, item)
+	})
+}
+
+func (c *RetryClient) GetUserByEmailContext(ctx context.Context, email string) (*slack.User, error) {
+	var user *slack.User
+	err := c.do(ctx, "users.lookupByEmail", func(ctx context.Context) error {
+		var err error
+		user, err = c.client.GetUserByEmailContext(ctx, email)
+		return err
+	})
+	return user, err
+}
+
+func (c *RetryClient) GetUserInfoContext(ctx context.Context, userID string) (*slack.User, error) {
+	var user *slack.User
+	err := c.do(ctx, "users.info", func(ctx context.Context) error {
+		var err error
+		user, err = c.client.GetUserInfoContext(ctx, userID)
+		return err
+	})
+	return user, err
+}
+
+func (c *RetryClient) DeleteMessageContext(ctx context.Context, channel, messageTimestamp string) (string, string, error) {
+	var deletedChannel, deletedTimestamp string
+	err := c.do(ctx, "chat.delete", func(ctx context.Context) error {
+		var err error
+		deletedChannel, deletedTimestamp, err = c.client.DeleteMessageContext(ctx, channel, messageTimestamp)
+		return err
+	})
+	return deletedChannel, deletedTimestamp, err
+}
+
+func (c *RetryClient) AuthTestContext(ctx context.Context) (*slack.AuthTestResponse, error) {
+	var response *slack.AuthTestResponse
+	err := c.do(ctx, "auth.test", func(ctx context.Context) error {
+		var err error
+		response, err = c.client.AuthTestContext(ctx)
+		return err
+	})
+	return response, err
+}
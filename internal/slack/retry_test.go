@@ -0,0 +1,176 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package slack_test
+
+import (
+	"context"
+	"errors"
+	"git-slack-bot/internal/slack"
+	mock_slack "git-slack-bot/internal/slack/mocks"
+	"time"
+
+	sl "github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RetryClient", func() {
+	var (
+		mockClient *mock_slack.MockClient
+		client     *slack.RetryClient
+	)
+
+	BeforeEach(func() {
+		mockClient = mock_slack.NewMockClient(gomock.NewController(GinkgoT()))
+		client = slack.NewRetryClientWithLimits(mockClient, 3, 0, nil)
+	})
+
+	It("retries rate limited calls after the delay slack asked for", func() {
+		gomock.InOrder(
+			mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("", "", &sl.RateLimitedError{RetryAfter: 20 * time.Millisecond}),
+			mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("C123", "1700000000.000100", nil),
+		)
+		start := time.Now()
+
+		_, timestamp, err := client.PostMessageContext(context.Background(), "C123")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(timestamp).To(Equal("1700000000.000100"))
+		Expect(time.Since(start)).To(BeNumerically(">=", 20*time.Millisecond))
+	})
+
+	It("holds back other calls of a rate limited method", func() {
+		client = slack.NewRetryClientWithLimits(mockClient, 3, 0, map[string]slack.Limit{"reactions.add": {PerMinute: 6000, Burst: 10}})
+		gomock.InOrder(
+			mockClient.EXPECT().AddReactionContext(gomock.Any(), "+1", gomock.Any()).Return(&sl.RateLimitedError{RetryAfter: 50 * time.Millisecond}),
+			mockClient.EXPECT().AddReactionContext(gomock.Any(), "+1", gomock.Any()).Return(nil).Times(2),
+		)
+		start := time.Now()
+
+		Expect(client.AddReactionContext(context.Background(), "+1", sl.ItemRef{})).To(Succeed())
+		Expect(client.AddReactionContext(context.Background(), "+1", sl.ItemRef{})).To(Succeed())
+
+		Expect(time.Since(start)).To(BeNumerically(">=", 50*time.Millisecond))
+	})
+
+	It("retries server errors", func() {
+		gomock.InOrder(
+			mockClient.EXPECT().AddReactionContext(gomock.Any(), "+1", gomock.Any()).Return(sl.StatusCodeError{Code: 503, Status: "503 Service Unavailable"}),
+			mockClient.EXPECT().AddReactionContext(gomock.Any(), "+1", gomock.Any()).Return(nil),
+		)
+
+		Expect(client.AddReactionContext(context.Background(), "+1", sl.ItemRef{})).To(Succeed())
+	})
+
+	It("gives up after the last attempt", func() {
+		client = slack.NewRetryClientWithLimits(mockClient, 2, 0, nil)
+		mockClient.EXPECT().RemoveReactionContext(gomock.Any(), "+1", gomock.Any()).Return(sl.StatusCodeError{Code: 502, Status: "502 Bad Gateway"}).Times(2)
+
+		Expect(client.RemoveReactionContext(context.Background(), "+1", sl.ItemRef{})).To(MatchError("slack server error: 502 Bad Gateway"))
+	})
+
+	It("does not retry other errors", func() {
+		mockClient.EXPECT().AddReactionContext(gomock.Any(), "+1", gomock.Any()).Return(errors.New("already_reacted")).Times(1)
+		mockClient.EXPECT().GetUserInfoContext(gomock.Any(), "U123").Return(nil, sl.StatusCodeError{Code: 404, Status: "404 Not Found"}).Times(1)
+
+		Expect(client.AddReactionContext(context.Background(), "+1", sl.ItemRef{})).To(MatchError("already_reacted"))
+		Expect(client.GetUserInfoContext(context.Background(), "U123")).Error().To(HaveOccurred())
+	})
+
+	It("does not wait past the deadline of the caller", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("", "", &sl.RateLimitedError{RetryAfter: time.Minute}).Times(1)
+
+		_, _, err := client.PostMessageContext(ctx, "C123")
+
+		Expect(err).To(MatchError(ContainSubstring("slack rate limit exceeded")))
+	})
+
+	It("bounds each attempt with the timeout", func() {
+		client = slack.NewRetryClientWithLimits(mockClient, 3, time.Second, nil)
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).DoAndReturn(func(ctx context.Context, _ string, _ ...sl.MsgOption) (string, string, error) {
+			deadline, ok := ctx.Deadline()
+			Expect(ok).To(BeTrue())
+			Expect(time.Until(deadline)).To(BeNumerically("<=", time.Second))
+			return "", "", nil
+		})
+
+		Expect(client.PostMessageContext(context.Background(), "C123")).Error().ToNot(HaveOccurred())
+	})
+
+	It("keeps an earlier deadline of the caller", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		mockClient.EXPECT().GetUserInfoContext(gomock.Any(), "U123").DoAndReturn(func(ctx context.Context, _ string) (*sl.User, error) {
+			deadline, _ := ctx.Deadline()
+			Expect(time.Until(deadline)).To(BeNumerically("<=", time.Millisecond))
+			return &sl.User{ID: "U123"}, nil
+		})
+
+		Expect(client.GetUserInfoContext(ctx, "U123")).Error().ToNot(HaveOccurred())
+	})
+
+	It("waits for a Retry-After longer than the timeout", func() {
+		client = slack.NewRetryClientWithLimits(mockClient, 3, 20*time.Millisecond, nil)
+		gomock.InOrder(
+			mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("", "", &sl.RateLimitedError{RetryAfter: 50 * time.Millisecond}),
+			mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).DoAndReturn(func(ctx context.Context, _ string, _ ...sl.MsgOption) (string, string, error) {
+				Expect(ctx.Err()).ToNot(HaveOccurred())
+				return "C123", "1700000000.000100", nil
+			}),
+		)
+		start := time.Now()
+
+		_, timestamp, err := client.PostMessageContext(context.Background(), "C123")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(timestamp).To(Equal("1700000000.000100"))
+		Expect(time.Since(start)).To(BeNumerically(">=", 50*time.Millisecond))
+	})
+
+	It("keeps calls of a method to its limit", func() {
+		client = slack.NewRetryClientWithLimits(mockClient, 3, 0, map[string]slack.Limit{"chat.postMessage": {PerMinute: 600, Burst: 1}})
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("C123", "1700000000.000100", nil).Times(2)
+		mockClient.EXPECT().AuthTestContext(gomock.Any()).Return(&sl.AuthTestResponse{}, nil)
+		start := time.Now()
+
+		Expect(client.PostMessageContext(context.Background(), "C123")).Error().ToNot(HaveOccurred())
+		Expect(client.AuthTestContext(context.Background())).Error().ToNot(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", 50*time.Millisecond))
+		Expect(client.PostMessageContext(context.Background(), "C123")).Error().ToNot(HaveOccurred())
+
+		Expect(time.Since(start)).To(BeNumerically(">=", 90*time.Millisecond))
+	})
+
+	It("stops waiting for the limit when the context is cancelled", func() {
+		client = slack.NewRetryClientWithLimits(mockClient, 3, 0, map[string]slack.Limit{"chat.postMessage": {PerMinute: 1, Burst: 1}})
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("C123", "1700000000.000100", nil).Times(1)
+		Expect(client.PostMessageContext(context.Background(), "C123")).Error().ToNot(HaveOccurred())
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, _, err := client.PostMessageContext(ctx, "C123")
+
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+})
@@ -0,0 +1,97 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package slack
+
+import (
+	"context"
+	"sync"
+
+	"github.com/slack-go/slack"
+)
+
+// RotatingClient is a Client calling slack with the latest value of a token which may be rotated. A new client is
+// created whenever the token changes.
+type RotatingClient struct {
+	token     func() string
+	newClient func(token string) Client
+
+	mutex        sync.Mutex
+	currentToken string
+	client       Client
+}
+
+func NewRotatingClient(token func() string, newClient func(token string) Client) *RotatingClient {
+	return &RotatingClient{
+		token:     token,
+		newClient: newClient,
+	}
+}
+
+// current returns the client of the latest token.
+func (c *RotatingClient) current() Client {
+	token := c.token()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.client == nil || token != c.currentToken {
+		c.client = c.newClient(token)
+		c.currentToken = token
+	}
+	return c.client
+}
+
+func (c *RotatingClient) GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+	return c.current().GetConversationHistoryContext(ctx, params)
+}
+
+func (c *RotatingClient) GetConversationRepliesContext(ctx context.Context, params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error) {
+	return c.current().GetConversationRepliesContext(ctx, params)
+}
+
+func (c *RotatingClient) PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	return c.current().PostMessageContext(ctx, channelID, options...)
+}
+
+func (c *RotatingClient) PostEphemeralContext(ctx context.Context, channelID, userID string, options ...slack.MsgOption) (string, error) {
+	return c.current().PostEphemeralContext(ctx, channelID, userID, options...)
+}
+
+func (c *RotatingClient) AddReactionContext(ctx context.Context, name string, item slack.ItemRef) error {
+	return c.current().AddReactionContext(ctx, name, item)
+}
+
+func (c *RotatingClient) RemoveReactionContext(ctx context.Context, name string, item slack.ItemRef) error {
+	return c.current().RemoveReactionContext(ctx, name, item)
+}
+
+func (c *RotatingClient) GetUserByEmailContext(ctx context.Context, email string) (*slack.User, error) {
+	return c.current().GetUserByEmailContext(ctx, email)
+}
+
+func (c *RotatingClient) GetUserInfoContext(ctx context.Context, user string) (*slack.User, error) {
+	return c.current().GetUserInfoContext(ctx, user)
+}
+
+func (c *RotatingClient) DeleteMessageContext(ctx context.Context, channel, messageTimestamp string) (string, string, error) {
+	return c.current().DeleteMessageContext(ctx, channel, messageTimestamp)
+}
+
+func (c *RotatingClient) AuthTestContext(ctx context.Context) (*slack.AuthTestResponse, error) {
+	return c.current().AuthTestContext(ctx)
+}
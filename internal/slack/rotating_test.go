@@ -0,0 +1,72 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package slack_test
+
+import (
+	"context"
+	"git-slack-bot/internal/slack"
+	mock_slack "git-slack-bot/internal/slack/mocks"
+
+	sl "github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RotatingClient", func() {
+	var (
+		token   string
+		clients map[string]*mock_slack.MockClient
+		created []string
+		client  *slack.RotatingClient
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		token = "xoxb-1"
+		clients = map[string]*mock_slack.MockClient{"xoxb-1": mock_slack.NewMockClient(mockCtrl), "xoxb-2": mock_slack.NewMockClient(mockCtrl)}
+		created = nil
+		client = slack.NewRotatingClient(func() string { return token }, func(token string) slack.Client {
+			created = append(created, token)
+			return clients[token]
+		})
+	})
+
+	It("reuses the client while the token is unchanged", func() {
+		clients["xoxb-1"].EXPECT().AuthTestContext(gomock.Any()).Return(&sl.AuthTestResponse{}, nil).Times(2)
+
+		Expect(client.AuthTestContext(context.Background())).Error().ToNot(HaveOccurred())
+		Expect(client.AuthTestContext(context.Background())).Error().ToNot(HaveOccurred())
+
+		Expect(created).To(Equal([]string{"xoxb-1"}))
+	})
+
+	It("creates a client with the rotated token", func() {
+		clients["xoxb-1"].EXPECT().AddReactionContext(gomock.Any(), "+1", gomock.Any())
+		clients["xoxb-2"].EXPECT().AddReactionContext(gomock.Any(), "+1", gomock.Any())
+
+		Expect(client.AddReactionContext(context.Background(), "+1", sl.ItemRef{})).To(Succeed())
+		token = "xoxb-2"
+		Expect(client.AddReactionContext(context.Background(), "+1", sl.ItemRef{})).To(Succeed())
+
+		Expect(created).To(Equal([]string{"xoxb-1", "xoxb-2"}))
+	})
+})
@@ -0,0 +1,160 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package slack
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/slack-go/slack"
+)
+
+// shadowThreads bounds how many posted messages are remembered to thread their mirrored replies.
+const shadowThreads = 1000
+
+// ShadowClient is a Client that mirrors everything posted through it into a second channel, with writes to the
+// mirror going to mirrorClient. Replies and reactions land on the mirrored copy of their message. Failing to mirror
+// is logged and never fails the call.
+type ShadowClient struct {
+	Client
+	mirrorClient    Client
+	shadowChannelID string
+
+	mutex       sync.Mutex
+	mirrored    map[string]string
+	mirrorOrder []string
+}
+
+// NewShadowClient wraps client. mirrorClient is usually client itself, or the real client when client is a dry run.
+func NewShadowClient(client, mirrorClient Client, shadowChannelID string) *ShadowClient {
+	return &ShadowClient{
+		Client:          client,
+		mirrorClient:    mirrorClient,
+		shadowChannelID: shadowChannelID,
+		mirrored:        map[string]string{},
+	}
+}
+
+func (c *ShadowClient) PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	channel, timestamp, err := c.Client.PostMessageContext(ctx, channelID, options...)
+	if err != nil {
+		return channel, timestamp, err
+	}
+
+	_, values, err := slack.UnsafeApplyMsgOptions("", channelID, "", options...)
+	if err != nil {
+		slog.Error("Failed to mirror message to the shadow channel", slog.Any("error", err))
+		return channel, timestamp, nil
+	}
+	mirrorOptions := options
+	if threadTimestamp := values.Get("thread_ts"); threadTimestamp != "" {
+		mirrorTimestamp, found := c.mirrorOf(threadTimestamp)
+		if !found {
+			// The parent was posted before the mirroring started, there is no copy to reply to.
+			return channel, timestamp, nil
+		}
+		mirrorOptions = append(mirrorOptions[:len(mirrorOptions):len(mirrorOptions)], slack.MsgOptionTS(mirrorTimestamp))
+	}
+
+	_, mirrorTimestamp, err := c.mirrorClient.PostMessageContext(ctx, c.shadowChannelID, mirrorOptions...)
+	if err != nil {
+		slog.Error("Failed to mirror message to the shadow channel", slog.Any("error", err))
+		return channel, timestamp, nil
+	}
+	c.remember(timestamp, mirrorTimestamp)
+	return channel, timestamp, nil
+}
+
+func (c *ShadowClient) PostEphemeralContext(ctx context.Context, channelID, userID string, options ...slack.MsgOption) (string, error) {
+	timestamp, err := c.Client.PostEphemeralContext(ctx, channelID, userID, options...)
+	if err != nil {
+		return timestamp, err
+	}
+
+	_, mirrorErr := c.mirrorClient.PostEphemeralContext(ctx, c.shadowChannelID, userID, options...)
+	if mirrorErr != nil {
+		slog.Error("Failed to mirror ephemeral message to the shadow channel", slog.Any("error", mirrorErr))
+	}
+	return timestamp, nil
+}
+
+func (c *ShadowClient) AddReactionContext(ctx context.Context, name string, item slack.ItemRef) error {
+	err := c.Client.AddReactionContext(ctx, name, item)
+	if err != nil {
+		return err
+	}
+
+	if mirrorTimestamp, found := c.mirrorOf(item.Timestamp); found {
+		mirrorErr := c.mirrorClient.AddReactionContext(ctx, name, slack.ItemRef{Channel: c.shadowChannelID, Timestamp: mirrorTimestamp})
+		if mirrorErr != nil {
+			slog.Error("Failed to mirror reaction to the shadow channel", slog.Any("error", mirrorErr))
+		}
+	}
+	return nil
+}
+
+func (c *ShadowClient) RemoveReactionContext(ctx context.Context, name string, item slack.ItemRef) error {
+	err := c.Client.RemoveReactionContext(ctx, name, item)
+	if err != nil {
+		return err
+	}
+
+	if mirrorTimestamp, found := c.mirrorOf(item.Timestamp); found {
+		mirrorErr := c.mirrorClient.RemoveReactionContext(ctx, name, slack.ItemRef{Channel: c.shadowChannelID, Timestamp: mirrorTimestamp})
+		if mirrorErr != nil {
+			slog.Error("Failed to mirror reaction removal to the shadow channel", slog.Any("error", mirrorErr))
+		}
+	}
+	return nil
+}
+
+func (c *ShadowClient) DeleteMessageContext(ctx context.Context, channel, messageTimestamp string) (string, string, error) {
+	deletedChannel, deletedTimestamp, err := c.Client.DeleteMessageContext(ctx, channel, messageTimestamp)
+	if err != nil {
+		return deletedChannel, deletedTimestamp, err
+	}
+
+	if mirrorTimestamp, found := c.mirrorOf(messageTimestamp); found {
+		_, _, mirrorErr := c.mirrorClient.DeleteMessageContext(ctx, c.shadowChannelID, mirrorTimestamp)
+		if mirrorErr != nil {
+			slog.Error("Failed to delete mirrored message from the shadow channel", slog.Any("error", mirrorErr))
+		}
+	}
+	return deletedChannel, deletedTimestamp, nil
+}
+
+func (c *ShadowClient) mirrorOf(timestamp string) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	mirrorTimestamp, found := c.mirrored[timestamp]
+	return mirrorTimestamp, found
+}
+
+func (c *ShadowClient) remember(timestamp, mirrorTimestamp string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.mirrored[timestamp] = mirrorTimestamp
+	c.mirrorOrder = append(c.mirrorOrder, timestamp)
+	if len(c.mirrorOrder) > shadowThreads {
+		delete(c.mirrored, c.mirrorOrder[0])
+		c.mirrorOrder = c.mirrorOrder[1:]
+	}
+}
@@ -0,0 +1,89 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package slack_test
+
+import (
+	"context"
+	"errors"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/slack"
+	mock_slack "git-slack-bot/internal/slack/mocks"
+
+	sl "github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ShadowClient", func() {
+	var (
+		mockClient       *mock_slack.MockClient
+		mockMirrorClient *mock_slack.MockClient
+		connector        *slack.Connector
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockClient = mock_slack.NewMockClient(mockCtrl)
+		mockMirrorClient = mock_slack.NewMockClient(mockCtrl)
+		connector = slack.NewSlackConnector(config.SlackConfiguration{ChannelID: "C123"}, slack.NewShadowClient(mockClient, mockMirrorClient, "CSHADOW"))
+		mockClient.EXPECT().GetConversationRepliesContext(gomock.Any(), gomock.Any()).Return(nil, false, "", nil).AnyTimes()
+	})
+
+	It("mirrors posted messages into the shadow channel", func() {
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("C123", "1.0", nil)
+		mockMirrorClient.EXPECT().PostMessageContext(gomock.Any(), "CSHADOW", gomock.Any()).Return("CSHADOW", "9.0", nil)
+
+		connector.SendMessage(context.Background(), "https://github.com/org/repo/pull/1")
+	})
+
+	It("threads replies and reactions on the mirrored message", func() {
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("C123", "1.0", nil)
+		mockMirrorClient.EXPECT().PostMessageContext(gomock.Any(), "CSHADOW", gomock.Any()).Return("CSHADOW", "9.0", nil)
+		connector.SendMessage(context.Background(), "https://github.com/org/repo/pull/1")
+
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("C123", "2.0", nil)
+		mockMirrorClient.EXPECT().PostMessageContext(gomock.Any(), "CSHADOW", gomock.Any()).Do(func(_ context.Context, _ string, options ...sl.MsgOption) {
+			_, values, err := sl.UnsafeApplyMsgOptions("", "CSHADOW", "", options...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(values.Get("thread_ts")).To(Equal("9.0"))
+			Expect(values.Get("text")).To(Equal("approved"))
+		}).Return("CSHADOW", "9.1", nil)
+		connector.SendReply(context.Background(), &sl.Message{Msg: sl.Msg{Timestamp: "1.0"}}, "approved")
+
+		mockClient.EXPECT().AddReactionContext(gomock.Any(), "+1", sl.ItemRef{Channel: "C123", Timestamp: "1.0"}).Return(nil)
+		mockMirrorClient.EXPECT().AddReactionContext(gomock.Any(), "+1", sl.ItemRef{Channel: "CSHADOW", Timestamp: "9.0"}).Return(nil)
+		connector.AddReactionToMessage(context.Background(), "+1", &sl.Message{Msg: sl.Msg{Timestamp: "1.0"}})
+	})
+
+	It("does not mirror replies to messages it has not mirrored", func() {
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("C123", "2.0", nil)
+
+		Expect(connector.SendReply(context.Background(), &sl.Message{Msg: sl.Msg{Timestamp: "1.0"}}, "approved")).To(Equal("2.0"))
+	})
+
+	It("does not fail when mirroring fails", func() {
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("C123", "1.0", nil)
+		mockMirrorClient.EXPECT().PostMessageContext(gomock.Any(), "CSHADOW", gomock.Any()).Return("", "", errors.New("not_in_channel"))
+
+		Expect(connector.SendReply(context.Background(), &sl.Message{}, "message")).To(Equal("1.0"))
+	})
+})
@@ -19,94 +19,461 @@ Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
 
 package slack
 
-//go:generate mockgen -destination=./mocks/slack.go . Client,Interactor
+//go:generate mockgen -destination=./mocks/slack.go . Client,Interactor,DeadLetters
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/deadletter"
+	"git-slack-bot/internal/metrics"
+	"git-slack-bot/internal/tracing"
 	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/trace"
 	"log/slog"
+	"slices"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
+var errMessageNotFound = errors.New("could not find message")
+
 type Client interface {
-	GetConversationHistory(params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error)
-	PostMessage(channelID string, options ...slack.MsgOption) (string, string, error)
-	AddReaction(name string, item slack.ItemRef) error
-	RemoveReaction(name string, item slack.ItemRef) error
-	GetUserByEmail(email string) (*slack.User, error)
+	GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error)
+	GetConversationRepliesContext(ctx context.Context, params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error)
+	PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error)
+	PostEphemeralContext(ctx context.Context, channelID, userID string, options ...slack.MsgOption) (string, error)
+	AddReactionContext(ctx context.Context, name string, item slack.ItemRef) error
+	RemoveReactionContext(ctx context.Context, name string, item slack.ItemRef) error
+	GetUserByEmailContext(ctx context.Context, email string) (*slack.User, error)
+	GetUserInfoContext(ctx context.Context, user string) (*slack.User, error)
+	DeleteMessageContext(ctx context.Context, channel, messageTimestamp string) (string, string, error)
+	AuthTestContext(ctx context.Context) (*slack.AuthTestResponse, error)
 }
 
 type Interactor interface {
-	SendMessage(message string)
-	SendReply(slackMessage *slack.Message, message string)
-	AddReactionToMessage(reaction string, message *slack.Message)
-	RemoveReactionFromMessage(reaction string, message *slack.Message)
-	GetMessage(messageKey string) (*slack.Message, error)
-	GetUserIDByEmail(email string) (string, error)
+	SendMessage(ctx context.Context, message string)
+	SendMessageWithBlocks(ctx context.Context, message string, blocks []slack.Block)
+	Announce(ctx context.Context, pullRequestURL, message string, blocks []slack.Block)
+	SendEphemeral(ctx context.Context, userID, message string)
+	SendReply(ctx context.Context, slackMessage *slack.Message, message string) string
+	DeleteMessage(ctx context.Context, timestamp string)
+	AddReactionToMessage(ctx context.Context, reaction string, message *slack.Message)
+	RemoveReactionFromMessage(ctx context.Context, reaction string, message *slack.Message)
+	GetMessage(ctx context.Context, messageKey string) (*slack.Message, error)
+	GetMessageByTimestamp(ctx context.Context, timestamp string) (*slack.Message, error)
+	GetUserIDByEmail(ctx context.Context, email string) (string, error)
+	GetUserByEmail(ctx context.Context, email string) (*slack.User, error)
+	GetUserByID(ctx context.Context, userID string) (*slack.User, error)
+	SendDirectMessage(ctx context.Context, userID, message string)
+}
+
+// DeadLetters keeps the posts and reactions that failed, so that they can be made again later.
+type DeadLetters interface {
+	Add(entry deadletter.Entry)
 }
 
 type Connector struct {
-	client    Client
-	channelID string
+	client      Client
+	channelID   string
+	deadLetters DeadLetters
+	// announcements keeps each pull request from being announced more than once within the announcement window.
+	announcements *announcements
+	// botUserID is the user the token reacts as, looked up when first needed.
+	botUserID atomic.Pointer[string]
 }
 
 func NewSlackConnector(cfg config.SlackConfiguration, client Client) *Connector {
+	return NewSlackConnectorWithDeadLetters(cfg, client, nil)
+}
+
+// NewSlackConnectorWithDeadLetters creates a Connector which hands posts and reactions that fail to deadLetters.
+func NewSlackConnectorWithDeadLetters(cfg config.SlackConfiguration, client Client, deadLetters DeadLetters) *Connector {
 	return &Connector{
-		client:    client,
-		channelID: cfg.ChannelID,
+		client:        client,
+		channelID:     cfg.ChannelID,
+		deadLetters:   deadLetters,
+		announcements: newAnnouncements(cfg.AnnouncementWindow),
+	}
+}
+
+func (sc *Connector) SendMessage(ctx context.Context, message string) {
+	sc.postToChannel(ctx, deadletter.OperationMessage, message, nil)
+}
+
+// SendMessageWithBlocks posts blocks to the channel. message is the notification fallback and is what GetMessage
+// matches on.
+func (sc *Connector) SendMessageWithBlocks(ctx context.Context, message string, blocks []slack.Block) {
+	sc.postToChannel(ctx, deadletter.OperationMessage, message, blocks)
+}
+
+// Announce posts the announcement of the pull request at pullRequestURL to the channel, with blocks if there are any,
+// unless it was announced within the announcement window. An announcement that failed doesn't count, so the pull
+// request is announced by the next event about it or by delivering the dead letter, whichever comes first.
+func (sc *Connector) Announce(ctx context.Context, pullRequestURL, message string, blocks []slack.Block) {
+	if !sc.announcements.claim(pullRequestURL, time.Now()) {
+		slog.InfoContext(ctx, "Pull request was announced already", slog.String("url", pullRequestURL))
+		return
+	}
+	err := sc.postToChannel(ctx, deadletter.OperationAnnouncement, message, blocks)
+	if err != nil {
+		sc.announcements.release(pullRequestURL)
+	}
+}
+
+// postToChannel posts message, with blocks if there are any, to the channel and hands it to the dead letters as
+// operation if that fails.
+func (sc *Connector) postToChannel(ctx context.Context, operation, message string, blocks []slack.Block) error {
+	options := []slack.MsgOption{slack.MsgOptionText(message, false)}
+	if len(blocks) > 0 {
+		options = append(options, slack.MsgOptionBlocks(blocks...))
+	}
+	_, _, err := sc.client.PostMessageContext(ctx, sc.channelID, options...)
+	metrics.SlackAPICall("chat.postMessage", err)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to send message to slack", slog.String("message", message), slog.Any("error", err))
+		payload := deadletter.Payload{Text: message}
+		if len(blocks) > 0 {
+			serialised, serialiseErr := json.Marshal(slack.Blocks{BlockSet: blocks})
+			if serialiseErr != nil {
+				slog.ErrorContext(ctx, "Failed to serialise blocks of failed message, keeping its text only", slog.Any("error", serialiseErr))
+			} else {
+				payload.Blocks = serialised
+			}
+		}
+		sc.deadLetter(operation, sc.channelID, payload, message, err)
+	}
+	return err
+}
+
+// SendEphemeral posts message to the channel so that only the user can see it.
+func (sc *Connector) SendEphemeral(ctx context.Context, userID, message string) {
+	_, err := sc.client.PostEphemeralContext(ctx, sc.channelID, userID, slack.MsgOptionText(message, false))
+	metrics.SlackAPICall("chat.postEphemeral", err)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to send ephemeral message to slack", slog.String("user", userID), slog.Any("error", err))
 	}
 }
 
-func (sc *Connector) SendMessage(message string) {
-	_, _, err := sc.client.PostMessage(sc.channelID, slack.MsgOptionText(message, false))
+// SendDirectMessage posts message to the app's direct message conversation with the user.
+func (sc *Connector) SendDirectMessage(ctx context.Context, userID, message string) {
+	_, _, err := sc.client.PostMessageContext(ctx, userID, slack.MsgOptionText(message, false))
+	metrics.SlackAPICall("chat.postMessage", err)
 	if err != nil {
-		slog.Error("Failed to send message to slack", slog.String("message", message), slog.Any("error", err))
+		slog.ErrorContext(ctx, "Failed to send direct message to slack", slog.String("user", userID), slog.Any("error", err))
+		sc.deadLetter(deadletter.OperationDirectMessage, userID, deadletter.Payload{Text: message}, message, err)
 	}
 }
 
-func (sc *Connector) SendReply(slackMessage *slack.Message, messageBody string) {
-	_, _, err := sc.client.PostMessage(sc.channelID, slack.MsgOptionText(messageBody, false), slack.MsgOptionTS(slackMessage.Timestamp))
+// SendReply posts a message in the thread of slackMessage and returns the timestamp of the reply, or an empty
+// string if it could not be posted. A reply the thread already has, such as for a redelivered webhook, isn't posted
+// again and the timestamp of the existing one is returned. If the thread can't be read the reply is posted anyway.
+func (sc *Connector) SendReply(ctx context.Context, slackMessage *slack.Message, messageBody string) string {
+	existing, err := sc.findReply(ctx, sc.channelID, slackMessage.Timestamp, messageBody)
+	if err == nil {
+		slog.InfoContext(ctx, "Thread has the reply already", slog.String("thread", slackMessage.Timestamp))
+		return existing.Timestamp
+	}
+	if !errors.Is(err, errMessageNotFound) {
+		slog.WarnContext(ctx, "Failed to read the thread, replying without checking for the same reply", slog.String("thread", slackMessage.Timestamp), slog.Any("error", err))
+	}
+	_, timestamp, err := sc.client.PostMessageContext(ctx, sc.channelID, slack.MsgOptionText(messageBody, false), slack.MsgOptionTS(slackMessage.Timestamp))
+	metrics.SlackAPICall("chat.postMessage", err)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to send message to slack", slog.String("message", messageBody), slog.Any("error", err))
+		sc.deadLetter(deadletter.OperationReply, sc.channelID, deadletter.Payload{Text: messageBody, Timestamp: slackMessage.Timestamp}, slackMessage.Text+messageBody, err)
+		return ""
+	}
+	return timestamp
+}
+
+func (sc *Connector) DeleteMessage(ctx context.Context, timestamp string) {
+	_, _, err := sc.client.DeleteMessageContext(ctx, sc.channelID, timestamp)
+	metrics.SlackAPICall("chat.delete", err)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to delete message", slog.String("timestamp", timestamp), slog.Any("error", err))
+	}
+}
+
+// AddReactionToMessage adds reaction to message, unless the message shows that the bot already reacted with it.
+func (sc *Connector) AddReactionToMessage(ctx context.Context, reaction string, message *slack.Message) {
+	if sc.hasReacted(ctx, reaction, message) {
+		slog.DebugContext(ctx, "Message already has the reaction", slog.String("reaction", reaction), slog.String("timestamp", message.Timestamp))
+		return
+	}
+	err := sc.client.AddReactionContext(ctx, reaction, slack.ItemRef{Channel: sc.channelID, Timestamp: message.Timestamp})
+	metrics.SlackAPICall("reactions.add", err)
+	if reactionHolds(err) {
+		slog.DebugContext(ctx, "Message already has the reaction", slog.String("reaction", reaction), slog.String("timestamp", message.Timestamp))
+		return
+	}
 	if err != nil {
-		slog.Error("Failed to send message to slack", slog.String("message", messageBody), slog.Any("error", err))
+		slog.ErrorContext(ctx, "Failed to add reaction to message", slog.Any("error", err))
+		sc.deadLetter(deadletter.OperationAddReaction, sc.channelID, deadletter.Payload{Timestamp: message.Timestamp, Reaction: reaction}, message.Text, err)
 	}
 }
 
-func (sc *Connector) AddReactionToMessage(reaction string, message *slack.Message) {
-	err := sc.client.AddReaction(reaction, slack.ItemRef{Channel: sc.channelID, Timestamp: message.Timestamp})
+// RemoveReactionFromMessage removes reaction from message, unless the message shows that the bot has not reacted
+// with it.
+func (sc *Connector) RemoveReactionFromMessage(ctx context.Context, reaction string, message *slack.Message) {
+	if sc.lacksReaction(ctx, reaction, message) {
+		slog.DebugContext(ctx, "Message does not have the reaction", slog.String("reaction", reaction), slog.String("timestamp", message.Timestamp))
+		return
+	}
+	err := sc.client.RemoveReactionContext(ctx, reaction, slack.ItemRef{Channel: sc.channelID, Timestamp: message.Timestamp})
+	metrics.SlackAPICall("reactions.remove", err)
+	if reactionHolds(err) {
+		slog.DebugContext(ctx, "Message does not have the reaction", slog.String("reaction", reaction), slog.String("timestamp", message.Timestamp))
+		return
+	}
 	if err != nil {
-		slog.Error("Failed to add reaction to message", slog.Any("error", err))
+		slog.ErrorContext(ctx, "Failed to remove reaction from message", slog.Any("error", err))
+		sc.deadLetter(deadletter.OperationRemoveReaction, sc.channelID, deadletter.Payload{Timestamp: message.Timestamp, Reaction: reaction}, message.Text, err)
+	}
+}
+
+// hasReacted reports whether the reactions of message include reaction by the bot. The reactions are as of when the
+// message was fetched, so false only means that the reaction has to be added to be sure.
+func (sc *Connector) hasReacted(ctx context.Context, reaction string, message *slack.Message) bool {
+	index := slices.IndexFunc(message.Reactions, func(item slack.ItemReaction) bool {
+		return item.Name == reaction
+	})
+	if index < 0 {
+		return false
+	}
+	botUserID := sc.lookUpBotUserID(ctx)
+	return botUserID != "" && slices.Contains(message.Reactions[index].Users, botUserID)
+}
+
+// lacksReaction reports whether message has no reaction by the bot to remove. Slack only lists some of the users of
+// popular reactions, so a reaction whose users are incomplete is assumed to include the bot.
+func (sc *Connector) lacksReaction(ctx context.Context, reaction string, message *slack.Message) bool {
+	if message.Reactions == nil {
+		// Messages that weren't fetched with their reactions say nothing about them.
+		return false
+	}
+	index := slices.IndexFunc(message.Reactions, func(item slack.ItemReaction) bool {
+		return item.Name == reaction
+	})
+	if index < 0 {
+		return true
+	}
+	item := message.Reactions[index]
+	if len(item.Users) < item.Count {
+		return false
 	}
+	botUserID := sc.lookUpBotUserID(ctx)
+	return botUserID != "" && !slices.Contains(item.Users, botUserID)
 }
 
-func (sc *Connector) RemoveReactionFromMessage(reaction string, message *slack.Message) {
-	err := sc.client.RemoveReaction(reaction, slack.ItemRef{Channel: sc.channelID, Timestamp: message.Timestamp})
+// lookUpBotUserID returns the user the token acts as, or an empty string if it can't be looked up.
+func (sc *Connector) lookUpBotUserID(ctx context.Context) string {
+	if botUserID := sc.botUserID.Load(); botUserID != nil {
+		return *botUserID
+	}
+	response, err := sc.client.AuthTestContext(ctx)
+	metrics.SlackAPICall("auth.test", err)
 	if err != nil {
-		slog.Error("Failed to remove reaction from message", slog.Any("error", err))
+		slog.WarnContext(ctx, "Failed to look up the bot user, reacting without checking the reactions of the message", slog.Any("error", err))
+		return ""
+	}
+	if response.UserID != "" {
+		sc.botUserID.Store(&response.UserID)
+	}
+	return response.UserID
+}
+
+// deadLetter hands a failed operation to the dead letters, if there are any. text is searched for the url of the pull
+// request the operation is about.
+func (sc *Connector) deadLetter(operation, channel string, payload deadletter.Payload, text string, err error) {
+	if sc.deadLetters == nil {
+		return
+	}
+	sc.deadLetters.Add(deadletter.Entry{
+		Operation: operation,
+		Channel:   channel,
+		Payload:   payload,
+		PRURL:     deadletter.PullRequestURL(text),
+		Error:     err.Error(),
+	})
+}
+
+// Deliver makes the operation of a dead letter again. Unlike the other methods it returns the error, and does not
+// hand the operation to the dead letters again. Announcements are held to the announcement window like live ones, so
+// a pull request announced in the meantime isn't announced again. Announcements and replies are looked up in the
+// channel or thread first, as the post may have gone through even though it failed on the bot's side, such as when
+// the response timed out.
+func (sc *Connector) Deliver(ctx context.Context, entry deadletter.Entry) error {
+	switch entry.Operation {
+	case deadletter.OperationMessage, deadletter.OperationDirectMessage:
+		return sc.deliverMessage(ctx, entry)
+	case deadletter.OperationReply:
+		_, err := sc.findReply(ctx, entry.Channel, entry.Payload.Timestamp, entry.Payload.Text)
+		if err == nil {
+			slog.InfoContext(ctx, "Failed reply was posted after all, dropping it", slog.String("thread", entry.Payload.Timestamp))
+			return nil
+		}
+		if !errors.Is(err, errMessageNotFound) {
+			return fmt.Errorf("failed to look up reply: %w", err)
+		}
+		return sc.deliverMessage(ctx, entry)
+	case deadletter.OperationAnnouncement:
+		if !sc.announcements.claim(entry.PRURL, time.Now()) {
+			slog.InfoContext(ctx, "Pull request was announced in the meantime, dropping its failed announcement", slog.String("url", entry.PRURL))
+			return nil
+		}
+		_, err := sc.findMessage(ctx, fmt.Sprintf("<%s>", entry.PRURL))
+		if err == nil {
+			slog.InfoContext(ctx, "Failed announcement was posted after all, dropping it", slog.String("url", entry.PRURL))
+			return nil
+		}
+		if !errors.Is(err, errMessageNotFound) {
+			sc.announcements.release(entry.PRURL)
+			return fmt.Errorf("failed to look up announcement: %w", err)
+		}
+		err = sc.deliverMessage(ctx, entry)
+		if err != nil {
+			sc.announcements.release(entry.PRURL)
+		}
+		return err
+	case deadletter.OperationAddReaction:
+		err := sc.client.AddReactionContext(ctx, entry.Payload.Reaction, slack.ItemRef{Channel: entry.Channel, Timestamp: entry.Payload.Timestamp})
+		metrics.SlackAPICall("reactions.add", err)
+		if reactionHolds(err) {
+			return nil
+		}
+		return err
+	case deadletter.OperationRemoveReaction:
+		err := sc.client.RemoveReactionContext(ctx, entry.Payload.Reaction, slack.ItemRef{Channel: entry.Channel, Timestamp: entry.Payload.Timestamp})
+		metrics.SlackAPICall("reactions.remove", err)
+		if reactionHolds(err) {
+			return nil
+		}
+		return err
+	default:
+		return fmt.Errorf("unknown operation %q", entry.Operation)
+	}
+}
+
+func (sc *Connector) deliverMessage(ctx context.Context, entry deadletter.Entry) error {
+	options := []slack.MsgOption{slack.MsgOptionText(entry.Payload.Text, false)}
+	if len(entry.Payload.Blocks) > 0 {
+		var blocks slack.Blocks
+		err := json.Unmarshal(entry.Payload.Blocks, &blocks)
+		if err != nil {
+			return fmt.Errorf("failed to parse blocks: %w", err)
+		}
+		options = append(options, slack.MsgOptionBlocks(blocks.BlockSet...))
 	}
+	if entry.Operation == deadletter.OperationReply {
+		options = append(options, slack.MsgOptionTS(entry.Payload.Timestamp))
+	}
+	_, _, err := sc.client.PostMessageContext(ctx, entry.Channel, options...)
+	metrics.SlackAPICall("chat.postMessage", err)
+	return err
+}
+
+// reactionHolds reports whether err means that the message already has the reaction being added, or lacks the one
+// being removed, which is as good as success.
+func reactionHolds(err error) bool {
+	var response slack.SlackErrorResponse
+	return errors.As(err, &response) && (response.Err == "already_reacted" || response.Err == "no_reaction")
 }
 
-func (sc *Connector) GetMessage(messageKey string) (*slack.Message, error) {
-	messages, err := sc.client.GetConversationHistory(&slack.GetConversationHistoryParameters{
+func (sc *Connector) GetMessage(ctx context.Context, messageKey string) (*slack.Message, error) {
+	ctx, span := tracing.Start(ctx, "slack.GetMessage", tracing.MessageKey.String(messageKey))
+	message, err := sc.findMessage(ctx, messageKey)
+	tracing.End(span, err)
+	return message, err
+}
+
+func (sc *Connector) findMessage(ctx context.Context, messageKey string) (*slack.Message, error) {
+	span := trace.SpanFromContext(ctx)
+	messages, err := sc.client.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
 		ChannelID: sc.channelID,
 	})
+	metrics.SlackAPICall("conversations.history", err)
 	if err != nil {
-		slog.Error("Failed to get conversation history", slog.Any("error", err))
+		slog.ErrorContext(ctx, "Failed to get conversation history", slog.Any("error", err))
 		return nil, err
 	}
 	for _, message := range messages.Messages {
 		if strings.Contains(message.Text, messageKey) {
+			metrics.MessageLookups.WithLabelValues(metrics.LookupHit).Inc()
+			span.SetAttributes(tracing.LookupResult.String(metrics.LookupHit))
 			return &message, nil
 		}
 	}
-	return nil, errors.New("could not find message")
+	metrics.MessageLookups.WithLabelValues(metrics.LookupMiss).Inc()
+	span.SetAttributes(tracing.LookupResult.String(metrics.LookupMiss))
+	return nil, errMessageNotFound
+}
+
+// findReply returns the reply in the thread of threadTimestamp in channel whose text is text.
+func (sc *Connector) findReply(ctx context.Context, channel, threadTimestamp, text string) (*slack.Message, error) {
+	replies, _, _, err := sc.client.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+		ChannelID: channel,
+		Timestamp: threadTimestamp,
+	})
+	metrics.SlackAPICall("conversations.replies", err)
+	if err != nil {
+		return nil, err
+	}
+	for _, reply := range replies {
+		if reply.Timestamp != threadTimestamp && reply.Text == text {
+			return &reply, nil
+		}
+	}
+	return nil, errMessageNotFound
+}
+
+// GetMessageByTimestamp returns the message of the channel posted at timestamp, such as the parent of a thread.
+func (sc *Connector) GetMessageByTimestamp(ctx context.Context, timestamp string) (*slack.Message, error) {
+	messages, err := sc.client.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+		ChannelID: sc.channelID,
+		Latest:    timestamp,
+		Oldest:    timestamp,
+		Inclusive: true,
+		Limit:     1,
+	})
+	metrics.SlackAPICall("conversations.history", err)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages.Messages) == 0 {
+		return nil, errMessageNotFound
+	}
+	return &messages.Messages[0], nil
 }
 
-func (sc *Connector) GetUserIDByEmail(email string) (string, error) {
-	user, err := sc.client.GetUserByEmail(email)
+func (sc *Connector) GetUserIDByEmail(ctx context.Context, email string) (string, error) {
+	user, err := sc.client.GetUserByEmailContext(ctx, email)
+	metrics.SlackAPICall("users.lookupByEmail", err)
 	if err != nil {
 		return "", err
 	}
 
 	return user.ID, nil
 }
+
+func (sc *Connector) GetUserByEmail(ctx context.Context, email string) (*slack.User, error) {
+	user, err := sc.client.GetUserByEmailContext(ctx, email)
+	metrics.SlackAPICall("users.lookupByEmail", err)
+	return user, err
+}
+
+func (sc *Connector) GetUserByID(ctx context.Context, userID string) (*slack.User, error) {
+	user, err := sc.client.GetUserInfoContext(ctx, userID)
+	metrics.SlackAPICall("users.info", err)
+	return user, err
+}
+
+// CheckAuth verifies that the token is valid with auth.test.
+func (sc *Connector) CheckAuth(ctx context.Context) error {
+	_, err := sc.client.AuthTestContext(ctx)
+	metrics.SlackAPICall("auth.test", err)
+	return err
+}
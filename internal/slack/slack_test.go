@@ -20,10 +20,14 @@ Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
 package slack_test
 
 import (
+	"context"
+	"errors"
 	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/deadletter"
 	"git-slack-bot/internal/slack"
 	mock_slack "git-slack-bot/internal/slack/mocks"
 	"testing"
+	"time"
 
 	sl "github.com/slack-go/slack"
 	"go.uber.org/mock/gomock"
@@ -70,10 +74,474 @@ var _ = Describe("GetMessage", func() {
 			},
 		}
 
-		mockClient.EXPECT().GetConversationHistory(gomock.Any()).Return(&response, nil)
+		mockClient.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(&response, nil)
 
-		message, err := connector.GetMessage("Some message")
+		message, err := connector.GetMessage(context.Background(), "Some message")
 		Expect(err).ToNot(HaveOccurred())
 		Expect(message.Text).To(Equal("Some message with the correct key"))
 	})
 })
+
+var _ = Describe("SendReply", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *mock_slack.MockClient
+		connector  *slack.Connector
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock_slack.NewMockClient(mockCtrl)
+		cfg := config.SlackConfiguration{
+			Token:     "AnyToken",
+			ChannelID: "AnyID",
+		}
+		connector = slack.NewSlackConnector(cfg, mockClient)
+	})
+
+	It("returns the timestamp of the reply", func() {
+		mockClient.EXPECT().GetConversationRepliesContext(gomock.Any(), &sl.GetConversationRepliesParameters{ChannelID: "AnyID", Timestamp: "1700000000.000100"}).Return(nil, false, "", nil)
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "AnyID", gomock.Any()).Return("AnyID", "1700000000.000200", nil)
+
+		timestamp := connector.SendReply(context.Background(), &sl.Message{Msg: sl.Msg{Timestamp: "1700000000.000100"}}, "reply")
+
+		Expect(timestamp).To(Equal("1700000000.000200"))
+	})
+
+	It("returns an empty timestamp if the reply failed", func() {
+		mockClient.EXPECT().GetConversationRepliesContext(gomock.Any(), gomock.Any()).Return(nil, false, "", nil)
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "AnyID", gomock.Any()).Return("", "", errors.New("channel_not_found"))
+
+		timestamp := connector.SendReply(context.Background(), &sl.Message{Msg: sl.Msg{Timestamp: "1700000000.000100"}}, "reply")
+
+		Expect(timestamp).To(BeEmpty())
+	})
+
+	It("does not post a reply the thread has already", func() {
+		mockClient.EXPECT().GetConversationRepliesContext(gomock.Any(), gomock.Any()).Return([]sl.Message{
+			{Msg: sl.Msg{Timestamp: "1700000000.000100", Text: "reply"}},
+			{Msg: sl.Msg{Timestamp: "1700000000.000200", Text: "reply"}},
+		}, false, "", nil)
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		timestamp := connector.SendReply(context.Background(), &sl.Message{Msg: sl.Msg{Timestamp: "1700000000.000100"}}, "reply")
+
+		Expect(timestamp).To(Equal("1700000000.000200"))
+	})
+
+	It("replies if the thread can't be read", func() {
+		mockClient.EXPECT().GetConversationRepliesContext(gomock.Any(), gomock.Any()).Return(nil, false, "", errors.New("ratelimited"))
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "AnyID", gomock.Any()).Return("AnyID", "1700000000.000200", nil)
+
+		timestamp := connector.SendReply(context.Background(), &sl.Message{Msg: sl.Msg{Timestamp: "1700000000.000100"}}, "reply")
+
+		Expect(timestamp).To(Equal("1700000000.000200"))
+	})
+})
+
+var _ = Describe("SendEphemeral", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *mock_slack.MockClient
+		connector  *slack.Connector
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock_slack.NewMockClient(mockCtrl)
+		cfg := config.SlackConfiguration{
+			Token:     "AnyToken",
+			ChannelID: "AnyID",
+		}
+		connector = slack.NewSlackConnector(cfg, mockClient)
+	})
+
+	It("posts to the channel for the user only", func() {
+		mockClient.EXPECT().PostEphemeralContext(gomock.Any(), "AnyID", "U123", gomock.Any()).Return("1700000000.000200", nil)
+
+		connector.SendEphemeral(context.Background(), "U123", "only for you")
+	})
+})
+
+var _ = Describe("GetMessageByTimestamp", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *mock_slack.MockClient
+		connector  *slack.Connector
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock_slack.NewMockClient(mockCtrl)
+		cfg := config.SlackConfiguration{
+			Token:     "AnyToken",
+			ChannelID: "AnyID",
+		}
+		connector = slack.NewSlackConnector(cfg, mockClient)
+	})
+
+	It("returns the message posted at the timestamp", func() {
+		mockClient.EXPECT().GetConversationHistoryContext(gomock.Any(), &sl.GetConversationHistoryParameters{
+			ChannelID: "AnyID",
+			Latest:    "1700000000.000100",
+			Oldest:    "1700000000.000100",
+			Inclusive: true,
+			Limit:     1,
+		}).Return(&sl.GetConversationHistoryResponse{Messages: []sl.Message{{Msg: sl.Msg{Text: "parent"}}}}, nil)
+
+		message, err := connector.GetMessageByTimestamp(context.Background(), "1700000000.000100")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(message.Text).To(Equal("parent"))
+	})
+
+	It("returns error if there is no such message", func() {
+		mockClient.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(&sl.GetConversationHistoryResponse{}, nil)
+
+		message, err := connector.GetMessageByTimestamp(context.Background(), "1700000000.000100")
+
+		Expect(err).To(HaveOccurred())
+		Expect(message).To(BeNil())
+	})
+})
+
+var _ = Describe("CheckAuth", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *mock_slack.MockClient
+		connector  *slack.Connector
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock_slack.NewMockClient(mockCtrl)
+		connector = slack.NewSlackConnector(config.SlackConfiguration{ChannelID: "AnyID"}, mockClient)
+	})
+
+	It("passes with a valid token", func() {
+		mockClient.EXPECT().AuthTestContext(gomock.Any()).Return(&sl.AuthTestResponse{UserID: "U123"}, nil)
+
+		Expect(connector.CheckAuth(context.Background())).To(Succeed())
+	})
+
+	It("fails with an invalid token", func() {
+		mockClient.EXPECT().AuthTestContext(gomock.Any()).Return(nil, errors.New("invalid_auth"))
+
+		Expect(connector.CheckAuth(context.Background())).To(MatchError("invalid_auth"))
+	})
+})
+
+var _ = Describe("DeadLetters", func() {
+	var (
+		mockCtrl        *gomock.Controller
+		mockClient      *mock_slack.MockClient
+		mockDeadLetters *mock_slack.MockDeadLetters
+		connector       *slack.Connector
+	)
+
+	message := &sl.Message{Msg: sl.Msg{Timestamp: "1.0", Text: "octocat opened Add dead letters:\nhttps://github.com/loveholidays/frontier/pull/42"}}
+	blocks := []sl.Block{sl.NewSectionBlock(sl.NewTextBlockObject(sl.MarkdownType, "*Add dead letters*", false, false), nil, nil)}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock_slack.NewMockClient(mockCtrl)
+		mockDeadLetters = mock_slack.NewMockDeadLetters(mockCtrl)
+		connector = slack.NewSlackConnectorWithDeadLetters(config.SlackConfiguration{ChannelID: "C123"}, mockClient, mockDeadLetters)
+	})
+
+	It("keeps messages that failed, with their blocks and pull request", func() {
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("", "", errors.New("service_unavailable"))
+		var entry deadletter.Entry
+		mockDeadLetters.EXPECT().Add(gomock.Any()).Do(func(added deadletter.Entry) {
+			entry = added
+		})
+
+		connector.SendMessageWithBlocks(context.Background(), message.Text, blocks)
+
+		Expect(entry.Operation).To(Equal(deadletter.OperationMessage))
+		Expect(entry.Channel).To(Equal("C123"))
+		Expect(entry.PRURL).To(Equal("https://github.com/loveholidays/frontier/pull/42"))
+		Expect(entry.Error).To(Equal("service_unavailable"))
+		Expect(entry.Payload.Text).To(Equal(message.Text))
+		Expect(string(entry.Payload.Blocks)).To(ContainSubstring("*Add dead letters*"))
+
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).DoAndReturn(func(_ context.Context, channelID string, options ...sl.MsgOption) (string, string, error) {
+			_, values, err := sl.UnsafeApplyMsgOptions("", channelID, "", options...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(values.Get("text")).To(Equal(message.Text))
+			Expect(values.Get("blocks")).To(ContainSubstring("*Add dead letters*"))
+			return "C123", "2.0", nil
+		})
+		Expect(connector.Deliver(context.Background(), entry)).To(Succeed())
+	})
+
+	It("keeps replies that failed and delivers them to the thread", func() {
+		mockClient.EXPECT().GetConversationRepliesContext(gomock.Any(), &sl.GetConversationRepliesParameters{ChannelID: "C123", Timestamp: "1.0"}).Return(nil, false, "", nil).Times(2)
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("", "", errors.New("service_unavailable"))
+		var entry deadletter.Entry
+		mockDeadLetters.EXPECT().Add(gomock.Any()).Do(func(added deadletter.Entry) {
+			entry = added
+		})
+
+		connector.SendReply(context.Background(), message, "approved")
+
+		Expect(entry.Operation).To(Equal(deadletter.OperationReply))
+		Expect(entry.PRURL).To(Equal("https://github.com/loveholidays/frontier/pull/42"))
+
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).DoAndReturn(func(_ context.Context, channelID string, options ...sl.MsgOption) (string, string, error) {
+			_, values, err := sl.UnsafeApplyMsgOptions("", channelID, "", options...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(values.Get("thread_ts")).To(Equal("1.0"))
+			Expect(values.Get("text")).To(Equal("approved"))
+			return "C123", "2.0", nil
+		})
+		Expect(connector.Deliver(context.Background(), entry)).To(Succeed())
+	})
+
+	It("does not deliver a failed reply that was posted after all", func() {
+		mockClient.EXPECT().GetConversationRepliesContext(gomock.Any(), gomock.Any()).Return(nil, false, "", nil)
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("", "", errors.New("context deadline exceeded"))
+		var entry deadletter.Entry
+		mockDeadLetters.EXPECT().Add(gomock.Any()).Do(func(added deadletter.Entry) {
+			entry = added
+		})
+		connector.SendReply(context.Background(), message, "approved")
+
+		mockClient.EXPECT().GetConversationRepliesContext(gomock.Any(), &sl.GetConversationRepliesParameters{ChannelID: "C123", Timestamp: "1.0"}).Return([]sl.Message{
+			{Msg: sl.Msg{Timestamp: "1.0", Text: message.Text}},
+			{Msg: sl.Msg{Timestamp: "2.0", Text: "approved"}},
+		}, false, "", nil)
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		Expect(connector.Deliver(context.Background(), entry)).To(Succeed())
+	})
+
+	It("keeps a failed reply if the thread can't be read", func() {
+		mockClient.EXPECT().GetConversationRepliesContext(gomock.Any(), gomock.Any()).Return(nil, false, "", errors.New("ratelimited"))
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		Expect(connector.Deliver(context.Background(), deadletter.Entry{
+			Operation: deadletter.OperationReply,
+			Channel:   "C123",
+			Payload:   deadletter.Payload{Text: "approved", Timestamp: "1.0"},
+		})).To(MatchError(ContainSubstring("ratelimited")))
+	})
+
+	It("keeps reactions that failed", func() {
+		mockClient.EXPECT().AddReactionContext(gomock.Any(), "merged", sl.ItemRef{Channel: "C123", Timestamp: "1.0"}).Return(errors.New("service_unavailable"))
+		mockDeadLetters.EXPECT().Add(deadletter.Entry{
+			Operation: deadletter.OperationAddReaction,
+			Channel:   "C123",
+			Payload:   deadletter.Payload{Timestamp: "1.0", Reaction: "merged"},
+			PRURL:     "https://github.com/loveholidays/frontier/pull/42",
+			Error:     "service_unavailable",
+		})
+
+		connector.AddReactionToMessage(context.Background(), "merged", message)
+	})
+
+	It("does not keep reactions the message already has", func() {
+		mockClient.EXPECT().AddReactionContext(gomock.Any(), "merged", gomock.Any()).Return(sl.SlackErrorResponse{Err: "already_reacted"})
+		mockClient.EXPECT().RemoveReactionContext(gomock.Any(), "x", gomock.Any()).Return(sl.SlackErrorResponse{Err: "no_reaction"})
+
+		connector.AddReactionToMessage(context.Background(), "merged", message)
+		connector.RemoveReactionFromMessage(context.Background(), "x", message)
+	})
+
+	It("delivers reactions the message got in the meantime", func() {
+		mockClient.EXPECT().RemoveReactionContext(gomock.Any(), "x", sl.ItemRef{Channel: "C123", Timestamp: "1.0"}).Return(sl.SlackErrorResponse{Err: "no_reaction"})
+
+		Expect(connector.Deliver(context.Background(), deadletter.Entry{
+			Operation: deadletter.OperationRemoveReaction,
+			Channel:   "C123",
+			Payload:   deadletter.Payload{Timestamp: "1.0", Reaction: "x"},
+		})).To(Succeed())
+	})
+
+	It("returns the error of failed deliveries without keeping them again", func() {
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "U123", gomock.Any()).Return("", "", errors.New("service_unavailable"))
+
+		Expect(connector.Deliver(context.Background(), deadletter.Entry{
+			Operation: deadletter.OperationDirectMessage,
+			Channel:   "U123",
+			Payload:   deadletter.Payload{Text: "Your review was requested"},
+		})).To(MatchError("service_unavailable"))
+	})
+})
+
+var _ = Describe("Announce", func() {
+	const url = "https://github.com/loveholidays/frontier/pull/42"
+
+	var (
+		mockCtrl        *gomock.Controller
+		mockClient      *mock_slack.MockClient
+		mockDeadLetters *mock_slack.MockDeadLetters
+		connector       *slack.Connector
+	)
+
+	message := "octocat opened Add dead letters:\n" + url
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock_slack.NewMockClient(mockCtrl)
+		mockDeadLetters = mock_slack.NewMockDeadLetters(mockCtrl)
+		connector = slack.NewSlackConnectorWithDeadLetters(config.SlackConfiguration{ChannelID: "C123"}, mockClient, mockDeadLetters)
+	})
+
+	It("announces a pull request once within the announcement window", func() {
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("C123", "1.0", nil)
+
+		connector.Announce(context.Background(), url, message, nil)
+		connector.Announce(context.Background(), url, message, nil)
+	})
+
+	It("announces a pull request again after the announcement window", func() {
+		connector = slack.NewSlackConnectorWithDeadLetters(config.SlackConfiguration{ChannelID: "C123", AnnouncementWindow: time.Nanosecond}, mockClient, mockDeadLetters)
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("C123", "1.0", nil).Times(2)
+
+		connector.Announce(context.Background(), url, message, nil)
+		time.Sleep(time.Millisecond)
+		connector.Announce(context.Background(), url, message, nil)
+	})
+
+	It("announces a pull request again after a failed announcement", func() {
+		gomock.InOrder(
+			mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("", "", errors.New("service_unavailable")),
+			mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("C123", "1.0", nil),
+		)
+		mockDeadLetters.EXPECT().Add(gomock.Any())
+
+		connector.Announce(context.Background(), url, message, nil)
+		connector.Announce(context.Background(), url, message, nil)
+	})
+
+	It("delivers a failed announcement unless the pull request was announced in the meantime", func() {
+		gomock.InOrder(
+			mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("", "", errors.New("service_unavailable")),
+			mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("C123", "1.0", nil),
+		)
+		var entry deadletter.Entry
+		mockDeadLetters.EXPECT().Add(gomock.Any()).Do(func(added deadletter.Entry) {
+			entry = added
+		})
+
+		connector.Announce(context.Background(), url, message, nil)
+		connector.Announce(context.Background(), url, message, nil)
+
+		Expect(entry.Operation).To(Equal(deadletter.OperationAnnouncement))
+		Expect(entry.PRURL).To(Equal(url))
+		Expect(connector.Deliver(context.Background(), entry)).To(Succeed())
+	})
+
+	It("holds the announcement window once a failed announcement is delivered", func() {
+		gomock.InOrder(
+			mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("", "", errors.New("service_unavailable")),
+			mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("C123", "1.0", nil),
+		)
+		var entry deadletter.Entry
+		mockDeadLetters.EXPECT().Add(gomock.Any()).Do(func(added deadletter.Entry) {
+			entry = added
+		})
+
+		mockClient.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(&sl.GetConversationHistoryResponse{}, nil)
+
+		connector.Announce(context.Background(), url, message, nil)
+		Expect(connector.Deliver(context.Background(), entry)).To(Succeed())
+		connector.Announce(context.Background(), url, message, nil)
+	})
+
+	It("does not deliver a failed announcement that was posted after all", func() {
+		mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("", "", errors.New("context deadline exceeded"))
+		var entry deadletter.Entry
+		mockDeadLetters.EXPECT().Add(gomock.Any()).Do(func(added deadletter.Entry) {
+			entry = added
+		})
+		mockClient.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(&sl.GetConversationHistoryResponse{
+			Messages: []sl.Message{{Msg: sl.Msg{Text: "octocat opened Add dead letters:\n<" + url + ">"}}},
+		}, nil)
+
+		connector.Announce(context.Background(), url, message, nil)
+		Expect(connector.Deliver(context.Background(), entry)).To(Succeed())
+		connector.Announce(context.Background(), url, message, nil)
+	})
+
+	It("keeps a failed announcement if the channel can't be looked up", func() {
+		gomock.InOrder(
+			mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("", "", errors.New("context deadline exceeded")),
+			mockClient.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("C123", "1.0", nil),
+		)
+		var entry deadletter.Entry
+		mockDeadLetters.EXPECT().Add(gomock.Any()).Do(func(added deadletter.Entry) {
+			entry = added
+		})
+		mockClient.EXPECT().GetConversationHistoryContext(gomock.Any(), gomock.Any()).Return(nil, errors.New("ratelimited"))
+
+		connector.Announce(context.Background(), url, message, nil)
+		Expect(connector.Deliver(context.Background(), entry)).To(MatchError(ContainSubstring("ratelimited")))
+		connector.Announce(context.Background(), url, message, nil)
+	})
+})
+
+var _ = Describe("Reactions", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *mock_slack.MockClient
+		connector  *slack.Connector
+	)
+
+	message := func(reactions ...sl.ItemReaction) *sl.Message {
+		return &sl.Message{Msg: sl.Msg{Timestamp: "1.0", Reactions: reactions}}
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock_slack.NewMockClient(mockCtrl)
+		connector = slack.NewSlackConnector(config.SlackConfiguration{ChannelID: "C123"}, mockClient)
+	})
+
+	It("does not add a reaction the bot already added", func() {
+		mockClient.EXPECT().AuthTestContext(gomock.Any()).Return(&sl.AuthTestResponse{UserID: "UBOT"}, nil)
+
+		connector.AddReactionToMessage(context.Background(), "+1", message(sl.ItemReaction{Name: "+1", Count: 2, Users: []string{"U123", "UBOT"}}))
+		connector.AddReactionToMessage(context.Background(), "+1", message(sl.ItemReaction{Name: "+1", Count: 2, Users: []string{"UBOT", "U123"}}))
+	})
+
+	It("adds a reaction someone else added", func() {
+		mockClient.EXPECT().AuthTestContext(gomock.Any()).Return(&sl.AuthTestResponse{UserID: "UBOT"}, nil)
+		mockClient.EXPECT().AddReactionContext(gomock.Any(), "+1", sl.ItemRef{Channel: "C123", Timestamp: "1.0"}).Return(nil)
+
+		connector.AddReactionToMessage(context.Background(), "+1", message(sl.ItemReaction{Name: "+1", Count: 1, Users: []string{"U123"}}))
+	})
+
+	It("treats already_reacted as success", func() {
+		mockClient.EXPECT().AddReactionContext(gomock.Any(), "+1", gomock.Any()).Return(sl.SlackErrorResponse{Err: "already_reacted"})
+
+		connector.AddReactionToMessage(context.Background(), "+1", message())
+	})
+
+	It("does not remove a reaction the message doesn't have", func() {
+		mockClient.EXPECT().AuthTestContext(gomock.Any()).Return(&sl.AuthTestResponse{UserID: "UBOT"}, nil)
+
+		connector.RemoveReactionFromMessage(context.Background(), "x", message(sl.ItemReaction{Name: "merged", Count: 1, Users: []string{"UBOT"}}))
+		connector.RemoveReactionFromMessage(context.Background(), "x", message(sl.ItemReaction{Name: "x", Count: 1, Users: []string{"U123"}}))
+	})
+
+	It("removes a reaction whose users are not all listed", func() {
+		mockClient.EXPECT().RemoveReactionContext(gomock.Any(), "x", sl.ItemRef{Channel: "C123", Timestamp: "1.0"}).Return(nil)
+
+		connector.RemoveReactionFromMessage(context.Background(), "x", message(sl.ItemReaction{Name: "x", Count: 60, Users: []string{"U123"}}))
+	})
+
+	It("treats no_reaction as success", func() {
+		mockClient.EXPECT().RemoveReactionContext(gomock.Any(), "x", gomock.Any()).Return(sl.SlackErrorResponse{Err: "no_reaction"})
+
+		connector.RemoveReactionFromMessage(context.Background(), "x", message())
+	})
+
+	It("reacts without checking if the bot user can't be looked up", func() {
+		mockClient.EXPECT().AuthTestContext(gomock.Any()).Return(nil, errors.New("invalid_auth"))
+		mockClient.EXPECT().AddReactionContext(gomock.Any(), "+1", gomock.Any()).Return(nil)
+
+		connector.AddReactionToMessage(context.Background(), "+1", message(sl.ItemReaction{Name: "+1", Count: 1, Users: []string{"UBOT"}}))
+	})
+})
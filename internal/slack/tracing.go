@@ -0,0 +1,106 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package slack
+
+import (
+	"context"
+	"git-slack-bot/internal/tracing"
+
+	"github.com/slack-go/slack"
+)
+
+// TracingClient is a Client that records a span for every slack api call.
+type TracingClient struct {
+	client Client
+}
+
+func NewTracingClient(client Client) *TracingClient {
+	return &TracingClient{client: client}
+}
+
+func (c *TracingClient) GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+	ctx, span := tracing.Start(ctx, "slack conversations.history", tracing.SlackMethod.String("conversations.history"), tracing.SlackChannel.String(params.ChannelID))
+	history, err := c.client.GetConversationHistoryContext(ctx, params)
+	tracing.End(span, err)
+	return history, err
+}
+
+func (c *TracingClient) GetConversationRepliesContext(ctx context.Context, params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error) {
+	ctx, span := tracing.Start(ctx, "slack conversations.replies", tracing.SlackMethod.String("conversations.replies"), tracing.SlackChannel.String(params.ChannelID))
+	replies, hasMore, nextCursor, err := c.client.GetConversationRepliesContext(ctx, params)
+	tracing.End(span, err)
+	return replies, hasMore, nextCursor, err
+}
+
+func (c *TracingClient) PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	ctx, span := tracing.Start(ctx, "slack chat.postMessage", tracing.SlackMethod.String("chat.postMessage"), tracing.SlackChannel.String(channelID))
+	channel, timestamp, err := c.client.PostMessageContext(ctx, channelID, options...)
+	tracing.End(span, err)
+	return channel, timestamp, err
+}
+
+func (c *TracingClient) PostEphemeralContext(ctx context.Context, channelID, userID string, options ...slack.MsgOption) (string, error) {
+	ctx, span := tracing.Start(ctx, "slack chat.postEphemeral", tracing.SlackMethod.String("chat.postEphemeral"), tracing.SlackChannel.String(channelID), tracing.SlackUser.String(userID))
+	timestamp, err := c.client.PostEphemeralContext(ctx, channelID, userID, options...)
+	tracing.End(span, err)
+	return timestamp, err
+}
+
+func (c *TracingClient) AddReactionContext(ctx context.Context, name string, item slack.ItemRef) error {
+	ctx, span := tracing.Start(ctx, "slack reactions.add", tracing.SlackMethod.String("reactions.add"), tracing.SlackChannel.String(item.Channel))
+	err := c.client.AddReactionContext(ctx, name, item)
+	tracing.End(span, err)
+	return err
+}
+
+func (c *TracingClient) RemoveReactionContext(ctx context.Context, name string, item slack.ItemRef) error {
+	ctx, span := tracing.Start(ctx, "slack reactions.remove", tracing.SlackMethod.String("reactions.remove"), tracing.SlackChannel.String(item.Channel))
+	err := c.client.RemoveReactionContext(ctx, name, item)
+	tracing.End(span, err)
+	return err
+}
+
+func (c *TracingClient) GetUserByEmailContext(ctx context.Context, email string) (*slack.User, error) {
+	ctx, span := tracing.Start(ctx, "slack users.lookupByEmail", tracing.SlackMethod.String("users.lookupByEmail"))
+	user, err := c.client.GetUserByEmailContext(ctx, email)
+	tracing.End(span, err)
+	return user, err
+}
+
+func (c *TracingClient) GetUserInfoContext(ctx context.Context, user string) (*slack.User, error) {
+	ctx, span := tracing.Start(ctx, "slack users.info", tracing.SlackMethod.String("users.info"), tracing.SlackUser.String(user))
+	slackUser, err := c.client.GetUserInfoContext(ctx, user)
+	tracing.End(span, err)
+	return slackUser, err
+}
+
+func (c *TracingClient) DeleteMessageContext(ctx context.Context, channel, messageTimestamp string) (string, string, error) {
+	ctx, span := tracing.Start(ctx, "slack chat.delete", tracing.SlackMethod.String("chat.delete"), tracing.SlackChannel.String(channel))
+	deletedChannel, deletedTimestamp, err := c.client.DeleteMessageContext(ctx, channel, messageTimestamp)
+	tracing.End(span, err)
+	return deletedChannel, deletedTimestamp, err
+}
+
+func (c *TracingClient) AuthTestContext(ctx context.Context) (*slack.AuthTestResponse, error) {
+	ctx, span := tracing.Start(ctx, "slack auth.test", tracing.SlackMethod.String("auth.test"))
+	response, err := c.client.AuthTestContext(ctx)
+	tracing.End(span, err)
+	return response, err
+}
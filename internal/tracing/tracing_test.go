@@ -0,0 +1,86 @@
+/*
+git-slack-bot
+Copyright (C) 2025 loveholidays
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU Lesser General Public
+License as published by the Free Software Foundation; either
+version 3 of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program; if not, write to the Free Software Foundation,
+Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
+*/
+
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/tracing"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTracing(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tracing tests")
+}
+
+var _ = Describe("Setup", func() {
+	It("does nothing when tracing is disabled", func() {
+		shutdown, err := tracing.Setup(context.Background(), config.TracingConfiguration{})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shutdown(context.Background())).To(Succeed())
+	})
+})
+
+var _ = Describe("Start and End", func() {
+	var recorder *tracetest.SpanRecorder
+
+	BeforeEach(func() {
+		previous := otel.GetTracerProvider()
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+		DeferCleanup(func() { otel.SetTracerProvider(previous) })
+	})
+
+	It("nests spans started from the context of another", func() {
+		ctx, parent := tracing.Start(context.Background(), "parent", tracing.Event.String("push"))
+		_, child := tracing.Start(ctx, "child")
+		tracing.End(child, nil)
+		tracing.End(parent, nil)
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[0].Name()).To(Equal("child"))
+		Expect(spans[0].Parent().SpanID()).To(Equal(spans[1].SpanContext().SpanID()))
+		Expect(spans[1].Attributes()).To(ContainElement(tracing.Event.String("push")))
+		Expect(spans[1].Status().Code).To(Equal(codes.Unset))
+	})
+
+	It("marks the span as failed on error", func() {
+		_, span := tracing.Start(context.Background(), "failing")
+		tracing.End(span, errors.New("channel_not_found"))
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Status().Code).To(Equal(codes.Error))
+		Expect(spans[0].Status().Description).To(Equal("channel_not_found"))
+		Expect(spans[0].Events()).To(HaveLen(1))
+	})
+})
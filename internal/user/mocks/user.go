@@ -10,8 +10,10 @@
 package mock_user
 
 import (
+	context "context"
 	reflect "reflect"
 
+	slack "github.com/slack-go/slack"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -39,18 +41,76 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// GetGithubLogin mocks base method.
+func (m *MockService) GetGithubLogin(ctx context.Context, slackUserID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGithubLogin", ctx, slackUserID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGithubLogin indicates an expected call of GetGithubLogin.
+func (mr *MockServiceMockRecorder) GetGithubLogin(ctx, slackUserID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGithubLogin", reflect.TypeOf((*MockService)(nil).GetGithubLogin), ctx, slackUserID)
+}
+
+// GetReviewQueueSubscribers mocks base method.
+func (m *MockService) GetReviewQueueSubscribers() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReviewQueueSubscribers")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetReviewQueueSubscribers indicates an expected call of GetReviewQueueSubscribers.
+func (mr *MockServiceMockRecorder) GetReviewQueueSubscribers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReviewQueueSubscribers", reflect.TypeOf((*MockService)(nil).GetReviewQueueSubscribers))
+}
+
+// GetSlackUser mocks base method.
+func (m *MockService) GetSlackUser(ctx context.Context, githubLogin string) (*slack.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSlackUser", ctx, githubLogin)
+	ret0, _ := ret[0].(*slack.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSlackUser indicates an expected call of GetSlackUser.
+func (mr *MockServiceMockRecorder) GetSlackUser(ctx, githubLogin any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSlackUser", reflect.TypeOf((*MockService)(nil).GetSlackUser), ctx, githubLogin)
+}
+
+// GetTeamMembers mocks base method.
+func (m *MockService) GetTeamMembers() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTeamMembers")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetTeamMembers indicates an expected call of GetTeamMembers.
+func (mr *MockServiceMockRecorder) GetTeamMembers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamMembers", reflect.TypeOf((*MockService)(nil).GetTeamMembers))
+}
+
 // GetUserDescriptor mocks base method.
-func (m *MockService) GetUserDescriptor(githubLogin string) string {
+func (m *MockService) GetUserDescriptor(ctx context.Context, githubLogin string) string {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserDescriptor", githubLogin)
+	ret := m.ctrl.Call(m, "GetUserDescriptor", ctx, githubLogin)
 	ret0, _ := ret[0].(string)
 	return ret0
 }
 
 // GetUserDescriptor indicates an expected call of GetUserDescriptor.
-func (mr *MockServiceMockRecorder) GetUserDescriptor(githubLogin any) *gomock.Call {
+func (mr *MockServiceMockRecorder) GetUserDescriptor(ctx, githubLogin any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserDescriptor", reflect.TypeOf((*MockService)(nil).GetUserDescriptor), githubLogin)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserDescriptor", reflect.TypeOf((*MockService)(nil).GetUserDescriptor), ctx, githubLogin)
 }
 
 // IsIgnoredCommentUser mocks base method.
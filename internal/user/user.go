@@ -20,40 +20,73 @@ Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
 package user
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"git-slack-bot/internal/config"
+	"git-slack-bot/internal/metrics"
 	"git-slack-bot/internal/slack"
+	"git-slack-bot/internal/tracing"
 	"log/slog"
+	"strings"
+	"sync/atomic"
+
+	sl "github.com/slack-go/slack"
 )
 
 type Service interface {
 	IsTeamMember(githubLogin string) bool
-	GetUserDescriptor(githubLogin string) string
+	GetTeamMembers() []string
+	GetUserDescriptor(ctx context.Context, githubLogin string) string
 	IsIgnoredCommentUser(githubLogin string) bool
 	IsIgnoredReviewUser(githubLogin string) bool
+	GetSlackUser(ctx context.Context, githubLogin string) (*sl.User, error)
+	GetGithubLogin(ctx context.Context, slackUserID string) (string, error)
+	GetReviewQueueSubscribers() []string
 }
 
-type ServiceImpl struct {
-	slackConnector      slack.Interactor
+// users are the users the service knows about, which are swapped as a whole when the configuration is reloaded.
+type users struct {
 	githubToSlackEmails []config.GithubEmailToSlackEmail
 	githubTeamMembers   []string
 	ignoredCommentUsers []string
 	ignoredReviewUsers  []string
 }
 
-func NewService(slackConnector slack.Interactor, githubTeamMembers []string, githubToSlackEmails []config.GithubEmailToSlackEmail, ignoredCommentUsers, ignoredReviewUsers []string) Service {
-	return &ServiceImpl{
-		slackConnector:      slackConnector,
+type ServiceImpl struct {
+	slackConnector slack.Interactor
+	users          atomic.Pointer[users]
+}
+
+func NewService(slackConnector slack.Interactor, githubTeamMembers []string, githubToSlackEmails []config.GithubEmailToSlackEmail, ignoredCommentUsers, ignoredReviewUsers []string) *ServiceImpl {
+	s := &ServiceImpl{
+		slackConnector: slackConnector,
+	}
+	s.users.Store(&users{
 		githubToSlackEmails: githubToSlackEmails,
 		githubTeamMembers:   githubTeamMembers,
 		ignoredCommentUsers: ignoredCommentUsers,
 		ignoredReviewUsers:  ignoredReviewUsers,
+	})
+	return s
+}
+
+// Reload replaces the users the service knows about. Calls in progress finish with the previous users. Without
+// githubTeamMembers, such as when they could not be fetched, the previous team members are kept.
+func (s *ServiceImpl) Reload(githubTeamMembers []string, githubToSlackEmails []config.GithubEmailToSlackEmail, ignoredCommentUsers, ignoredReviewUsers []string) {
+	if len(githubTeamMembers) == 0 {
+		githubTeamMembers = s.users.Load().githubTeamMembers
 	}
+	s.users.Store(&users{
+		githubToSlackEmails: githubToSlackEmails,
+		githubTeamMembers:   githubTeamMembers,
+		ignoredCommentUsers: ignoredCommentUsers,
+		ignoredReviewUsers:  ignoredReviewUsers,
+	})
 }
 
 func (s *ServiceImpl) IsTeamMember(githubLogin string) bool {
-	for _, teamMember := range s.githubTeamMembers {
+	for _, teamMember := range s.users.Load().githubTeamMembers {
 		if githubLogin == teamMember {
 			return true
 		}
@@ -61,31 +94,94 @@ func (s *ServiceImpl) IsTeamMember(githubLogin string) bool {
 	return false
 }
 
-func (s *ServiceImpl) GetUserDescriptor(githubLogin string) string {
-	slackUserID, err := s.getSlackUserID(githubLogin)
+func (s *ServiceImpl) GetTeamMembers() []string {
+	return s.users.Load().githubTeamMembers
+}
+
+func (s *ServiceImpl) GetUserDescriptor(ctx context.Context, githubLogin string) string {
+	ctx, span := tracing.Start(ctx, "user.GetUserDescriptor", tracing.GithubLogin.String(githubLogin))
+	slackUserID, err := s.getSlackUserID(ctx, githubLogin)
+	tracing.End(span, err)
 	if err != nil {
-		slog.Warn("Unable to find slack ID for user", slog.Any("user", githubLogin), slog.Any("error", err))
+		slog.WarnContext(ctx, "Unable to find slack ID for user", slog.Any("user", githubLogin), slog.Any("error", err))
+		metrics.UserResolutionFailures.WithLabelValues(metrics.ResolveSlackUser).Inc()
 		return githubLogin
 	}
 	return fmt.Sprintf("<@%s>", slackUserID)
 }
 
-func (s *ServiceImpl) getSlackUserID(githubLogin string) (string, error) {
-	for _, githubToSlackEmail := range s.githubToSlackEmails {
+func (s *ServiceImpl) getSlackUserID(ctx context.Context, githubLogin string) (string, error) {
+	for _, githubToSlackEmail := range s.users.Load().githubToSlackEmails {
 		if githubToSlackEmail.GithubEmail == githubLogin {
-			email, err := s.slackConnector.GetUserIDByEmail(githubToSlackEmail.SlackEmail)
+			email, err := s.slackConnector.GetUserIDByEmail(ctx, githubToSlackEmail.SlackEmail)
 			if err != nil {
-				slog.Error("Received error from slack", slog.Any("error", err))
+				slog.ErrorContext(ctx, "Received error from slack", slog.Any("error", err))
 			}
 			return email, err
 		}
 	}
-	slog.Warn("could not find slack email for github login", slog.Any("user", githubLogin))
+	slog.WarnContext(ctx, "could not find slack email for github login", slog.Any("user", githubLogin))
 	return "", errors.New("could not find slack email for github login")
 }
 
+// GetSlackUser returns the slack user mapped to the github login.
+func (s *ServiceImpl) GetSlackUser(ctx context.Context, githubLogin string) (*sl.User, error) {
+	ctx, span := tracing.Start(ctx, "user.GetSlackUser", tracing.GithubLogin.String(githubLogin))
+	slackUser, err := s.getSlackUser(ctx, githubLogin)
+	tracing.End(span, err)
+	return slackUser, err
+}
+
+func (s *ServiceImpl) getSlackUser(ctx context.Context, githubLogin string) (*sl.User, error) {
+	for _, githubToSlackEmail := range s.users.Load().githubToSlackEmails {
+		if githubToSlackEmail.GithubEmail == githubLogin {
+			slackUser, err := s.slackConnector.GetUserByEmail(ctx, githubToSlackEmail.SlackEmail)
+			if err != nil {
+				metrics.UserResolutionFailures.WithLabelValues(metrics.ResolveSlackUser).Inc()
+			}
+			return slackUser, err
+		}
+	}
+	metrics.UserResolutionFailures.WithLabelValues(metrics.ResolveSlackUser).Inc()
+	return nil, errors.New("could not find slack email for github login")
+}
+
+// GetGithubLogin returns the github login mapped to the slack user, matching on the slack user's email.
+func (s *ServiceImpl) GetGithubLogin(ctx context.Context, slackUserID string) (string, error) {
+	ctx, span := tracing.Start(ctx, "user.GetGithubLogin", tracing.SlackUser.String(slackUserID))
+	githubLogin, err := s.getGithubLogin(ctx, slackUserID)
+	tracing.End(span, err)
+	return githubLogin, err
+}
+
+func (s *ServiceImpl) getGithubLogin(ctx context.Context, slackUserID string) (string, error) {
+	slackUser, err := s.slackConnector.GetUserByID(ctx, slackUserID)
+	if err != nil {
+		metrics.UserResolutionFailures.WithLabelValues(metrics.ResolveGithubLogin).Inc()
+		return "", err
+	}
+	for _, githubToSlackEmail := range s.users.Load().githubToSlackEmails {
+		if strings.EqualFold(githubToSlackEmail.SlackEmail, slackUser.Profile.Email) {
+			return githubToSlackEmail.GithubEmail, nil
+		}
+	}
+	metrics.UserResolutionFailures.WithLabelValues(metrics.ResolveGithubLogin).Inc()
+	return "", errors.New("could not find github login for slack user")
+}
+
+// GetReviewQueueSubscribers returns the github logins of the mapped users who want their review queue sent to them.
+func (s *ServiceImpl) GetReviewQueueSubscribers() []string {
+	var subscribers []string
+	for _, githubToSlackEmail := range s.users.Load().githubToSlackEmails {
+		if githubToSlackEmail.ReviewQueue {
+			subscribers = append(subscribers, githubToSlackEmail.GithubEmail)
+		}
+	}
+	return subscribers
+}
+
 func (s *ServiceImpl) IsIgnoredCommentUser(githubLogin string) bool {
-	for _, user := range s.ignoredCommentUsers {
+	for _, user := range s.users.Load().ignoredCommentUsers {
 		if user == githubLogin {
 			return true
 		}
@@ -94,7 +190,7 @@ func (s *ServiceImpl) IsIgnoredCommentUser(githubLogin string) bool {
 }
 
 func (s *ServiceImpl) IsIgnoredReviewUser(githubLogin string) bool {
-	for _, user := range s.ignoredReviewUsers {
+	for _, user := range s.users.Load().ignoredReviewUsers {
 		if user == githubLogin {
 			return true
 		}
@@ -20,6 +20,7 @@ Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301, USA.
 package user_test
 
 import (
+	"context"
 	"errors"
 	"git-slack-bot/internal/config"
 	mock_slack "git-slack-bot/internal/slack/mocks"
@@ -28,6 +29,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
 	"go.uber.org/mock/gomock"
 )
 
@@ -61,6 +63,33 @@ var _ = Describe("HandleUser", func() {
 		})
 	})
 
+	Context("GetTeamMembers", func() {
+		It("should return the git team members", func() {
+			service := user.NewService(nil, []string{"userLogin", "otherUserLogin"}, nil, nil, nil)
+
+			Expect(service.GetTeamMembers()).To(Equal([]string{"userLogin", "otherUserLogin"}))
+		})
+	})
+
+	Context("Reload", func() {
+		It("should replace the users", func() {
+			service := user.NewService(nil, []string{"userLogin"}, nil, nil, nil)
+
+			service.Reload([]string{"otherUserLogin"}, nil, []string{"userLogin"}, nil)
+
+			Expect(service.GetTeamMembers()).To(Equal([]string{"otherUserLogin"}))
+			Expect(service.IsIgnoredCommentUser("userLogin")).To(BeTrue())
+		})
+
+		It("should keep the team members if none were fetched", func() {
+			service := user.NewService(nil, []string{"userLogin"}, nil, nil, nil)
+
+			service.Reload(nil, nil, nil, nil)
+
+			Expect(service.GetTeamMembers()).To(Equal([]string{"userLogin"}))
+		})
+	})
+
 	Context("IsIgnoredCommentUser", func() {
 		It("should return true if user comment should be ignored", func() {
 			service := user.NewService(nil, []string{"userLogin"}, nil, []string{"userLogin"}, nil)
@@ -99,9 +128,9 @@ var _ = Describe("HandleUser", func() {
 			}
 			service := user.NewService(slackMock, []string{"userLogin"}, emails, nil, nil)
 
-			slackMock.EXPECT().GetUserIDByEmail("user@user.com").Return("123", nil)
+			slackMock.EXPECT().GetUserIDByEmail(gomock.Any(), "user@user.com").Return("123", nil)
 
-			actual := service.GetUserDescriptor("userLogin")
+			actual := service.GetUserDescriptor(context.Background(), "userLogin")
 
 			Expect(actual).To(Equal("<@123>"))
 		})
@@ -110,7 +139,7 @@ var _ = Describe("HandleUser", func() {
 
 			service := user.NewService(slackMock, []string{"userLogin"}, []config.GithubEmailToSlackEmail{}, nil, nil)
 
-			actual := service.GetUserDescriptor("userLogin")
+			actual := service.GetUserDescriptor(context.Background(), "userLogin")
 
 			Expect(actual).To(Equal("userLogin"))
 		})
@@ -124,11 +153,98 @@ var _ = Describe("HandleUser", func() {
 			}
 			service := user.NewService(slackMock, []string{"userLogin"}, emails, nil, nil)
 
-			slackMock.EXPECT().GetUserIDByEmail("user@user.com").Return("", errors.New("not found"))
+			slackMock.EXPECT().GetUserIDByEmail(gomock.Any(), "user@user.com").Return("", errors.New("not found"))
+
+			actual := service.GetUserDescriptor(context.Background(), "userLogin")
+
+			Expect(actual).To(Equal("userLogin"))
+		})
+	})
+
+	Context("GetSlackUser", func() {
+		It("should return the mapped slack user", func() {
+			emails := []config.GithubEmailToSlackEmail{
+				{
+					GithubEmail: "userLogin",
+					SlackEmail:  "user@user.com",
+				},
+			}
+			service := user.NewService(slackMock, []string{"userLogin"}, emails, nil, nil)
+
+			slackMock.EXPECT().GetUserByEmail(gomock.Any(), "user@user.com").Return(&slack.User{ID: "123", TZ: "Europe/London"}, nil)
+
+			actual, err := service.GetSlackUser(context.Background(), "userLogin")
 
-			actual := service.GetUserDescriptor("userLogin")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual.TZ).To(Equal("Europe/London"))
+		})
+
+		It("should return error if there is no mapping between github and slack emails", func() {
+			service := user.NewService(slackMock, []string{"userLogin"}, []config.GithubEmailToSlackEmail{}, nil, nil)
+
+			actual, err := service.GetSlackUser(context.Background(), "userLogin")
+
+			Expect(err).To(HaveOccurred())
+			Expect(actual).To(BeNil())
+		})
+	})
+
+	Context("GetReviewQueueSubscribers", func() {
+		It("should return the users who opted in", func() {
+			emails := []config.GithubEmailToSlackEmail{
+				{
+					GithubEmail: "subscribed",
+					SlackEmail:  "subscribed@user.com",
+					ReviewQueue: true,
+				},
+				{
+					GithubEmail: "notSubscribed",
+					SlackEmail:  "not-subscribed@user.com",
+				},
+			}
+			service := user.NewService(slackMock, nil, emails, nil, nil)
 
+			Expect(service.GetReviewQueueSubscribers()).To(Equal([]string{"subscribed"}))
+		})
+	})
+
+	Context("GetGithubLogin", func() {
+		emails := []config.GithubEmailToSlackEmail{
+			{
+				GithubEmail: "userLogin",
+				SlackEmail:  "user@user.com",
+			},
+		}
+
+		It("should return the github login mapped to the slack user's email", func() {
+			service := user.NewService(slackMock, nil, emails, nil, nil)
+
+			slackMock.EXPECT().GetUserByID(gomock.Any(), "U123").Return(&slack.User{ID: "U123", Profile: slack.UserProfile{Email: "User@user.com"}}, nil)
+
+			actual, err := service.GetGithubLogin(context.Background(), "U123")
+
+			Expect(err).ToNot(HaveOccurred())
 			Expect(actual).To(Equal("userLogin"))
 		})
+
+		It("should return error if the slack user is not mapped", func() {
+			service := user.NewService(slackMock, nil, emails, nil, nil)
+
+			slackMock.EXPECT().GetUserByID(gomock.Any(), "U123").Return(&slack.User{ID: "U123", Profile: slack.UserProfile{Email: "other@user.com"}}, nil)
+
+			_, err := service.GetGithubLogin(context.Background(), "U123")
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should return error if the slack user can't be found", func() {
+			service := user.NewService(slackMock, nil, emails, nil, nil)
+
+			slackMock.EXPECT().GetUserByID(gomock.Any(), "U123").Return(nil, errors.New("user_not_found"))
+
+			_, err := service.GetGithubLogin(context.Background(), "U123")
+
+			Expect(err).To(HaveOccurred())
+		})
 	})
 })